@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -11,9 +17,21 @@ import (
 
 	_ "todo/docs"
 	"todo/internal/config"
+	"todo/internal/events"
 	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/middleware/precondition"
+	"todo/internal/http-server/middleware/reqlog"
+	"todo/internal/lib/api/route"
 	"todo/internal/lib/logger/sl"
+	"todo/internal/metrics"
+	"todo/internal/models"
+	"todo/internal/reminder"
+	"todo/internal/storage/memory"
 	"todo/internal/storage/postgres"
+	"todo/internal/storage/sqlite"
+	"todo/internal/tracing"
+	"todo/internal/webhook"
+	"todo/internal/workflow"
 )
 
 // @title ToDo API
@@ -42,36 +60,168 @@ func main() {
 
 	log := setupLogger(cfg.Env)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, log, os.Args[2:]); err != nil {
+			log.Error("migration failed", sl.Err(err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Info("starting todo", slog.String("env", cfg.Env))
 	log.Debug("debug messages are enabled")
-	storage, err := postgres.New(
-		cfg.Postgres.Host,
-		cfg.Postgres.Port,
-		cfg.Postgres.User,
-		cfg.Postgres.Password,
-		cfg.Postgres.DBName,
-	)
+	storage, err := setupStorage(cfg)
 	if err != nil {
 		log.Error("failed to init storage", sl.Err(err))
 		os.Exit(1)
 	}
 
+	log.Info("storage initialized", slog.String("driver", cfg.Storage.Driver))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Error("failed to init tracing", sl.Err(err))
+		os.Exit(1)
+	}
+
+	log.Info("tracing initialized", slog.String("exporter", cfg.Tracing.Exporter))
+
+	appMetrics := metrics.New()
+	if pool, ok := storage.(metrics.StatsProvider); ok {
+		appMetrics.RegisterDBStats(cfg.Storage.Driver, pool)
+	}
+
+	taskWorkflow, err := models.NewWorkflow(cfg.Workflow.Transitions)
+	if err != nil {
+		log.Error("invalid workflow", sl.Err(err))
+		os.Exit(1)
+	}
+
+	feed := events.NewFeed(log, storage, cfg.Events)
+
+	// Хранилище, общее для нескольких экземпляров, записывает изменение
+	// задачи и его события одной транзакцией.
+	var tx webhook.Transactor
+	if transactor, ok := storage.(webhook.Transactor); ok {
+		tx = transactor
+	}
+
+	tasks := webhook.WrapTaskService(log, appMetrics.WrapTaskService(workflow.WrapTaskService(storage, taskWorkflow)),
+		webhook.Publishers{storage, feed}, tx)
+
+	notifier, err := reminder.NewNotifier(log, cfg.Reminders)
+	if err != nil {
+		log.Error("failed to init reminder notifiers", sl.Err(err))
+		os.Exit(1)
+	}
+
+	// Методы WebDAV нужно зарегистрировать до маршрутов, иначе chi
+	// отвечает на них 405.
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
+
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
-	router.Use(middleware.Logger)
+	router.Use(tracing.Middleware)
+	router.Use(appMetrics.Middleware)
+	// Токен календаря может прийти параметром запроса, его нельзя писать
+	// в журнал вместе с адресом.
+	router.Use(reqlog.Logger("token"))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.URLFormat)
 
+	// ready сбрасывается в начале остановки, чтобы /readyz сообщил
+	// балансировщику о выводе экземпляра из работы.
+	var ready atomic.Bool
+	ready.Store(true)
+
+	router.Get("/healthz", handlers.Healthz())
+	router.Get("/readyz", handlers.Readyz(log, storage, cfg.HTTPServer.HealthCheckTimeout, &ready))
+	router.Handle("/metrics", appMetrics.Handler())
+
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 
-	router.Post("/newtask", handlers.New(log, storage))
-	router.Get("/tasks/{id}", handlers.GetByID(log, storage))
-	router.Put("/tasks/{id}", handlers.UpdateTask(log, storage))
-	router.Delete("/tasks/{id}", handlers.DeleteTask(log, storage))
-	router.Get("/tasks", handlers.List(log, storage))
+	router.Post("/newtask", handlers.New(log, tasks))
+	// /tasks.ics приходит сюда же: URLFormat убирает расширение из пути.
+	router.Get("/tasks", route.ByFormat(handlers.List(log, tasks), map[string]http.HandlerFunc{
+		"ics": handlers.TaskCalendar(log, tasks, storage),
+	}))
+
+	router.Get("/tasks/next", handlers.NextTasks(log, tasks))
+	router.Get("/tasks/export", handlers.ExportTasks(log, tasks))
+	router.Post("/tasks/import", handlers.ImportTasks(log, tasks))
+	router.Get("/tasks/events", handlers.TaskEvents(log, feed, cfg.Events.Heartbeat))
+
+	// wsDone закрывается в начале остановки сервера: Shutdown не ждёт
+	// соединений WebSocket, поэтому их нужно закрыть самим.
+	wsDone := make(chan struct{})
+	router.Get("/ws", handlers.WebSocket(log, tasks, feed, handlers.WebSocketOptions{
+		PingInterval:   cfg.WebSocket.PingInterval,
+		PongTimeout:    cfg.WebSocket.PongTimeout,
+		WriteTimeout:   cfg.WebSocket.WriteTimeout,
+		SendBuffer:     cfg.WebSocket.SendBuffer,
+		MaxMessageSize: cfg.WebSocket.MaxMessageSize,
+		OriginPatterns: cfg.WebSocket.OriginPatterns,
+		RequireVersion: cfg.HTTPServer.RequireIfMatch,
+		Done:           wsDone,
+	}))
+
+	router.Route("/tasks/{id}", func(r chi.Router) {
+		if cfg.HTTPServer.RequireIfMatch {
+			r.Use(precondition.RequireIfMatch(log))
+		}
+
+		r.Get("/", handlers.GetByID(log, tasks))
+		r.Put("/", handlers.UpdateTask(log, tasks))
+		r.Patch("/", handlers.PatchTask(log, tasks))
+		r.Delete("/", handlers.DeleteTask(log, tasks))
+		r.Get("/children", handlers.Children(log, tasks))
+		r.Get("/subtree", handlers.Subtree(log, tasks))
+		r.Get("/blockers", handlers.Blockers(log, tasks))
+		r.Put("/blockers/{blocker_id}", handlers.AddBlocker(log, tasks))
+		r.Delete("/blockers/{blocker_id}", handlers.RemoveBlocker(log, tasks))
+		r.Get("/occurrences", handlers.Occurrences(log, tasks))
+		r.Post("/skip", handlers.SkipOccurrence(log, tasks))
+		r.Delete("/recurrence", handlers.EndRecurrence(log, tasks))
+	})
+
+	// У напоминаний нет версии, поэтому If-Match для них не требуется.
+	router.Route("/tasks/{id}/reminders", func(r chi.Router) {
+		r.Get("/", handlers.ListReminders(log, storage))
+		r.Post("/", handlers.CreateReminder(log, storage))
+		r.Delete("/{reminder_id}", handlers.DeleteReminder(log, storage))
+	})
+
+	router.Get("/tags", handlers.ListTags(log, storage))
+	router.Post("/tags", handlers.CreateTag(log, storage))
+	router.Get("/tags/{id}", handlers.GetTag(log, storage))
+	router.Patch("/tags/{id}", handlers.UpdateTag(log, storage))
+	router.Delete("/tags/{id}", handlers.DeleteTag(log, storage))
+
+	router.Get("/projects", handlers.ListProjects(log, storage))
+	router.Post("/projects", handlers.CreateProject(log, storage))
+	router.Get("/projects/{id}", handlers.GetProject(log, storage))
+	router.Patch("/projects/{id}", handlers.UpdateProject(log, storage))
+	router.Delete("/projects/{id}", handlers.DeleteProject(log, tasks))
+	router.Get("/projects/{id}/tasks", handlers.ProjectTasks(log, storage, tasks))
+
+	router.Get("/calendar/tokens", handlers.ListFeedTokens(log, storage))
+	router.Post("/calendar/tokens", handlers.CreateFeedToken(log, storage))
+	router.Delete("/calendar/tokens/{id}", handlers.DeleteFeedToken(log, storage))
+
+	caldav := handlers.CalDAV(log, tasks, storage, storage)
+	router.Handle("/caldav", caldav)
+	router.Handle("/caldav/*", caldav)
+	router.Get("/.well-known/caldav", handlers.CalDAVWellKnown())
+
+	router.Get("/webhooks", handlers.ListWebhooks(log, storage))
+	router.Post("/webhooks", handlers.CreateWebhook(log, storage))
+	router.Get("/webhooks/{id}", handlers.GetWebhook(log, storage))
+	router.Delete("/webhooks/{id}", handlers.DeleteWebhook(log, storage))
+	router.Get("/webhooks/{id}/deliveries", handlers.ListDeliveries(log, storage))
 
 	log.Info("starting server", slog.String("address", cfg.HTTPServer.Address))
 
@@ -82,12 +232,129 @@ func main() {
 		WriteTimeout: cfg.HTTPServer.Timeout,
 		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
 	}
+	// Потоки событий не завершаются сами, поэтому при остановке их
+	// закрывает Feed, иначе Shutdown ждал бы их до shutdown_timeout.
+	// wsDone закрывается раньше, чтобы соединения WebSocket закрылись как
+	// остановленные, а не как отставшие.
+	srv.RegisterOnShutdown(func() {
+		close(wsDone)
+		feed.Close()
+	})
+
+	// ctx отменяется по SIGINT/SIGTERM и служит сигналом остановки
+	// для сервера и фоновых задач.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err := srv.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server", sl.Err(err))
+			stop()
+		}
+	}()
+
+	// schedulerDone закрывается, когда планировщик напоминаний остановился
+	// и больше не обращается к хранилищу.
+	schedulerDone := make(chan struct{})
+	if cfg.Reminders.Enabled {
+		scheduler := reminder.NewScheduler(log, storage, notifier, cfg.Reminders)
+		go func() {
+			defer close(schedulerDone)
+			scheduler.Run(ctx)
+		}()
+		log.Info("reminder scheduler started", slog.Duration("poll_interval", cfg.Reminders.PollInterval))
+	} else {
+		close(schedulerDone)
 	}
 
-	log.Error("server stopped")
+	// dispatcherDone закрывается, когда диспетчер вебхуков остановился.
+	dispatcherDone := make(chan struct{})
+	if cfg.Webhooks.Enabled {
+		dispatcher := webhook.NewDispatcher(log, storage, cfg.Webhooks)
+		go func() {
+			defer close(dispatcherDone)
+			dispatcher.Run(ctx)
+		}()
+		log.Info("webhook dispatcher started", slog.Duration("poll_interval", cfg.Webhooks.PollInterval))
+	} else {
+		close(dispatcherDone)
+	}
+
+	// feedDone закрывается, когда Feed перестал слушать уведомления
+	// хранилища.
+	feedDone := make(chan struct{})
+	go func() {
+		defer close(feedDone)
+		feed.Run(ctx)
+	}()
+
+	<-ctx.Done()
+
+	ready.Store(false)
+
+	if cfg.HTTPServer.DrainDelay > 0 {
+		log.Info("draining server", slog.Duration("delay", cfg.HTTPServer.DrainDelay))
+		time.Sleep(cfg.HTTPServer.DrainDelay)
+	}
+
+	log.Info("stopping server", slog.Duration("grace_period", cfg.HTTPServer.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to stop server gracefully", sl.Err(err))
+	}
+
+	<-schedulerDone
+	<-dispatcherDone
+	<-feedDone
+
+	if err := storage.Close(); err != nil {
+		log.Error("failed to close storage", sl.Err(err))
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("failed to flush traces", sl.Err(err))
+	}
+
+	log.Info("server stopped")
+}
+
+// taskStorage — хранилище задач, которое проверяется в /readyz и
+// закрывается при остановке сервера.
+type taskStorage interface {
+	handlers.TaskService
+	handlers.TagService
+	handlers.ProjectService
+	handlers.ReminderService
+	handlers.WebhookService
+	handlers.CalendarService
+	handlers.CalendarObjectService
+	reminder.Store
+	webhook.Store
+	webhook.Publisher
+	events.Store
+	handlers.Pinger
+	Close() error
+}
+
+func setupStorage(cfg *config.Config) (taskStorage, error) {
+	switch cfg.Storage.Driver {
+	case config.DriverMemory:
+		return memory.New(), nil
+	case config.DriverSQLite:
+		return sqlite.New(cfg.SQLite.Path, cfg.SQLite.QueryTimeout)
+	default:
+		return postgres.New(
+			cfg.Postgres.Host,
+			cfg.Postgres.Port,
+			cfg.Postgres.User,
+			cfg.Postgres.Password,
+			cfg.Postgres.DBName,
+			cfg.Postgres.QueryTimeout,
+		)
+	}
 }
 
 func setupLogger(env string) *slog.Logger {
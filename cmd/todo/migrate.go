@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/storage/migrations"
+	"todo/internal/storage/postgres"
+	"todo/internal/storage/sqlite"
+)
+
+const migrateUsage = "usage: todo migrate up|down|status"
+
+// runMigrate обрабатывает подкоманду `todo migrate up|down|status`.
+func runMigrate(cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	db, dialect, err := openMigrationDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Info("migration applied", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			log.Info("no migrations to apply")
+		}
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("migration reverted", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+func openMigrationDB(cfg *config.Config) (*sql.DB, migrations.Dialect, error) {
+	switch cfg.Storage.Driver {
+	case config.DriverPostgres:
+		db, err := postgres.Open(
+			cfg.Postgres.Host,
+			cfg.Postgres.Port,
+			cfg.Postgres.User,
+			cfg.Postgres.Password,
+			cfg.Postgres.DBName,
+		)
+		return db, migrations.Postgres, err
+	case config.DriverSQLite:
+		db, err := sqlite.Open(cfg.SQLite.Path)
+		return db, migrations.SQLite, err
+	default:
+		return nil, "", fmt.Errorf("storage driver %q does not support migrations", cfg.Storage.Driver)
+	}
+}
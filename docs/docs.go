@@ -24,9 +24,108 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/newtask": {
+        "/caldav/{path}": {
+            "get": {
+                "description": "Минимальный сервер CalDAV (RFC 4791) для приложений задач. /caldav/ — принципал и домашний набор, /caldav/tasks/ — календарь с задачами, /caldav/tasks/{name}.ics — задача компонентом VTODO. Поддерживаются OPTIONS, PROPFIND (Depth 0 и 1), REPORT calendar-query и calendar-multiget, GET, PUT и DELETE с If-Match и If-None-Match. ETag задачи вычисляется из updated_at, getctag календаря меняется при любом изменении задач. Логин любой, пароль — токен календаря из POST /calendar/tokens.",
+                "produces": [
+                    "application/xml",
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Синхронизация задач по CalDAV",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Путь ресурса: tasks/ или tasks/{name}.ics",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Задача в формате iCalendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status для PROPFIND и REPORT",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar/tokens": {
+            "get": {
+                "description": "Получить токены календаря в порядке создания, без самих токенов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Получить список токенов календаря",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FeedToken"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Создать новую задачу с указанными данными",
+                "description": "Создать токен доступа к календарю задач GET /tasks.ics. Токен возвращается только в ответе на этот запрос; хранится лишь его хеш.",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,19 +133,84 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "calendar"
                 ],
-                "summary": "Создать новую задачу",
+                "summary": "Выпустить токен календаря",
                 "parameters": [
                     {
-                        "description": "Данные задачи",
+                        "description": "Для кого выпускается токен",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Task"
+                            "$ref": "#/definitions/models.FeedToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.FeedToken"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/calendar/tokens/{id}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
                         }
                     }
+                }
+            }
+        },
+        "/calendar/tokens/{id}": {
+            "delete": {
+                "description": "Удалить токен: календарь по нему больше не выгружается",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Отозвать токен календаря",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID токена",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -61,18 +225,50 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/tasks": {
+        "/healthz": {
             "get": {
-                "description": "Получить список задач с пагинацией и фильтрацией",
+                "description": "Возвращает 200, пока процесс запущен и обслуживает запросы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка живости",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/newtask": {
+            "post": {
+                "description": "Создать новую задачу с указанными данными",
                 "consumes": [
                     "application/json"
                 ],
@@ -82,40 +278,41 @@ const docTemplate = `{
                 "tags": [
                     "tasks"
                 ],
-                "summary": "Получить список задач",
+                "summary": "Создать новую задачу",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Количество элементов на странице",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Статус задачи (true - выполнена, false - не выполнена)",
-                        "name": "completed",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Дата в формате YYYY-MM-DD",
-                        "name": "date",
-                        "in": "query"
+                        "description": "Данные задачи",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Task"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.TasksList"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/tasks/{id}"
+                            }
                         }
                     },
                     "400": {
@@ -129,37 +326,54 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/tasks/{id}": {
+        "/projects": {
             "get": {
-                "description": "Получить задачу по её идентификатору",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Получить проекты в порядке position",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "projects"
                 ],
-                "summary": "Получить задачу по ID",
+                "summary": "Получить список проектов",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID задачи",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "true - только архивные, false - только активные; без параметра - все",
+                        "name": "archived",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Task"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Project"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -168,22 +382,22 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "504": {
+                        "description": "Gateway Timeout",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Обновить существующую задачу по ID",
+            "post": {
+                "description": "Создать проект для группировки задач",
                 "consumes": [
                     "application/json"
                 ],
@@ -191,32 +405,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "projects"
                 ],
-                "summary": "Обновить задачу",
+                "summary": "Создать проект",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID задачи",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные задачи для обновления",
+                        "description": "Данные проекта",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Task"
+                            "$ref": "#/definitions/models.Project"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/projects/{id}"
+                            }
                         }
                     },
                     "400": {
@@ -225,36 +450,34 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "504": {
+                        "description": "Gateway Timeout",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удалить задачу по её идентификатору",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/projects/{id}": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "projects"
                 ],
-                "summary": "Удалить задачу",
+                "summary": "Получить проект по ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID задачи",
+                        "description": "ID проекта",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -264,7 +487,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Project"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -284,32 +519,3344 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "handlers.Response": {
-            "description": "Ответ обработчика",
-            "type": "object",
-            "properties": {
-                "data": {
-                    "description": "Данные ответа"
-                },
-                "error": {
-                    "description": "Сообщение об ошибке (если есть)",
-                    "type": "string",
-                    "example": "something went wrong"
-                },
-                "status": {
-                    "description": "Статус ответа (OK/Error)",
-                    "type": "string",
-                    "example": "OK"
-                }
-            }
-        },
-        "models.Task": {
+            },
+            "delete": {
+                "description": "Удалить проект. Параметр tasks определяет судьбу задач проекта: refuse - вернуть 409, если задачи есть; inbox - перенести их во входящие; cascade - удалить вместе с проектом.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Удалить проект",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проекта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refuse",
+                            "inbox",
+                            "cascade"
+                        ],
+                        "type": "string",
+                        "default": "refuse",
+                        "description": "Что делать с задачами проекта",
+                        "name": "tasks",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменить название, описание, место в списке проектов или перенести проект в архив",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Изменить проект",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проекта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProjectPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/tasks": {
+            "get": {
+                "description": "Получить задачи проекта с теми же пагинацией, фильтрами и сортировкой, что и у списка задач",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Получить задачи проекта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проекта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Совместимость: true - закрытые задачи (done или cancelled), false - открытые",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "todo",
+                                "in_progress",
+                                "blocked",
+                                "done",
+                                "cancelled"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы задачи",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Блокировка (true - ждёт невыполненных задач, false - можно делать)",
+                        "name": "blocked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "low",
+                            "medium",
+                            "high",
+                            "urgent"
+                        ],
+                        "type": "string",
+                        "description": "Приоритет задачи",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Названия меток",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Режим фильтра по меткам: any - хотя бы одна, all - все",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "due_date",
+                            "priority"
+                        ],
+                        "type": "string",
+                        "default": "due_date",
+                        "description": "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TasksList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет доступность базы данных и состояние миграций. Во время остановки сервера всегда возвращает 503, чтобы балансировщик перестал направлять запросы.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Получить все метки, упорядоченные по названию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Получить список меток",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Tag"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создать метку с уникальным названием",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Создать метку",
+                "parameters": [
+                    {
+                        "description": "Данные метки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Tag"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/tags/{id}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Получить метку по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID метки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Tag"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удалить метку и снять её со всех задач",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Удалить метку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID метки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Переименовать метку или изменить её цвет. Изменение видно во всех задачах с этой меткой.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Изменить метку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID метки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Tag"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "Получить список задач с пагинацией и фильтрацией",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Получить список задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Совместимость: true - закрытые задачи (done или cancelled), false - открытые",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "todo",
+                                "in_progress",
+                                "blocked",
+                                "done",
+                                "cancelled"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы задачи",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Блокировка (true - ждёт невыполненных задач, false - можно делать)",
+                        "name": "blocked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "low",
+                            "medium",
+                            "high",
+                            "urgent"
+                        ],
+                        "type": "string",
+                        "description": "Приоритет задачи",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Названия меток",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Режим фильтра по меткам: any - хотя бы одна, all - все",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "due_date",
+                            "priority"
+                        ],
+                        "type": "string",
+                        "default": "due_date",
+                        "description": "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TasksList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks.ics": {
+            "get": {
+                "description": "Задачи в формате iCalendar (RFC 5545) для подписки из календарных приложений: VTODO на каждую задачу со сроком DUE, статусом, названием и описанием, с include=events — ещё и VEVENT в момент срока. Фильтры те же, что у GET /tasks; страницы не применяются, выгружаются все подходящие задачи. Токен календаря передаётся параметром token или паролем HTTP Basic; в журнале запросов значение token скрывается.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Календарь задач",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен календаря, в журнале запросов заменяется на REDACTED",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "events"
+                        ],
+                        "type": "string",
+                        "description": "events - добавить VEVENT для каждой задачи",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Совместимость: true - закрытые задачи (done или cancelled), false - открытые",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "todo",
+                                "in_progress",
+                                "blocked",
+                                "done",
+                                "cancelled"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы задачи",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Блокировка (true - ждёт невыполненных задач, false - можно делать)",
+                        "name": "blocked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "low",
+                            "medium",
+                            "high",
+                            "urgent"
+                        ],
+                        "type": "string",
+                        "description": "Приоритет задачи",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Названия меток",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Режим фильтра по меткам: any - хотя бы одна, all - все",
+                        "name": "tag_match",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Календарь iCalendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/events": {
+            "get": {
+                "description": "Server-Sent Events: события task.created, task.updated и task.deleted по мере изменения задач. Поле id события — его номер в журнале; после переподключения поток продолжается с события, следующего за заголовком Last-Event-ID (или параметром last_event_id). Если журнал уже вытеснил нужные события, сначала приходит событие reset: клиенту нужно заново загрузить задачи. Фильтры применяются к задаче в том состоянии, которое записано в событии.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Поток изменений задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проекта",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Название метки; можно указать несколько, событие проходит, если у задачи есть хотя бы одна",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "todo",
+                                "in_progress",
+                                "blocked",
+                                "done",
+                                "cancelled"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статус задачи; можно указать несколько",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события, если нельзя передать заголовок Last-Event-ID",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/export": {
+            "get": {
+                "description": "Выгрузить все задачи, подходящие под фильтры GET /tasks, в CSV с заголовком. Страницы не применяются: задачи читаются из хранилища частями и сразу отправляются клиенту. Метки перечисляются через запятую, даты — в RFC 3339.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Выгрузить задачи в CSV",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат выгрузки",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": ",",
+                        "description": "Разделитель полей: один символ или tab",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Совместимость: true - закрытые задачи (done или cancelled), false - открытые",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "todo",
+                                "in_progress",
+                                "blocked",
+                                "done",
+                                "cancelled"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы задачи",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Блокировка (true - ждёт невыполненных задач, false - можно делать)",
+                        "name": "blocked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "low",
+                            "medium",
+                            "high",
+                            "urgent"
+                        ],
+                        "type": "string",
+                        "description": "Приоритет задачи",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Названия меток",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Режим фильтра по меткам: any - хотя бы одна, all - все",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "due_date",
+                            "priority"
+                        ],
+                        "type": "string",
+                        "default": "due_date",
+                        "description": "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Задачи в CSV",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/import": {
+            "post": {
+                "description": "Создать задачи из CSV. Первая строка — заголовок; столбцы с названиями полей задачи (id, title, description, due_date, status, priority, tags, project_id, parent_id, rrule, timezone) узнаются сами, прочие сопоставляются параметром map вида \"Столбец:поле\". Каждая строка проверяется по тем же правилам, что в POST /newtask, и создаётся отдельно: ошибка в одной строке не мешает остальным. Пустые строки и строки с id существующей задачи пропускаются, поэтому выгрузку GET /tasks/export можно загрузить повторно. С dry_run=true строки только проверяются. В отчёте — итог по каждой строке с причиной пропуска или ошибки.",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Загрузить задачи из CSV",
+                "parameters": [
+                    {
+                        "description": "Задачи в CSV",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Сопоставление столбца полю задачи: Название:title",
+                        "name": "map",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": ",",
+                        "description": "Разделитель полей: один символ или tab",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только проверить строки, не создавая задачи",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.TaskImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/next": {
+            "get": {
+                "description": "Получить невыполненные задачи в порядке, в котором их можно делать: каждая задача идёт после своих блокирующих, а среди доступных первой идёт задача с ближайшим сроком",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Что делать дальше",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество задач",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "description": "Получить задачу по её идентификатору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Получить задачу по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "progress"
+                        ],
+                        "type": "string",
+                        "description": "progress - добавить число подзадач и процент выполнения",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи, известный клиенту",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Task"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия задачи"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Задача не изменилась"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновить существующую задачу по ID. Выполнение повторяющейся задачи создаёт её следующее повторение.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Обновить задачу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные задачи для обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "block",
+                            "complete"
+                        ],
+                        "type": "string",
+                        "default": "block",
+                        "description": "Выполнение задачи с открытыми подзадачами: block - отказать с 409, complete - выполнить и подзадачи",
+                        "name": "children",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удалить задачу по её идентификатору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Удалить задачу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменить только переданные поля задачи. Поддерживаются JSON Merge Patch (RFC 7396, application/merge-patch+json или application/json) и JSON Patch (RFC 6902, application/json-patch+json). Валидация применяется к результату слияния. Выполнение повторяющейся задачи создаёт её следующее повторение.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Частично обновить задачу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Патч задачи",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "block",
+                            "complete"
+                        ],
+                        "type": "string",
+                        "default": "block",
+                        "description": "Выполнение задачи с открытыми подзадачами: block - отказать с 409, complete - выполнить и подзадачи",
+                        "name": "children",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/blockers": {
+            "get": {
+                "description": "Получить задачи, которые нужно выполнить до начала задачи, упорядоченные по сроку выполнения",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Получить блокирующие задачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/blockers/{blocker_id}": {
+            "put": {
+                "description": "Задачу нельзя начать, пока не выполнена блокирующая. Зависимость, замыкающая цикл, отклоняется с 409. Повторное добавление ничего не меняет.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Добавить блокирующую задачу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID блокирующей задачи",
+                        "name": "blocker_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Убрать зависимость задачи от блокирующей задачи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Убрать блокирующую задачу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID блокирующей задачи",
+                        "name": "blocker_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/children": {
+            "get": {
+                "description": "Получить прямые подзадачи задачи с теми же пагинацией, фильтрами и сортировкой, что и у списка задач",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Получить подзадачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Совместимость: true - закрытые задачи (done или cancelled), false - открытые",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "todo",
+                                "in_progress",
+                                "blocked",
+                                "done",
+                                "cancelled"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы задачи",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Блокировка (true - ждёт невыполненных задач, false - можно делать)",
+                        "name": "blocked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "low",
+                            "medium",
+                            "high",
+                            "urgent"
+                        ],
+                        "type": "string",
+                        "description": "Приоритет задачи",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Названия меток",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Режим фильтра по меткам: any - хотя бы одна, all - все",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "due_date",
+                            "priority"
+                        ],
+                        "type": "string",
+                        "default": "due_date",
+                        "description": "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TasksList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/occurrences": {
+            "get": {
+                "description": "Получить сроки следующих повторений задачи после её текущего срока, в часовом поясе задачи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurrence"
+                ],
+                "summary": "Предпросмотр повторений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Количество повторений, не больше 100",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/recurrence": {
+            "delete": {
+                "description": "Убрать правило повторения: задача остаётся, но при выполнении следующее повторение не создаётся",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurrence"
+                ],
+                "summary": "Завершить серию повторений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/reminders": {
+            "get": {
+                "description": "Получить напоминания задачи в порядке срабатывания, вместе с состоянием доставки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "Получить напоминания задачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Reminder"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавить напоминание о задаче: на момент remind_at или за before до срока задачи (например, \"15m\", \"1h30m\"). Напоминание относительно срока переносится вместе со сроком.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "Добавить напоминание",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Напоминание: remind_at или before",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Reminder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Reminder"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/tasks/{id}/reminders/{reminder_id}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/reminders/{reminder_id}": {
+            "delete": {
+                "description": "Удалить напоминание задачи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "Удалить напоминание",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID напоминания",
+                        "name": "reminder_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/skip": {
+            "post": {
+                "description": "Перенести срок повторяющейся задачи на следующее повторение, не выполняя её. COUNT в правиле уменьшается на единицу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurrence"
+                ],
+                "summary": "Пропустить повторение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи; при несовпадении возвращается 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/subtree": {
+            "get": {
+                "description": "Получить задачу со всеми подзадачами на любой глубине. Подзадачи каждого уровня упорядочены по сроку выполнения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Получить дерево задачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.TaskTree"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Получить подписки на события задач в порядке создания, без ключей подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить список подписок",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Webhook"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создать подписку: события task.created, task.updated, task.completed и task.deleted отправляются POST-запросом на url с подписью HMAC-SHA256 тела в заголовке X-Todo-Signature. Ключ подписи возвращается только в ответе на этот запрос.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Подписаться на события задач",
+                "parameters": [
+                    {
+                        "description": "Адрес, ключ подписи и события",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Webhook"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/webhooks/{id}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Получить подписку без ключа подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить подписку по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Webhook"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удалить подписку вместе с журналом доставок; неотправленные события больше не доставляются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удалить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Получить доставки событий подписке, новые первыми: состояние, число попыток, код ответа и ошибку последней попытки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Журнал доставок подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Состояние доставки",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeliveryList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Двунаправленный канал с JSON-сообщениями. Клиент отправляет {\"type\": \"create\", \"task\": {...}}, {\"type\": \"update\", \"task_id\": 1, \"version\": 3, \"task\": {...}, \"children\": \"complete\"}, {\"type\": \"delete\", \"task_id\": 1, \"version\": 3} и {\"type\": \"subscribe\", \"filter\": {\"project_id\": 1, \"tags\": [...], \"statuses\": [...]}, \"last_event_id\": 10}; поле id запроса возвращается в ответе. Сервер отвечает {\"type\": \"ack\", \"task\": {...}} или {\"type\": \"error\", \"code\": 412, \"error\": \"...\"}, где code — HTTP-код соответствующего REST-запроса, а события подписки присылает как {\"type\": \"event\", \"event\": {...}}. Новая подписка заменяет прежнюю; reset означает, что события после last_event_id уже вытеснены из журнала. Задачи проверяются по тем же правилам, что в POST /newtask и PUT /tasks/{id}.",
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Синхронизация задач по WebSocket",
+                "responses": {
+                    "101": {
+                        "description": "Соединение переключено на WebSocket"
+                    },
+                    "400": {
+                        "description": "Не запрос WebSocket",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Источник не разрешён",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.Check": {
+            "description": "Результат проверки",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Причина сбоя",
+                    "type": "string",
+                    "example": "context deadline exceeded"
+                },
+                "latency_ms": {
+                    "description": "Длительность проверки в миллисекундах",
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "description": "ok или unavailable",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "handlers.HealthResponse": {
+            "description": "Состояние сервиса",
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Результаты отдельных проверок",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.Check"
+                    }
+                },
+                "migrations": {
+                    "description": "Состояние миграций",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.MigrationsReport"
+                        }
+                    ]
+                },
+                "pool": {
+                    "description": "Статистика пула соединений",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.PoolStats"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "ok или unavailable",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "handlers.MigrationsReport": {
+            "description": "Состояние миграций схемы",
+            "type": "object",
+            "properties": {
+                "current_version": {
+                    "description": "Последняя применённая версия",
+                    "type": "integer",
+                    "example": 2
+                },
+                "latest_version": {
+                    "description": "Последняя известная версия",
+                    "type": "integer",
+                    "example": 2
+                },
+                "pending": {
+                    "description": "Количество неприменённых миграций",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "handlers.PoolStats": {
+            "description": "Статистика пула соединений с базой данных",
+            "type": "object",
+            "properties": {
+                "idle": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "in_use": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_open_connections": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "open_connections": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "wait_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "wait_duration_ms": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "handlers.Response": {
+            "description": "Ответ обработчика",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Данные ответа"
+                },
+                "error": {
+                    "description": "Сообщение об ошибке (если есть)",
+                    "type": "string",
+                    "example": "something went wrong"
+                },
+                "status": {
+                    "description": "Статус ответа (OK/Error)",
+                    "type": "string",
+                    "example": "OK"
+                }
+            }
+        },
+        "models.DeliveryList": {
+            "description": "Журнал доставок с пагинацией",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Доставки, новые первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                },
+                "limit": {
+                    "description": "Количество элементов на странице",
+                    "type": "integer",
+                    "example": 10
+                },
+                "page": {
+                    "description": "Текущая страница",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "description": "Общее количество доставок",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliveryDelivered",
+                "DeliveryFailed"
+            ]
+        },
+        "models.FeedToken": {
+            "description": "Токен календаря задач",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор токена",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Для кого или для какого приложения выпущен токен",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Календарь на телефоне"
+                },
+                "token": {
+                    "description": "Токен; возвращается только при создании",
+                    "type": "string",
+                    "example": "9b2f4c1d7e3a5b6c8d0e2f4a6b8c0d1e3f5a7b9c1d3e5f7a"
+                }
+            }
+        },
+        "models.ImportRowStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "skipped",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportCreated",
+                "ImportSkipped",
+                "ImportFailed"
+            ]
+        },
+        "models.Priority": {
+            "type": "string",
+            "enum": [
+                "none",
+                "low",
+                "medium",
+                "high",
+                "urgent"
+            ],
+            "x-enum-varnames": [
+                "PriorityNone",
+                "PriorityLow",
+                "PriorityMedium",
+                "PriorityHigh",
+                "PriorityUrgent"
+            ]
+        },
+        "models.Project": {
+            "description": "Проект с задачами",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "archived": {
+                    "description": "Проект в архиве",
+                    "type": "boolean",
+                    "example": false
+                },
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "description": {
+                    "description": "Описание проекта",
+                    "type": "string",
+                    "example": "Всё, что нужно сделать в квартире"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор проекта",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Название проекта",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Ремонт"
+                },
+                "position": {
+                    "description": "Порядок в списке проектов, меньшие значения идут первыми",
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated_at": {
+                    "description": "Дата обновления",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                }
+            }
+        },
+        "models.ProjectPatch": {
+            "description": "Изменение проекта",
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "Перенести проект в архив или вернуть из него",
+                    "type": "boolean",
+                    "example": true
+                },
+                "description": {
+                    "description": "Новое описание проекта",
+                    "type": "string",
+                    "example": "Коробки, грузчики, новый адрес"
+                },
+                "name": {
+                    "description": "Новое название проекта",
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 1,
+                    "example": "Переезд"
+                },
+                "position": {
+                    "description": "Новое место в списке проектов",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.Reminder": {
+            "description": "Напоминание о задаче",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Число неудачных попыток доставки",
+                    "type": "integer",
+                    "example": 0
+                },
+                "before": {
+                    "description": "Напомнить за это время до срока задачи",
+                    "type": "string",
+                    "example": "15m"
+                },
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "fire_at": {
+                    "description": "Когда напоминание сработает",
+                    "type": "string",
+                    "example": "2025-04-20T14:45:00Z"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор напоминания",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "description": "Ошибка последней неудачной попытки",
+                    "type": "string",
+                    "example": "webhook: unexpected status 502"
+                },
+                "remind_at": {
+                    "description": "Момент напоминания",
+                    "type": "string",
+                    "example": "2025-04-20T09:00:00Z"
+                },
+                "sent_at": {
+                    "description": "Когда напоминание доставлено, null — ещё не доставлено",
+                    "type": "string",
+                    "example": "2025-04-20T14:45:02Z"
+                },
+                "task_id": {
+                    "description": "Задача, о которой напоминание",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Tag": {
+            "description": "Метка для группировки задач",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "color": {
+                    "description": "Цвет метки в формате #RRGGBB",
+                    "type": "string",
+                    "example": "#1e90ff"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор метки",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Название метки, уникально",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "backend"
+                }
+            }
+        },
+        "models.TagPatch": {
+            "description": "Изменение метки",
+            "type": "object",
+            "properties": {
+                "color": {
+                    "description": "Новый цвет метки, пустая строка убирает цвет",
+                    "type": "string",
+                    "example": "#ff4500"
+                },
+                "name": {
+                    "description": "Новое название метки",
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1,
+                    "example": "frontend"
+                }
+            }
+        },
+        "models.Task": {
             "description": "Задача пользователя",
             "type": "object",
             "required": [
@@ -317,6 +3864,21 @@ const docTemplate = `{
                 "title"
             ],
             "properties": {
+                "blocked": {
+                    "description": "Задачу блокирует хотя бы одна невыполненная задача; вычисляется, при создании и обновлении игнорируется",
+                    "type": "boolean",
+                    "example": false
+                },
+                "cancelled_at": {
+                    "description": "Когда задача перешла в cancelled; null, если она не отменена",
+                    "type": "string",
+                    "example": "2025-04-18T12:00:00Z"
+                },
+                "completed_at": {
+                    "description": "Когда задача перешла в done; null, если она не выполнена",
+                    "type": "string",
+                    "example": "2025-04-18T12:00:00Z"
+                },
                 "created_at": {
                     "description": "Дата создания",
                     "type": "string",
@@ -337,11 +3899,344 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "next_id": {
+                    "description": "Следующее повторение, созданное при выполнении задачи; при создании и обновлении игнорируется",
+                    "type": "integer",
+                    "example": 7
+                },
+                "parent_id": {
+                    "description": "Родительская задача; null — задача верхнего уровня",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "priority": {
+                    "description": "Приоритет задачи (по умолчанию none)",
+                    "enum": [
+                        "none",
+                        "low",
+                        "medium",
+                        "high",
+                        "urgent"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Priority"
+                        }
+                    ],
+                    "example": "high"
+                },
+                "progress": {
+                    "description": "Прогресс по подзадачам, только для GET /tasks/{id}?include=progress",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskProgress"
+                        }
+                    ]
+                },
+                "project_id": {
+                    "description": "Проект задачи; null — задача во входящих",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "rrule": {
+                    "description": "Правило повторения RFC 5545 (FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL); пустое — задача не повторяется",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "FREQ=WEEKLY;BYDAY=MO"
+                },
+                "status": {
+                    "description": "Статус задачи (по умолчанию todo); для совместимости принимается и true/false — done/todo",
+                    "enum": [
+                        "todo",
+                        "in_progress",
+                        "blocked",
+                        "done",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskStatus"
+                        }
+                    ],
+                    "example": "in_progress"
+                },
+                "tags": {
+                    "description": "Метки задачи; при создании и обновлении метки ищутся по названию, отсутствующие создаются",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
+                "timezone": {
+                    "description": "Часовой пояс IANA, в котором вычисляются повторения; пустой — UTC",
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
+                "title": {
+                    "description": "Заголовок задачи",
+                    "type": "string",
+                    "example": "Купить молоко"
+                },
+                "updated_at": {
+                    "description": "Дата обновления",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "version": {
+                    "description": "Версия задачи, увеличивается при каждом изменении (ETag)",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.TaskImportReport": {
+            "description": "Отчёт об импорте задач",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Сколько задач создано (в пробном импорте — было бы создано)",
+                    "type": "integer",
+                    "example": 2
+                },
+                "dry_run": {
+                    "description": "Пробный импорт: строки проверены, но задачи не созданы",
+                    "type": "boolean",
+                    "example": false
+                },
+                "failed": {
+                    "description": "Сколько строк с ошибками",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ignored_columns": {
+                    "description": "Столбцы, которые не сопоставлены ни с одним полем задачи",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "created_at"
+                    ]
+                },
+                "rows": {
+                    "description": "Результат по каждой строке",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskImportRow"
+                    }
+                },
+                "skipped": {
+                    "description": "Сколько строк пропущено",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.TaskImportRow": {
+            "description": "Результат импорта строки",
+            "type": "object",
+            "properties": {
+                "line": {
+                    "description": "Номер строки в файле; заголовок — строка 1",
+                    "type": "integer",
+                    "example": 2
+                },
+                "reason": {
+                    "description": "Причина пропуска или ошибки",
+                    "type": "string",
+                    "example": "field due_date is a required field"
+                },
                 "status": {
-                    "description": "Статус выполнения (true - выполнена, false - не выполнена)",
+                    "description": "Результат",
+                    "enum": [
+                        "created",
+                        "skipped",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportRowStatus"
+                        }
+                    ],
+                    "example": "failed"
+                },
+                "task_id": {
+                    "description": "Созданная или уже существующая задача",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.TaskProgress": {
+            "description": "Прогресс по подзадачам",
+            "type": "object",
+            "properties": {
+                "children": {
+                    "description": "Количество прямых подзадач",
+                    "type": "integer",
+                    "example": 2
+                },
+                "completed": {
+                    "description": "Количество выполненных подзадач на всех уровнях",
+                    "type": "integer",
+                    "example": 3
+                },
+                "percent": {
+                    "description": "Процент выполненных подзадач; у задачи без подзадач — 0 или 100 по её статусу",
+                    "type": "integer",
+                    "example": 60
+                },
+                "subtasks": {
+                    "description": "Количество подзадач на всех уровнях вложенности",
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "models.TaskStatus": {
+            "type": "string",
+            "enum": [
+                "todo",
+                "in_progress",
+                "blocked",
+                "done",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StatusTodo",
+                "StatusInProgress",
+                "StatusBlocked",
+                "StatusDone",
+                "StatusCancelled"
+            ]
+        },
+        "models.TaskTree": {
+            "description": "Задача с деревом подзадач",
+            "type": "object",
+            "required": [
+                "due_date",
+                "title"
+            ],
+            "properties": {
+                "blocked": {
+                    "description": "Задачу блокирует хотя бы одна невыполненная задача; вычисляется, при создании и обновлении игнорируется",
                     "type": "boolean",
                     "example": false
                 },
+                "cancelled_at": {
+                    "description": "Когда задача перешла в cancelled; null, если она не отменена",
+                    "type": "string",
+                    "example": "2025-04-18T12:00:00Z"
+                },
+                "children": {
+                    "description": "Прямые подзадачи со своими подзадачами",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskTree"
+                    }
+                },
+                "completed_at": {
+                    "description": "Когда задача перешла в done; null, если она не выполнена",
+                    "type": "string",
+                    "example": "2025-04-18T12:00:00Z"
+                },
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "description": {
+                    "description": "Описание задачи",
+                    "type": "string",
+                    "example": "Купить 2 литра молока в магазине"
+                },
+                "due_date": {
+                    "description": "Дата выполнения",
+                    "type": "string",
+                    "example": "2025-04-20T15:00:00Z"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор задачи",
+                    "type": "integer",
+                    "example": 1
+                },
+                "next_id": {
+                    "description": "Следующее повторение, созданное при выполнении задачи; при создании и обновлении игнорируется",
+                    "type": "integer",
+                    "example": 7
+                },
+                "parent_id": {
+                    "description": "Родительская задача; null — задача верхнего уровня",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "priority": {
+                    "description": "Приоритет задачи (по умолчанию none)",
+                    "enum": [
+                        "none",
+                        "low",
+                        "medium",
+                        "high",
+                        "urgent"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Priority"
+                        }
+                    ],
+                    "example": "high"
+                },
+                "progress": {
+                    "description": "Прогресс по подзадачам, только для GET /tasks/{id}?include=progress",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskProgress"
+                        }
+                    ]
+                },
+                "project_id": {
+                    "description": "Проект задачи; null — задача во входящих",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "rrule": {
+                    "description": "Правило повторения RFC 5545 (FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL); пустое — задача не повторяется",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "FREQ=WEEKLY;BYDAY=MO"
+                },
+                "status": {
+                    "description": "Статус задачи (по умолчанию todo); для совместимости принимается и true/false — done/todo",
+                    "enum": [
+                        "todo",
+                        "in_progress",
+                        "blocked",
+                        "done",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskStatus"
+                        }
+                    ],
+                    "example": "in_progress"
+                },
+                "tags": {
+                    "description": "Метки задачи; при создании и обновлении метки ищутся по названию, отсутствующие создаются",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
+                "timezone": {
+                    "description": "Часовой пояс IANA, в котором вычисляются повторения; пустой — UTC",
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
                 "title": {
                     "description": "Заголовок задачи",
                     "type": "string",
@@ -351,6 +4246,11 @@ const docTemplate = `{
                     "description": "Дата обновления",
                     "type": "string",
                     "example": "2025-04-17T10:30:00Z"
+                },
+                "version": {
+                    "description": "Версия задачи, увеличивается при каждом изменении (ETag)",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
@@ -382,6 +4282,131 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Webhook": {
+            "description": "Подписка на события задач",
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "events": {
+                    "description": "События, на которые подписка; пустой список — все события",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookEventType"
+                    },
+                    "example": [
+                        "task.created"
+                    ]
+                },
+                "id": {
+                    "description": "Уникальный идентификатор подписки",
+                    "type": "integer",
+                    "example": 1
+                },
+                "secret": {
+                    "description": "Ключ подписи; если не задан, генерируется. Возвращается только при создании",
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 16,
+                    "example": "3f9c1b7e5d2a4c6e8b0d1f3a5c7e9b1d"
+                },
+                "url": {
+                    "description": "Адрес, на который POST-запросом отправляются события",
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://ci.example.com/hooks/todo"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "description": "Доставка события подписчику",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Число сделанных попыток",
+                    "type": "integer",
+                    "example": 2
+                },
+                "created_at": {
+                    "description": "Когда событие поставлено в очередь",
+                    "type": "string",
+                    "example": "2025-04-17T10:30:00Z"
+                },
+                "delivered_at": {
+                    "description": "Когда подписчик принял событие",
+                    "type": "string",
+                    "example": "2025-04-17T10:31:00Z"
+                },
+                "event": {
+                    "description": "Событие",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.WebhookEventType"
+                        }
+                    ],
+                    "example": "task.completed"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор доставки, передаётся в заголовке X-Todo-Delivery",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "description": "Ошибка последней неудачной попытки",
+                    "type": "string",
+                    "example": "webhook: unexpected status 502"
+                },
+                "next_attempt_at": {
+                    "description": "Когда будет следующая попытка; null, если доставка завершена",
+                    "type": "string",
+                    "example": "2025-04-17T10:31:00Z"
+                },
+                "payload": {
+                    "description": "Тело запроса",
+                    "type": "object"
+                },
+                "response_code": {
+                    "description": "Код ответа подписчика на последнюю попытку",
+                    "type": "integer",
+                    "example": 502
+                },
+                "status": {
+                    "description": "Состояние: pending, delivered или failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DeliveryStatus"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "webhook_id": {
+                    "description": "Подписка",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.WebhookEventType": {
+            "type": "string",
+            "enum": [
+                "task.created",
+                "task.updated",
+                "task.completed",
+                "task.deleted"
+            ],
+            "x-enum-varnames": [
+                "EventTaskCreated",
+                "EventTaskUpdated",
+                "EventTaskCompleted",
+                "EventTaskDeleted"
+            ]
+        },
         "response.Response": {
             "description": "Стандартный ответ API",
             "type": "object",
@@ -10,22 +10,159 @@ import (
 
 type Config struct {
 	Env        string     `yaml:"env" env-default:"local"`
+	Storage    Storage    `yaml:"storage"`
 	Postgres   Postgres   `yaml:"postgres"`
+	SQLite     SQLite     `yaml:"sqlite"`
 	HTTPServer HTTPServer `yaml:"http_server"`
+	Tracing    Tracing    `yaml:"tracing"`
+	Workflow   Workflow   `yaml:"workflow"`
+	Reminders  Reminders  `yaml:"reminders"`
+	Webhooks   Webhooks   `yaml:"webhooks"`
+	Events     Events     `yaml:"events"`
+	WebSocket  WebSocket  `yaml:"websocket"`
+}
+
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+	DriverSQLite   = "sqlite"
+)
+
+const (
+	TracingDisabled = "disabled"
+	TracingStdout   = "stdout"
+	TracingOTLP     = "otlp"
+)
+
+type Storage struct {
+	Driver string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"postgres"`
 }
 
 type Postgres struct {
-	Host     string `yaml:"host" env-default:"localhost"`
-	Port     string `yaml:"port" env-default:"5432"`
-	User     string `yaml:"user" env-required:"true"`
-	Password string `yaml:"password" env-required:"true"`
-	DBName   string `yaml:"dbname" env-required:"true"`
+	Host         string        `yaml:"host" env-default:"localhost"`
+	Port         string        `yaml:"port" env-default:"5432"`
+	User         string        `yaml:"user"`
+	Password     string        `yaml:"password"`
+	DBName       string        `yaml:"dbname"`
+	QueryTimeout time.Duration `yaml:"query_timeout" env-default:"3s"`
+}
+
+type SQLite struct {
+	Path         string        `yaml:"path" env:"SQLITE_PATH" env-default:"todo.db"`
+	QueryTimeout time.Duration `yaml:"query_timeout" env-default:"3s"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	Address            string        `yaml:"address" env-default:"localhost:8080"`
+	Timeout            time.Duration `yaml:"timeout" env-default:"4s"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout" env-default:"10s"`
+	DrainDelay         time.Duration `yaml:"drain_delay" env-default:"0s"`
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" env-default:"1s"`
+	RequireIfMatch     bool          `yaml:"require_if_match" env-default:"false"`
+}
+
+// Tracing описывает экспорт трасс OpenTelemetry. Если endpoint для otlp не
+// задан, используются стандартные переменные окружения OTEL_EXPORTER_OTLP_*.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"disabled"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" env-default:"todo"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+}
+
+// Workflow задаёт допустимые переходы между статусами задачи: для каждого
+// статуса — статусы, в которые из него можно перейти. Пустые transitions
+// означают процесс по умолчанию.
+type Workflow struct {
+	Transitions map[string][]string `yaml:"transitions"`
+}
+
+const (
+	NotifierLog     = "log"
+	NotifierWebhook = "webhook"
+	NotifierSMTP    = "smtp"
+)
+
+// Reminders настраивает планировщик напоминаний. Каждые poll_interval он
+// выбирает до batch_size сработавших напоминаний и доставляет их всеми
+// notifiers. Выбранное напоминание закрепляется за экземпляром на lease,
+// поэтому lease должен быть больше времени доставки. Неудачная доставка
+// повторяется через retry_delay, но не больше max_attempts раз.
+type Reminders struct {
+	Enabled      bool            `yaml:"enabled" env:"REMINDERS_ENABLED" env-default:"true"`
+	PollInterval time.Duration   `yaml:"poll_interval" env-default:"30s"`
+	BatchSize    int             `yaml:"batch_size" env-default:"100"`
+	Lease        time.Duration   `yaml:"lease" env-default:"1m"`
+	MaxAttempts  int             `yaml:"max_attempts" env-default:"5"`
+	RetryDelay   time.Duration   `yaml:"retry_delay" env-default:"1m"`
+	Notifiers    []string        `yaml:"notifiers" env-default:"log"`
+	Webhook      ReminderWebhook `yaml:"webhook"`
+	SMTP         SMTP            `yaml:"smtp"`
+}
+
+// ReminderWebhook — адрес, на который POST-запросом отправляются
+// сработавшие напоминания.
+type ReminderWebhook struct {
+	URL     string        `yaml:"url" env:"REMINDERS_WEBHOOK_URL"`
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+}
+
+// SMTP — почтовый сервер для писем с напоминаниями. Если username пуст,
+// письма отправляются без аутентификации.
+type SMTP struct {
+	Host     string        `yaml:"host" env:"SMTP_HOST"`
+	Port     string        `yaml:"port" env-default:"587"`
+	Username string        `yaml:"username" env:"SMTP_USERNAME"`
+	Password string        `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string        `yaml:"from"`
+	To       []string      `yaml:"to"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"10s"`
+}
+
+// Webhooks настраивает доставку событий задач подписчикам. Каждые
+// poll_interval выбирается до batch_size доставок, которым пора выполнить
+// попытку; выбранная доставка закрепляется за экземпляром на lease, поэтому
+// lease должен быть больше timeout. После неудачной попытки n следующая
+// выполняется через backoff_base * 2^(n-1), но не позже чем через
+// backoff_max; после max_attempts попыток доставка становится failed.
+type Webhooks struct {
+	Enabled      bool          `yaml:"enabled" env:"WEBHOOKS_ENABLED" env-default:"true"`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"100"`
+	Lease        time.Duration `yaml:"lease" env-default:"1m"`
+	Timeout      time.Duration `yaml:"timeout" env-default:"10s"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"10"`
+	BackoffBase  time.Duration `yaml:"backoff_base" env-default:"30s"`
+	BackoffMax   time.Duration `yaml:"backoff_max" env-default:"6h"`
+}
+
+// Events настраивает поток изменений задач GET /tasks/events. Журнал
+// хранит последние retention событий: с них можно продолжить поток после
+// переподключения. Подписчик, у которого накопилось больше buffer
+// неотправленных событий, отключается и продолжает поток по Last-Event-ID.
+// Пока событий нет, каждые heartbeat отправляется комментарий, чтобы
+// прокси не закрывали соединение.
+type Events struct {
+	Retention int           `yaml:"retention" env-default:"10000"`
+	Buffer    int           `yaml:"buffer" env-default:"256"`
+	Heartbeat time.Duration `yaml:"heartbeat" env-default:"15s"`
+}
+
+// WebSocket настраивает соединения /ws. Каждые ping_interval сервер
+// отправляет ping и закрывает соединение, если pong не пришёл за
+// pong_timeout. Сообщения клиенту ставятся в очередь на send_buffer
+// сообщений; клиент, который не успевает их читать, отключается. Сообщения
+// клиента длиннее max_message_size байт закрывают соединение.
+// origin_patterns — шаблоны хостов других источников, которым разрешено
+// подключаться из браузера; по умолчанию разрешён только свой хост.
+type WebSocket struct {
+	PingInterval   time.Duration `yaml:"ping_interval" env-default:"30s"`
+	PongTimeout    time.Duration `yaml:"pong_timeout" env-default:"10s"`
+	WriteTimeout   time.Duration `yaml:"write_timeout" env-default:"10s"`
+	SendBuffer     int           `yaml:"send_buffer" env-default:"64"`
+	MaxMessageSize int64         `yaml:"max_message_size" env-default:"65536"`
+	OriginPatterns []string      `yaml:"origin_patterns"`
 }
 
 func MustLoad() *Config {
@@ -44,5 +181,61 @@ func MustLoad() *Config {
 		log.Fatalf("cannot read config: %s", err)
 	}
 
+	switch cfg.Storage.Driver {
+	case DriverPostgres:
+		if cfg.Postgres.User == "" || cfg.Postgres.Password == "" || cfg.Postgres.DBName == "" {
+			log.Fatal("postgres user, password and dbname are required for postgres driver")
+		}
+	case DriverMemory, DriverSQLite:
+	default:
+		log.Fatalf("unknown storage driver: %s", cfg.Storage.Driver)
+	}
+
+	switch cfg.Tracing.Exporter {
+	case TracingDisabled, TracingStdout, TracingOTLP:
+	default:
+		log.Fatalf("unknown tracing exporter: %s", cfg.Tracing.Exporter)
+	}
+
+	if cfg.Reminders.Enabled {
+		if cfg.Reminders.PollInterval <= 0 || cfg.Reminders.BatchSize <= 0 || cfg.Reminders.MaxAttempts <= 0 {
+			log.Fatal("reminders poll_interval, batch_size and max_attempts must be positive")
+		}
+		for _, notifier := range cfg.Reminders.Notifiers {
+			switch notifier {
+			case NotifierLog:
+			case NotifierWebhook:
+				if cfg.Reminders.Webhook.URL == "" {
+					log.Fatal("reminders webhook url is required for webhook notifier")
+				}
+			case NotifierSMTP:
+				if cfg.Reminders.SMTP.Host == "" || cfg.Reminders.SMTP.From == "" || len(cfg.Reminders.SMTP.To) == 0 {
+					log.Fatal("smtp host, from and to are required for smtp notifier")
+				}
+			default:
+				log.Fatalf("unknown reminders notifier: %s", notifier)
+			}
+		}
+	}
+
+	if cfg.Webhooks.Enabled {
+		if cfg.Webhooks.PollInterval <= 0 || cfg.Webhooks.BatchSize <= 0 || cfg.Webhooks.MaxAttempts <= 0 ||
+			cfg.Webhooks.BackoffBase <= 0 {
+			log.Fatal("webhooks poll_interval, batch_size, max_attempts and backoff_base must be positive")
+		}
+		if cfg.Webhooks.Lease <= cfg.Webhooks.Timeout {
+			log.Fatal("webhooks lease must be greater than timeout")
+		}
+	}
+
+	if cfg.Events.Retention <= 0 || cfg.Events.Buffer <= 0 || cfg.Events.Heartbeat <= 0 {
+		log.Fatal("events retention, buffer and heartbeat must be positive")
+	}
+
+	if cfg.WebSocket.PingInterval <= 0 || cfg.WebSocket.PongTimeout <= 0 || cfg.WebSocket.WriteTimeout <= 0 ||
+		cfg.WebSocket.SendBuffer <= 0 || cfg.WebSocket.MaxMessageSize <= 0 {
+		log.Fatal("websocket ping_interval, pong_timeout, write_timeout, send_buffer and max_message_size must be positive")
+	}
+
 	return &cfg
 }
@@ -0,0 +1,182 @@
+// Package events рассылает изменения задач клиентам потока GET
+// /tasks/events.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+)
+
+// listenRetryDelay — пауза перед повторным подключением слушателя после
+// ошибки.
+const listenRetryDelay = 5 * time.Second
+
+// Store — журнал изменений задач, из которого клиенты продолжают поток
+// после переподключения.
+type Store interface {
+	AppendTaskEvent(ctx context.Context, event models.WebhookEvent, keep int) (*models.TaskEvent, error)
+	ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error)
+}
+
+// Source сообщает о событиях, которые записывают в журнал все экземпляры
+// приложения. Его реализует хранилище, общее для нескольких экземпляров.
+type Source interface {
+	ListenTaskEvents(ctx context.Context, handle func(models.TaskEvent)) error
+}
+
+// Feed записывает изменения задач в журнал и рассылает их подписчикам
+// потока. Если хранилище реализует Source, события рассылаются по его
+// уведомлениям, и подписчики любого экземпляра получают изменения,
+// сделанные через другие экземпляры; иначе — сразу после записи.
+type Feed struct {
+	log    *slog.Logger
+	store  Store
+	source Source
+	cfg    config.Events
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+type subscriber struct {
+	filter models.TaskEventFilter
+	events chan models.TaskEvent
+}
+
+func NewFeed(log *slog.Logger, store Store, cfg config.Events) *Feed {
+	f := &Feed{
+		log:         log.With(slog.String("component", "events.Feed")),
+		store:       store,
+		cfg:         cfg,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	if source, ok := store.(Source); ok {
+		f.source = source
+	}
+
+	return f
+}
+
+// EnqueueEvent записывает событие в журнал. События task.completed не
+// записываются: выполнение задачи клиенты потока видят в task.updated.
+func (f *Feed) EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error) {
+	const op = "events.Feed.EnqueueEvent"
+
+	if event.Type == models.EventTaskCompleted {
+		return 0, nil
+	}
+
+	stored, err := f.store.AppendTaskEvent(ctx, event, f.cfg.Retention)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if f.source == nil {
+		f.Broadcast(*stored)
+	}
+
+	return 1, nil
+}
+
+// ListTaskEvents возвращает до limit событий журнала с ID больше afterID.
+func (f *Feed) ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error) {
+	return f.store.ListTaskEvents(ctx, afterID, limit)
+}
+
+// Subscribe возвращает канал событий, проходящих filter, и функцию отписки.
+// Канал закрывается при отписке, при остановке Feed и когда подписчик
+// отстал больше чем на Buffer событий: тогда клиент должен переподключиться
+// и продолжить поток по ID последнего полученного события.
+func (f *Feed) Subscribe(filter models.TaskEventFilter) (<-chan models.TaskEvent, func()) {
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan models.TaskEvent, f.cfg.Buffer),
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	f.subscribers[sub] = struct{}{}
+
+	return sub.events, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.remove(sub)
+	}
+}
+
+// Broadcast передаёт событие подписчикам, чей фильтр оно проходит.
+func (f *Feed) Broadcast(event models.TaskEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			f.log.Warn("task events subscriber is too slow, disconnecting", slog.Int64("event_id", event.ID))
+			f.remove(sub)
+		}
+	}
+}
+
+// Run слушает Source, пока ctx не отменён, и переподключается после
+// ошибок. Без Source возвращается сразу.
+func (f *Feed) Run(ctx context.Context) {
+	if f.source == nil {
+		return
+	}
+
+	for {
+		err := f.source.ListenTaskEvents(ctx, f.Broadcast)
+		if ctx.Err() != nil {
+			return
+		}
+		f.log.Error("task events listener failed", sl.Err(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// Close закрывает каналы всех подписчиков, чтобы открытые потоки
+// завершились при остановке сервера. Новые подписки сразу закрываются.
+func (f *Feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for sub := range f.subscribers {
+		f.remove(sub)
+	}
+}
+
+// remove закрывает канал подписчика, если он ещё подписан. Вызывается под
+// f.mu.
+func (f *Feed) remove(sub *subscriber) {
+	if _, ok := f.subscribers[sub]; !ok {
+		return
+	}
+	delete(f.subscribers, sub)
+	close(sub.events)
+}
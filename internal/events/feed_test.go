@@ -0,0 +1,122 @@
+package events_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/config"
+	"todo/internal/events"
+	"todo/internal/models"
+	"todo/internal/storage/memory"
+)
+
+func newFeed(cfg config.Events) *events.Feed {
+	return events.NewFeed(slog.New(slog.NewTextHandler(io.Discard, nil)), memory.New(), cfg)
+}
+
+func publish(t *testing.T, feed *events.Feed, eventType models.WebhookEventType, task models.Task) int {
+	t.Helper()
+
+	n, err := feed.EnqueueEvent(context.Background(), models.WebhookEvent{Type: eventType, OccurredAt: time.Now(), Task: task})
+	require.NoError(t, err)
+	return n
+}
+
+func TestFeedBroadcast(t *testing.T) {
+	feed := newFeed(config.Events{Retention: 100, Buffer: 10, Heartbeat: time.Second})
+
+	projectID := int64(1)
+	all, unsubscribeAll := feed.Subscribe(models.TaskEventFilter{})
+	defer unsubscribeAll()
+	project, unsubscribeProject := feed.Subscribe(models.TaskEventFilter{ProjectID: &projectID})
+	defer unsubscribeProject()
+	tagged, unsubscribeTagged := feed.Subscribe(models.TaskEventFilter{
+		Tags:     []string{"ui", "api"},
+		Statuses: []models.TaskStatus{models.StatusTodo},
+	})
+
+	require.Equal(t, 1, publish(t, feed, models.EventTaskCreated, models.Task{ID: 1, ProjectID: &projectID, Status: models.StatusTodo}))
+	require.Equal(t, 1, publish(t, feed, models.EventTaskUpdated, models.Task{
+		ID:     2,
+		Status: models.StatusTodo,
+		Tags:   []models.Tag{{Name: "api"}},
+	}))
+	// Выполнение задачи приходит как task.updated, task.completed в поток не
+	// попадает.
+	require.Zero(t, publish(t, feed, models.EventTaskCompleted, models.Task{ID: 2, Status: models.StatusDone}))
+	require.Equal(t, 1, publish(t, feed, models.EventTaskDeleted, models.Task{
+		ID:     3,
+		Status: models.StatusDone,
+		Tags:   []models.Tag{{Name: "ui"}},
+	}))
+
+	require.Equal(t, []int64{1, 2, 3}, drain(all))
+	require.Equal(t, []int64{1}, drain(project))
+	require.Equal(t, []int64{2}, drain(tagged))
+
+	unsubscribeTagged()
+	_, ok := <-tagged
+	require.False(t, ok)
+
+	logged, err := feed.ListTaskEvents(context.Background(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, logged, 2)
+	require.Equal(t, models.EventTaskUpdated, logged[0].Type)
+	require.Equal(t, models.EventTaskDeleted, logged[1].Type)
+}
+
+func TestFeedSlowSubscriber(t *testing.T) {
+	feed := newFeed(config.Events{Retention: 100, Buffer: 2, Heartbeat: time.Second})
+
+	slow, unsubscribe := feed.Subscribe(models.TaskEventFilter{})
+	defer unsubscribe()
+
+	for i := int64(1); i <= 3; i++ {
+		publish(t, feed, models.EventTaskCreated, models.Task{ID: i})
+	}
+
+	// Отставший подписчик получает то, что успело попасть в буфер, после
+	// чего канал закрывается.
+	require.Equal(t, []int64{1, 2}, drain(slow))
+	_, ok := <-slow
+	require.False(t, ok)
+}
+
+func TestFeedClose(t *testing.T) {
+	feed := newFeed(config.Events{Retention: 100, Buffer: 2, Heartbeat: time.Second})
+
+	open, unsubscribe := feed.Subscribe(models.TaskEventFilter{})
+	defer unsubscribe()
+
+	feed.Close()
+	_, ok := <-open
+	require.False(t, ok)
+
+	late, _ := feed.Subscribe(models.TaskEventFilter{})
+	_, ok = <-late
+	require.False(t, ok)
+
+	// Без Source Run возвращается сразу.
+	feed.Run(context.Background())
+}
+
+// drain возвращает ID событий, уже лежащих в канале.
+func drain(events <-chan models.TaskEvent) []int64 {
+	var ids []int64
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
@@ -0,0 +1,704 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/ical"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+const (
+	// caldavRoot — принципал и домашний набор календарей.
+	caldavRoot = "/caldav/"
+	// caldavCollection — единственный календарь с задачами.
+	caldavCollection = caldavRoot + "tasks/"
+)
+
+// caldavMaxBody ограничивает тело запросов PROPFIND, REPORT и PUT.
+const caldavMaxBody = 1 << 20
+
+// caldavAllow — методы, которые поддерживает CalDAV.
+const caldavAllow = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+
+// Пространства имён WebDAV (RFC 4918), CalDAV (RFC 4791) и расширений
+// Calendar Server, из которого клиенты берут getctag.
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+// defaultObjectName — имя ресурса задачи, созданной не через CalDAV:
+// task-7.ics. Такие имена клиентам занимать нельзя.
+var defaultObjectName = regexp.MustCompile(`^task-([1-9][0-9]*)\.ics$`)
+
+// defaultObjectUID — UID задачи, созданной не через CalDAV: task-7@todo.
+var defaultObjectUID = regexp.MustCompile(`^task-([1-9][0-9]*)@todo$`)
+
+//go:generate mockery --name=CalendarObjectService --output=mocks --outpkg=mocks
+type CalendarObjectService interface {
+	CalendarObjects(ctx context.Context) ([]models.CalendarObject, error)
+	SaveCalendarObject(ctx context.Context, object models.CalendarObject) error
+}
+
+// caldavKind — вид ресурса CalDAV.
+type caldavKind int
+
+const (
+	caldavHome caldavKind = iota
+	caldavTasks
+	caldavObject
+)
+
+// caldav обслуживает запросы к /caldav/.
+type caldav struct {
+	log      *slog.Logger
+	tasks    TaskService
+	calendar CalendarService
+	objects  CalendarObjectService
+}
+
+// CalDAVWellKnown перенаправляет /.well-known/caldav на корень CalDAV
+// (RFC 6764), чтобы клиенту хватало адреса сервера.
+func CalDAVWellKnown() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, caldavRoot, http.StatusMovedPermanently)
+	}
+}
+
+// CalDAV godoc
+// @Summary Синхронизация задач по CalDAV
+// @Description Минимальный сервер CalDAV (RFC 4791) для приложений задач. /caldav/ — принципал и домашний набор, /caldav/tasks/ — календарь с задачами, /caldav/tasks/{name}.ics — задача компонентом VTODO. Поддерживаются OPTIONS, PROPFIND (Depth 0 и 1), REPORT calendar-query и calendar-multiget, GET, PUT и DELETE с If-Match и If-None-Match. ETag задачи вычисляется из updated_at, getctag календаря меняется при любом изменении задач. Логин любой, пароль — токен календаря из POST /calendar/tokens.
+// @Tags calendar
+// @Produce application/xml
+// @Produce text/calendar
+// @Param path path string true "Путь ресурса: tasks/ или tasks/{name}.ics"
+// @Success 200 {string} string "Задача в формате iCalendar"
+// @Success 207 {string} string "Multi-Status для PROPFIND и REPORT"
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Router /caldav/{path} [get]
+func CalDAV(log *slog.Logger, taskService TaskService, calendarService CalendarService, objectService CalendarObjectService) http.HandlerFunc {
+	h := caldav{log: log, tasks: taskService, calendar: calendarService, objects: objectService}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.CalDAV"
+
+		log := h.log.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		kind, name, ok := parseCalDAVPath(r.URL.Path)
+		if !ok {
+			log.Info("caldav resource not found")
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error("resource not found"))
+			return
+		}
+
+		w.Header().Set("DAV", "1, 3, calendar-access")
+
+		// Клиенты узнают возможности сервера до того, как спросят пароль.
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Allow", caldavAllow)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if !h.authenticate(w, r, log) {
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, caldavMaxBody)
+
+		switch {
+		case r.Method == "PROPFIND":
+			h.propfind(w, r, log, kind, name)
+		case r.Method == "REPORT" && kind == caldavTasks:
+			h.report(w, r, log)
+		case (r.Method == http.MethodGet || r.Method == http.MethodHead) && kind == caldavObject:
+			h.get(w, r, log, name)
+		case r.Method == http.MethodPut && kind == caldavObject:
+			h.put(w, r, log, name)
+		case r.Method == http.MethodDelete && kind == caldavObject:
+			h.delete(w, r, log, name)
+		default:
+			log.Info("method not allowed for caldav resource")
+			w.Header().Set("Allow", caldavAllow)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			render.JSON(w, r, resp.Error("method not allowed"))
+		}
+	}
+}
+
+// parseCalDAVPath определяет ресурс по пути запроса.
+func parseCalDAVPath(path string) (caldavKind, string, bool) {
+	switch path {
+	case strings.TrimSuffix(caldavRoot, "/"), caldavRoot:
+		return caldavHome, "", true
+	case strings.TrimSuffix(caldavCollection, "/"), caldavCollection:
+		return caldavTasks, "", true
+	}
+
+	name, ok := strings.CutPrefix(path, caldavCollection)
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return 0, "", false
+	}
+
+	return caldavObject, name, true
+}
+
+// authenticate проверяет пароль HTTP Basic как токен календаря и отвечает
+// 401, если он не подходит.
+func (h caldav) authenticate(w http.ResponseWriter, r *http.Request, log *slog.Logger) bool {
+	_, secret, ok := r.BasicAuth()
+	if !ok || secret == "" {
+		log.Info("feed token is missing")
+		w.Header().Set("WWW-Authenticate", `Basic realm="tasks"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, resp.Error("feed token is required"))
+		return false
+	}
+
+	_, err := h.calendar.FindFeedToken(r.Context(), feedTokenHash(secret))
+	if errors.Is(err, storage.ErrFeedTokenNotFound) {
+		log.Info("invalid feed token")
+		w.Header().Set("WWW-Authenticate", `Basic realm="tasks"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, resp.Error("invalid feed token"))
+		return false
+	}
+	if err != nil {
+		writeError(w, r, log, err, "failed to check feed token")
+		return false
+	}
+
+	return true
+}
+
+// calendarIndex связывает задачи с именами ресурсов и UID. Задачи без
+// записи в CalendarObjectService получают имя и UID по умолчанию.
+type calendarIndex struct {
+	byTask map[int64]models.CalendarObject
+	byName map[string]int64
+	byUID  map[string]int64
+}
+
+func (h caldav) index(ctx context.Context) (*calendarIndex, error) {
+	objects, err := h.objects.CalendarObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &calendarIndex{
+		byTask: make(map[int64]models.CalendarObject, len(objects)),
+		byName: make(map[string]int64, len(objects)),
+		byUID:  make(map[string]int64, len(objects)),
+	}
+	for _, object := range objects {
+		index.byTask[object.TaskID] = object
+		index.byName[object.Name] = object.TaskID
+		index.byUID[object.UID] = object.TaskID
+	}
+
+	return index, nil
+}
+
+// name возвращает имя ресурса задачи.
+func (idx *calendarIndex) name(id int64) string {
+	if object, ok := idx.byTask[id]; ok {
+		return object.Name
+	}
+	return fmt.Sprintf("task-%d.ics", id)
+}
+
+// uid возвращает UID задачи в календаре.
+func (idx *calendarIndex) uid(id int64) string {
+	if object, ok := idx.byTask[id]; ok {
+		return object.UID
+	}
+	return taskUID(id)
+}
+
+// href возвращает адрес ресурса задачи.
+func (idx *calendarIndex) href(id int64) string {
+	return caldavCollection + url.PathEscape(idx.name(id))
+}
+
+// lookup возвращает id задачи по имени ресурса.
+func (idx *calendarIndex) lookup(name string) (int64, bool) {
+	if id, ok := idx.byName[name]; ok {
+		return id, true
+	}
+	return idx.defaultID(defaultObjectName, name)
+}
+
+// taskByUID возвращает id задачи по UID.
+func (idx *calendarIndex) taskByUID(uid string) (int64, bool) {
+	if id, ok := idx.byUID[uid]; ok {
+		return id, true
+	}
+	return idx.defaultID(defaultObjectUID, uid)
+}
+
+// defaultID извлекает id задачи из имени или UID по умолчанию. Они не
+// подходят задаче, которой клиент дал свои.
+func (idx *calendarIndex) defaultID(pattern *regexp.Regexp, value string) (int64, bool) {
+	match := pattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if _, ok := idx.byTask[id]; ok {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// object возвращает задачу по имени ресурса; storage.ErrTaskNotFound,
+// если ресурса нет.
+func (h caldav) object(ctx context.Context, index *calendarIndex, name string) (*models.Task, error) {
+	id, ok := index.lookup(name)
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	return h.tasks.GetByID(ctx, uint(id))
+}
+
+// allTasks возвращает все задачи календаря.
+func (h caldav) allTasks(ctx context.Context) ([]models.Task, error) {
+	filter, err := parseTaskFilter(url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	return allTasks(ctx, h.tasks, filter)
+}
+
+// calendarETag — ETag ресурса задачи, вычисленный из времени изменения.
+// Версия добавлена на случай двух изменений в пределах микросекунды;
+// микросекунды сохраняет любое хранилище.
+func calendarETag(task models.Task) string {
+	return strconv.Quote(fmt.Sprintf("%d-%d", task.UpdatedAt.UnixMicro(), task.Version))
+}
+
+// calendarCTag меняется при любом изменении, добавлении и удалении задач
+// календаря: клиенты сверяют его, прежде чем запрашивать ETag задач.
+func calendarCTag(tasks []models.Task, index *calendarIndex) string {
+	hash := sha256.New()
+	for _, task := range tasks {
+		fmt.Fprintf(hash, "%s %s\n", index.name(task.ID), calendarETag(task))
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// calendarData возвращает задачу календарём iCalendar с одним VTODO.
+func calendarData(task models.Task, index *calendarIndex) ([]byte, error) {
+	var buf bytes.Buffer
+
+	cw := ical.NewWriter(&buf)
+	cw.Begin("VCALENDAR")
+	cw.Raw("VERSION", "2.0")
+	cw.Raw("PRODID", "-//todo//ToDo API//RU")
+	writeTaskTodo(cw, task, index.uid)
+	cw.End("VCALENDAR")
+
+	if err := cw.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// get отдаёт задачу в формате iCalendar.
+func (h caldav) get(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string) {
+	index, err := h.index(r.Context())
+	if err != nil {
+		writeError(w, r, log, err, "failed to get calendar objects")
+		return
+	}
+
+	task, err := h.object(r.Context(), index, name)
+	if err != nil {
+		writeTaskError(w, r, log, err, 0, "failed to get task")
+		return
+	}
+
+	tag := calendarETag(*task)
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+	if etagMatches(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := calendarData(*task, index)
+	if err != nil {
+		writeError(w, r, log, err, "failed to write calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// put создаёт или заменяет задачу по компоненту VTODO. Сервер меняет
+// присланные данные (например, добавляет DTSTAMP и SEQUENCE), поэтому
+// ETag в ответе не возвращается (RFC 4791, 5.3.4): клиент прочитает его
+// при следующей синхронизации.
+func (h caldav) put(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string) {
+	todo, err := parseTodo(r.Body)
+	if err != nil {
+		log.Error("invalid calendar data", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(err.Error()))
+		return
+	}
+
+	index, err := h.index(r.Context())
+	if err != nil {
+		writeError(w, r, log, err, "failed to get calendar objects")
+		return
+	}
+
+	current, err := h.object(r.Context(), index, name)
+	if err != nil && !errors.Is(err, storage.ErrTaskNotFound) {
+		writeError(w, r, log, err, "failed to get task")
+		return
+	}
+
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	switch {
+	case current == nil && ifMatch != "",
+		current != nil && ifMatch != "" && !etagMatches(ifMatch, calendarETag(*current)),
+		current != nil && ifNoneMatch != "" && etagMatches(ifNoneMatch, calendarETag(*current)):
+		log.Info("calendar object precondition failed")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		render.JSON(w, r, resp.Error("calendar object precondition failed"))
+		return
+	}
+
+	if current == nil {
+		h.create(w, r, log, index, name, todo)
+		return
+	}
+
+	if uid := todo.Prop("UID").Text(); uid != index.uid(current.ID) {
+		log.Info("calendar object uid changed", slog.Int64("id", current.ID), slog.String("uid", uid))
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, resp.Error("calendar object UID cannot be changed"))
+		return
+	}
+
+	task := *current
+	if err := applyTodo(&task, todo, index); err != nil {
+		log.Error("invalid calendar data", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(err.Error()))
+		return
+	}
+
+	if err := validateTask(&task); err != nil {
+		log.Error("invalid task", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(err.Error()))
+		return
+	}
+
+	// Клиенты CalDAV не знают о children=complete, поэтому выполненная
+	// задача выполняет и свои подзадачи в той же транзакции.
+	policy := models.SubtasksBlock
+	if task.Status == models.StatusDone && current.Status != models.StatusDone {
+		policy = models.SubtasksComplete
+	}
+
+	if err := h.tasks.UpdateTask(r.Context(), &task, policy); err != nil {
+		writeTaskError(w, r, log, err, task.ID, "failed to update task")
+		return
+	}
+
+	log.Info("calendar object updated", slog.Int64("id", task.ID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// create создаёт задачу под именем ресурса, выбранным клиентом.
+func (h caldav) create(w http.ResponseWriter, r *http.Request, log *slog.Logger, index *calendarIndex, name string, todo *ical.Component) {
+	if defaultObjectName.MatchString(name) {
+		log.Info("calendar object name is reserved", slog.String("name", name))
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, resp.Error("calendar object name is reserved"))
+		return
+	}
+
+	uid := todo.Prop("UID").Text()
+	if _, ok := index.byUID[uid]; ok || defaultObjectUID.MatchString(uid) {
+		log.Info("calendar object uid already used", slog.String("uid", uid))
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, resp.Error("calendar object UID already used"))
+		return
+	}
+
+	var task models.Task
+	if err := applyTodo(&task, todo, index); err != nil {
+		log.Error("invalid calendar data", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(err.Error()))
+		return
+	}
+
+	if err := validateTask(&task); err != nil {
+		log.Error("invalid task", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(err.Error()))
+		return
+	}
+
+	created, err := h.tasks.CreateTask(r.Context(), task)
+	if err != nil {
+		writeTaskError(w, r, log, err, 0, "failed to create task")
+		return
+	}
+
+	err = h.objects.SaveCalendarObject(r.Context(), models.CalendarObject{TaskID: created.ID, Name: name, UID: uid})
+	if err != nil {
+		// Без имени ресурса задача недоступна клиенту под ожидаемым
+		// адресом, поэтому удаляется.
+		if delErr := h.tasks.DeleteTask(r.Context(), uint(created.ID), 0); delErr != nil {
+			log.Error("failed to delete task without calendar object", slog.Int64("id", created.ID), sl.Err(delErr))
+		}
+		if errors.Is(err, storage.ErrCalendarObjectExists) {
+			log.Info("calendar object name already used", slog.String("name", name))
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error("calendar object name already used"))
+			return
+		}
+		writeError(w, r, log, err, "failed to save calendar object")
+		return
+	}
+
+	log.Info("calendar object created", slog.Int64("id", created.ID), slog.String("name", name))
+	w.Header().Set("Location", caldavCollection+url.PathEscape(name))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// delete удаляет задачу вместе с подзадачами.
+func (h caldav) delete(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string) {
+	index, err := h.index(r.Context())
+	if err != nil {
+		writeError(w, r, log, err, "failed to get calendar objects")
+		return
+	}
+
+	task, err := h.object(r.Context(), index, name)
+	if err != nil {
+		writeTaskError(w, r, log, err, 0, "failed to delete task")
+		return
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !etagMatches(ifMatch, calendarETag(*task)) {
+		log.Info("calendar object precondition failed", slog.Int64("id", task.ID))
+		w.WriteHeader(http.StatusPreconditionFailed)
+		render.JSON(w, r, resp.Error("calendar object precondition failed"))
+		return
+	}
+
+	if err := h.tasks.DeleteTask(r.Context(), uint(task.ID), task.Version); err != nil {
+		writeTaskError(w, r, log, err, task.ID, "failed to delete task")
+		return
+	}
+
+	log.Info("calendar object deleted", slog.Int64("id", task.ID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// etagMatches сообщает, что заголовок If-Match или If-None-Match содержит
+// tag или "*".
+func etagMatches(header, tag string) bool {
+	for _, value := range parseETags(header) {
+		if value == "*" || strings.TrimPrefix(value, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTodo читает календарь из тела PUT и возвращает его VTODO.
+func parseTodo(body io.Reader) (*ical.Component, error) {
+	calendar, err := ical.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar data: %w", err)
+	}
+	if calendar.Name != "VCALENDAR" {
+		return nil, errors.New("invalid calendar data: VCALENDAR is required")
+	}
+
+	var todo *ical.Component
+	for _, component := range calendar.Components {
+		switch component.Name {
+		case "VTODO":
+			if todo != nil {
+				return nil, errors.New("only one VTODO is supported")
+			}
+			todo = component
+		case "VTIMEZONE":
+		default:
+			return nil, fmt.Errorf("component %s is not supported, use VTODO", component.Name)
+		}
+	}
+	if todo == nil {
+		return nil, errors.New("VTODO is required")
+	}
+	if uid := todo.Prop("UID"); uid == nil || uid.Text() == "" {
+		return nil, errors.New("UID is required")
+	}
+
+	return todo, nil
+}
+
+// applyTodo переносит в задачу свойства VTODO. PUT заменяет ресурс
+// целиком, поэтому отсутствующие описание, приоритет, метки и родитель
+// сбрасываются. Проект и повторения в VTODO не передаются и остаются
+// прежними. Время без часового пояса считается в поясе задачи.
+func applyTodo(task *models.Task, todo *ical.Component, index *calendarIndex) error {
+	location := time.UTC
+	if task.Timezone != "" {
+		if loc, err := time.LoadLocation(task.Timezone); err == nil {
+			location = loc
+		}
+	}
+
+	task.Title = ""
+	if summary := todo.Prop("SUMMARY"); summary != nil {
+		task.Title = summary.Text()
+	}
+
+	task.Description = ""
+	if description := todo.Prop("DESCRIPTION"); description != nil {
+		task.Description = description.Text()
+	}
+
+	due := todo.Prop("DUE")
+	if due == nil {
+		due = todo.Prop("DTSTART")
+	}
+	if due != nil {
+		dueDate, err := due.Time(location)
+		if err != nil {
+			return err
+		}
+		task.DueDate = dueDate
+	}
+
+	task.Status = todoTaskStatus(todo, task.Status)
+
+	task.Priority = models.PriorityNone
+	if priority := todo.Prop("PRIORITY"); priority != nil {
+		value, err := strconv.Atoi(strings.TrimSpace(priority.Value))
+		if err != nil || value < 0 || value > 9 {
+			return fmt.Errorf("PRIORITY: invalid value %q", priority.Value)
+		}
+		task.Priority = taskPriority(value)
+	}
+
+	task.Tags = nil
+	var names []string
+	for _, prop := range todo.Props {
+		if prop.Name != "CATEGORIES" {
+			continue
+		}
+		for _, name := range prop.TextList() {
+			if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		task.Tags = append(task.Tags, models.Tag{Name: name})
+	}
+
+	task.ParentID = nil
+	for _, prop := range todo.Props {
+		if prop.Name != "RELATED-TO" {
+			continue
+		}
+		if reltype := strings.ToUpper(prop.Params["RELTYPE"]); reltype != "" && reltype != "PARENT" {
+			continue
+		}
+		// Родитель из другого календаря клиента сюда не попадает.
+		if id, ok := index.taskByUID(prop.Text()); ok {
+			task.ParentID = &id
+		}
+	}
+
+	return nil
+}
+
+// todoTaskStatus переводит STATUS компонента VTODO в статус задачи.
+// NEEDS-ACTION сохраняет todo и blocked: в VTODO они не различаются.
+func todoTaskStatus(todo *ical.Component, current models.TaskStatus) models.TaskStatus {
+	status := ""
+	if prop := todo.Prop("STATUS"); prop != nil {
+		status = strings.ToUpper(prop.Value)
+	}
+
+	switch {
+	case status == "COMPLETED", status == "" && todo.Prop("COMPLETED") != nil:
+		return models.StatusDone
+	case status == "CANCELLED":
+		return models.StatusCancelled
+	case status == "IN-PROCESS":
+		return models.StatusInProgress
+	case current == models.StatusTodo || current == models.StatusBlocked:
+		return current
+	default:
+		return models.StatusTodo
+	}
+}
+
+// taskPriority переводит PRIORITY компонента VTODO (1 — высший, 9 —
+// низший, 0 — не задан) в приоритет задачи.
+func taskPriority(priority int) models.Priority {
+	switch {
+	case priority == 0:
+		return models.PriorityNone
+	case priority <= 2:
+		return models.PriorityUrgent
+	case priority <= 4:
+		return models.PriorityHigh
+	case priority == 5:
+		return models.PriorityMedium
+	default:
+		return models.PriorityLow
+	}
+}
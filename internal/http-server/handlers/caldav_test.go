@@ -0,0 +1,351 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestCalDAVHandler(t *testing.T) {
+	const secret = "feed-secret"
+
+	updated := time.Date(2025, 4, 19, 8, 0, 0, 0, time.UTC)
+	completed := updated.Add(time.Hour)
+	apiTask := models.Task{
+		ID:        1,
+		Title:     "API task",
+		DueDate:   time.Date(2025, 4, 20, 15, 0, 0, 0, time.UTC),
+		Status:    models.StatusTodo,
+		UpdatedAt: updated,
+		Version:   1,
+	}
+	phoneTask := models.Task{
+		ID:          2,
+		Title:       "Phone task",
+		DueDate:     time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC),
+		Status:      models.StatusDone,
+		UpdatedAt:   updated.Add(time.Hour),
+		CompletedAt: &completed,
+		Version:     3,
+		ParentID:    &apiTask.ID,
+	}
+	phoneETag := `"1745053200000000-3"`
+	objects := []models.CalendarObject{{TaskID: 2, Name: "abc.ics", UID: "abc@phone"}}
+
+	todo := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\n" +
+			strings.Join(lines, "\r\n") + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+	query := `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<d:prop><d:getetag/></d:prop><c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">%s` +
+		`</c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`
+
+	cases := []struct {
+		name          string
+		method        string
+		path          string
+		headers       map[string]string
+		body          string
+		noAuth        bool
+		skipIndex     bool
+		listTasks     bool
+		setup         func(taskService *mocks.TaskService, objectService *mocks.CalendarObjectService)
+		expectCode    int
+		expectHeaders map[string]string
+		expectBody    []string
+		absentBody    []string
+	}{
+		{
+			name:          "Options without auth",
+			method:        http.MethodOptions,
+			path:          "/caldav/tasks/",
+			noAuth:        true,
+			expectCode:    http.StatusOK,
+			expectHeaders: map[string]string{"DAV": "1, 3, calendar-access"},
+		},
+		{
+			name:       "Missing token",
+			method:     "PROPFIND",
+			path:       "/caldav/",
+			noAuth:     true,
+			expectCode: http.StatusUnauthorized,
+			expectBody: []string{"feed token is required"},
+		},
+		{
+			name:       "Unknown path",
+			method:     "PROPFIND",
+			path:       "/caldav/other/",
+			noAuth:     true,
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "Propfind home",
+			method:     "PROPFIND",
+			path:       "/caldav/",
+			headers:    map[string]string{"Depth": "0"},
+			body:       `<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:current-user-principal/><c:calendar-home-set/></d:prop></d:propfind>`,
+			expectCode: http.StatusMultiStatus,
+			expectBody: []string{
+				"<D:current-user-principal><D:href>/caldav/</D:href></D:current-user-principal>",
+				"<C:calendar-home-set><D:href>/caldav/</D:href></C:calendar-home-set>",
+			},
+			absentBody: []string{"/caldav/tasks/"},
+		},
+		{
+			name:       "Propfind collection",
+			method:     "PROPFIND",
+			path:       "/caldav/tasks/",
+			headers:    map[string]string{"Depth": "1"},
+			body:       `<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/"><d:prop><d:resourcetype/><d:getetag/><cs:getctag/><x:color xmlns:x="urn:x"/></d:prop></d:propfind>`,
+			listTasks:  true,
+			expectCode: http.StatusMultiStatus,
+			expectBody: []string{
+				"<D:resourcetype><D:collection/><C:calendar/></D:resourcetype>",
+				"<CS:getctag>",
+				`<color xmlns="urn:x"/>`,
+				"<D:href>/caldav/tasks/task-1.ics</D:href>",
+				"<D:href>/caldav/tasks/abc.ics</D:href>",
+				"<D:getetag>" + phoneETag + "</D:getetag>",
+			},
+		},
+		{
+			name:          "Get",
+			method:        http.MethodGet,
+			path:          "/caldav/tasks/abc.ics",
+			expectCode:    http.StatusOK,
+			expectHeaders: map[string]string{"ETag": phoneETag, "Content-Type": "text/calendar; charset=utf-8"},
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(2)).Return(&phoneTask, nil).Once()
+			},
+			expectBody: []string{"UID:abc@phone", "STATUS:COMPLETED", "RELATED-TO;RELTYPE=PARENT:task-1@todo"},
+		},
+		{
+			name:       "Get not modified",
+			method:     http.MethodGet,
+			path:       "/caldav/tasks/abc.ics",
+			headers:    map[string]string{"If-None-Match": phoneETag},
+			expectCode: http.StatusNotModified,
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(2)).Return(&phoneTask, nil).Once()
+			},
+		},
+		{
+			name:       "Get default name of renamed task",
+			method:     http.MethodGet,
+			path:       "/caldav/tasks/task-2.ics",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:    "Put create",
+			method:  http.MethodPut,
+			path:    "/caldav/tasks/new.ics",
+			headers: map[string]string{"If-None-Match": "*"},
+			body: todo("UID:new@phone", "SUMMARY:Купить молоко\\, кефир", "DUE;TZID=Europe/Moscow:20250421T100000",
+				"PRIORITY:1", "CATEGORIES:дом,магазин", "RELATED-TO:abc@phone"),
+			setup: func(taskService *mocks.TaskService, objectService *mocks.CalendarObjectService) {
+				taskService.On("CreateTask", mock.Anything, mock.MatchedBy(func(task models.Task) bool {
+					return task.Title == "Купить молоко, кефир" &&
+						task.DueDate.Equal(time.Date(2025, 4, 21, 7, 0, 0, 0, time.UTC)) &&
+						task.Priority == models.PriorityUrgent &&
+						task.Status == models.StatusTodo &&
+						len(task.Tags) == 2 && task.Tags[1].Name == "магазин" &&
+						task.ParentID != nil && *task.ParentID == 2
+				})).Return(&models.Task{ID: 5}, nil).Once()
+				objectService.On("SaveCalendarObject", mock.Anything,
+					models.CalendarObject{TaskID: 5, Name: "new.ics", UID: "new@phone"}).Return(nil).Once()
+			},
+			expectCode:    http.StatusCreated,
+			expectHeaders: map[string]string{"Location": "/caldav/tasks/new.ics"},
+		},
+		{
+			name:       "Put create with used UID",
+			method:     http.MethodPut,
+			path:       "/caldav/tasks/new.ics",
+			body:       todo("UID:abc@phone", "SUMMARY:copy", "DUE:20250421T070000Z"),
+			expectCode: http.StatusConflict,
+			expectBody: []string{"calendar object UID already used"},
+		},
+		{
+			name:       "Put create without due",
+			method:     http.MethodPut,
+			path:       "/caldav/tasks/new.ics",
+			body:       todo("UID:new@phone", "SUMMARY:no due"),
+			expectCode: http.StatusBadRequest,
+			expectBody: []string{"field due_date is a required field"},
+		},
+		{
+			name:       "Put event",
+			method:     http.MethodPut,
+			path:       "/caldav/tasks/new.ics",
+			body:       "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:e\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			skipIndex:  true,
+			expectCode: http.StatusBadRequest,
+			expectBody: []string{"component VEVENT is not supported, use VTODO"},
+		},
+		{
+			name:    "Put update",
+			method:  http.MethodPut,
+			path:    "/caldav/tasks/task-1.ics",
+			headers: map[string]string{"If-Match": `"1745049600000000-1"`},
+			body:    todo("UID:task-1@todo", "SUMMARY:renamed", "DUE:20250420T150000Z", "STATUS:COMPLETED"),
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(1)).Return(&apiTask, nil).Once()
+				taskService.On("UpdateTask", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					return task.ID == 1 && task.Version == 1 && task.Title == "renamed" &&
+						task.Status == models.StatusDone && task.Priority == models.PriorityNone
+				}), models.SubtasksComplete).Return(nil).Once()
+			},
+			expectCode: http.StatusNoContent,
+		},
+		{
+			name:    "Put complete rejected",
+			method:  http.MethodPut,
+			path:    "/caldav/tasks/task-1.ics",
+			headers: map[string]string{"If-Match": `"1745049600000000-1"`},
+			body:    todo("UID:task-1@todo", "SUMMARY:renamed", "DUE:20250420T150000Z", "STATUS:COMPLETED"),
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(1)).Return(&apiTask, nil).Once()
+				taskService.On("UpdateTask", mock.Anything, mock.Anything, models.SubtasksComplete).
+					Return(storage.ErrVersionMismatch).Once()
+			},
+			expectCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Put update stale",
+			method:  http.MethodPut,
+			path:    "/caldav/tasks/task-1.ics",
+			headers: map[string]string{"If-Match": `"1745049600000000-0"`},
+			body:    todo("UID:task-1@todo", "SUMMARY:renamed", "DUE:20250420T150000Z"),
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(1)).Return(&apiTask, nil).Once()
+			},
+			expectCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "Query open tasks",
+			method:     "REPORT",
+			path:       "/caldav/tasks/",
+			body:       strings.Replace(query, "%s", `<c:prop-filter name="COMPLETED"><c:is-not-defined/></c:prop-filter>`, 1),
+			listTasks:  true,
+			expectCode: http.StatusMultiStatus,
+			expectBody: []string{"<D:href>/caldav/tasks/task-1.ics</D:href>"},
+			absentBody: []string{"abc.ics"},
+		},
+		{
+			name:   "Query time range",
+			method: "REPORT",
+			path:   "/caldav/tasks/",
+			body: strings.Replace(query, "%s",
+				`<c:time-range start="20250422T000000Z" end="20250423T000000Z"/>`, 1),
+			listTasks:  true,
+			expectCode: http.StatusMultiStatus,
+			expectBody: []string{"<D:href>/caldav/tasks/abc.ics</D:href>"},
+			absentBody: []string{"task-1.ics"},
+		},
+		{
+			name:   "Multiget",
+			method: "REPORT",
+			path:   "/caldav/tasks/",
+			body: `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+				`<d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+				`<d:href>/caldav/tasks/abc.ics</d:href><d:href>/caldav/tasks/missing.ics</d:href></c:calendar-multiget>`,
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(2)).Return(&phoneTask, nil).Once()
+			},
+			expectCode: http.StatusMultiStatus,
+			expectBody: []string{
+				"<D:getetag>" + phoneETag + "</D:getetag>",
+				"<C:calendar-data>BEGIN:VCALENDAR&#xD;\n",
+				"UID:abc@phone&#xD;\n",
+				"<D:href>/caldav/tasks/missing.ics</D:href><D:status>HTTP/1.1 404 Not Found</D:status>",
+			},
+		},
+		{
+			name:       "Unsupported report",
+			method:     "REPORT",
+			path:       "/caldav/tasks/",
+			body:       `<d:sync-collection xmlns:d="DAV:"/>`,
+			expectCode: http.StatusForbidden,
+		},
+		{
+			name:    "Delete",
+			method:  http.MethodDelete,
+			path:    "/caldav/tasks/abc.ics",
+			headers: map[string]string{"If-Match": phoneETag},
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(2)).Return(&phoneTask, nil).Once()
+				taskService.On("DeleteTask", mock.Anything, uint(2), int64(3)).Return(nil).Once()
+			},
+			expectCode: http.StatusNoContent,
+		},
+		{
+			name:   "Delete missing",
+			method: http.MethodDelete,
+			path:   "/caldav/tasks/task-7.ics",
+			setup: func(taskService *mocks.TaskService, _ *mocks.CalendarObjectService) {
+				taskService.On("GetByID", mock.Anything, uint(7)).Return(nil, storage.ErrTaskNotFound).Once()
+			},
+			expectCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskServiceMock := mocks.NewTaskService(t)
+			calendarServiceMock := mocks.NewCalendarService(t)
+			objectServiceMock := mocks.NewCalendarObjectService(t)
+
+			if !tc.noAuth {
+				calendarServiceMock.On("FindFeedToken", mock.Anything, mock.Anything).
+					Return(&models.FeedToken{ID: 1, Name: "phone"}, nil).Once()
+				if !tc.skipIndex {
+					objectServiceMock.On("CalendarObjects", mock.Anything).Return(objects, nil).Once()
+				}
+			}
+			if tc.listTasks {
+				taskServiceMock.On("List", mock.Anything, mock.Anything).
+					Return(&models.TasksList{Data: []models.Task{apiTask, phoneTask}, Total: 2}, nil).Once()
+			}
+			if tc.setup != nil {
+				tc.setup(taskServiceMock, objectServiceMock)
+			}
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.CalDAV(log, taskServiceMock, calendarServiceMock, objectServiceMock)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if !tc.noAuth {
+				req.SetBasicAuth("user", secret)
+			}
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req.WithContext(context.Background()))
+
+			require.Equal(t, tc.expectCode, rr.Code, rr.Body.String())
+			for key, value := range tc.expectHeaders {
+				require.Equal(t, value, rr.Header().Get(key))
+			}
+			for _, part := range tc.expectBody {
+				require.Contains(t, rr.Body.String(), part)
+			}
+			for _, part := range tc.absentBody {
+				require.NotContains(t, rr.Body.String(), part)
+			}
+		})
+	}
+}
@@ -0,0 +1,587 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/ical"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// davPrefixes — префиксы пространств имён в ответах Multi-Status.
+var davPrefixes = map[string]string{
+	nsDAV:    "D",
+	nsCalDAV: "C",
+	nsCS:     "CS",
+}
+
+// davNode — элемент XML из тела запроса PROPFIND или REPORT.
+type davNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []davNode  `xml:",any"`
+}
+
+// child возвращает первый дочерний элемент с именем {space}local или nil.
+func (n *davNode) child(space, local string) *davNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Space == space && n.Nodes[i].XMLName.Local == local {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// attr возвращает значение атрибута без пространства имён.
+func (n *davNode) attr(local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// parseDAVBody разбирает тело запроса. Пустое тело возвращается как nil.
+func parseDAVBody(body io.Reader) (*davNode, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var root davNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	return &root, nil
+}
+
+// davPropRequest — свойства, которые запросил клиент: перечисленные в
+// DAV:prop или все (DAV:allprop и пустой PROPFIND).
+type davPropRequest struct {
+	all   bool
+	names []xml.Name
+}
+
+func newDAVPropRequest(root *davNode) davPropRequest {
+	if root == nil {
+		return davPropRequest{all: true}
+	}
+
+	prop := root.child(nsDAV, "prop")
+	if prop == nil {
+		return davPropRequest{all: true}
+	}
+
+	req := davPropRequest{}
+	for _, node := range prop.Nodes {
+		req.names = append(req.names, node.XMLName)
+	}
+
+	return req
+}
+
+// has сообщает, что свойство запрошено по имени.
+func (req davPropRequest) has(space, local string) bool {
+	for _, name := range req.names {
+		if name.Space == space && name.Local == local {
+			return true
+		}
+	}
+	return false
+}
+
+// davProp — свойство ресурса; value — его содержимое в XML.
+type davProp struct {
+	name  xml.Name
+	value string
+}
+
+// multistatus собирает ответ 207 Multi-Status (RFC 4918, 13).
+type multistatus struct {
+	buf strings.Builder
+}
+
+// response добавляет ресурс с запрошенными свойствами. Неизвестные
+// свойства попадают в propstat с кодом 404.
+func (m *multistatus) response(href string, props []davProp, req davPropRequest) {
+	var found, missing []davProp
+	if req.all {
+		found = props
+	} else {
+		for _, name := range req.names {
+			i := indexDAVProp(props, name)
+			if i < 0 {
+				missing = append(missing, davProp{name: name})
+				continue
+			}
+			found = append(found, props[i])
+		}
+	}
+
+	m.buf.WriteString("<D:response><D:href>" + xmlText(href) + "</D:href>")
+	m.propstat(found, "HTTP/1.1 200 OK")
+	m.propstat(missing, "HTTP/1.1 404 Not Found")
+	m.buf.WriteString("</D:response>")
+}
+
+// notFound добавляет ресурс, которого нет.
+func (m *multistatus) notFound(href string) {
+	m.buf.WriteString("<D:response><D:href>" + xmlText(href) + "</D:href>" +
+		"<D:status>HTTP/1.1 404 Not Found</D:status></D:response>")
+}
+
+func (m *multistatus) propstat(props []davProp, status string) {
+	if len(props) == 0 {
+		return
+	}
+
+	m.buf.WriteString("<D:propstat><D:prop>")
+	for _, prop := range props {
+		m.buf.WriteString(davElement(prop.name, prop.value))
+	}
+	m.buf.WriteString("</D:prop><D:status>" + status + "</D:status></D:propstat>")
+}
+
+// write отправляет ответ клиенту.
+func (m *multistatus) write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?>`+"\n"+
+		`<D:multistatus xmlns:D="DAV:" xmlns:C="`+nsCalDAV+`" xmlns:CS="`+nsCS+`">`+
+		m.buf.String()+"</D:multistatus>\n")
+}
+
+func indexDAVProp(props []davProp, name xml.Name) int {
+	for i, prop := range props {
+		if prop.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// davElement возвращает элемент XML. Для известных пространств имён
+// используется префикс, для прочих — атрибут xmlns.
+func davElement(name xml.Name, inner string) string {
+	tag, open := name.Local, name.Local
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+		open = tag
+	} else if name.Space != "" {
+		open = name.Local + ` xmlns="` + strings.ReplaceAll(xmlText(name.Space), `"`, "&quot;") + `"`
+	}
+
+	if inner == "" {
+		return "<" + open + "/>"
+	}
+	return "<" + open + ">" + inner + "</" + tag + ">"
+}
+
+// xmlEscaper экранирует текст XML. Кавычки не экранируются, чтобы ETag
+// читались без декодирования, а CR — экранируется, иначе разбор XML
+// превратит CRLF в строках calendar-data в LF.
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r", "&#xD;",
+)
+
+// xmlText экранирует текст для XML.
+func xmlText(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+func davName(space, local string) xml.Name {
+	return xml.Name{Space: space, Local: local}
+}
+
+func davHref(href string) string {
+	return "<D:href>" + xmlText(href) + "</D:href>"
+}
+
+// davPrivileges — права клиента на любой ресурс.
+const davPrivileges = "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>"
+
+// homeProps — свойства принципала, он же домашний набор календарей.
+func homeProps() []davProp {
+	return []davProp{
+		{davName(nsDAV, "resourcetype"), "<D:collection/><D:principal/>"},
+		{davName(nsDAV, "displayname"), "ToDo"},
+		{davName(nsDAV, "current-user-principal"), davHref(caldavRoot)},
+		{davName(nsDAV, "principal-URL"), davHref(caldavRoot)},
+		{davName(nsCalDAV, "calendar-home-set"), davHref(caldavRoot)},
+		{davName(nsDAV, "current-user-privilege-set"), davPrivileges},
+	}
+}
+
+// collectionProps — свойства календаря с задачами.
+func collectionProps(tasks []models.Task, index *calendarIndex) []davProp {
+	ctag := calendarCTag(tasks, index)
+
+	return []davProp{
+		{davName(nsDAV, "resourcetype"), "<D:collection/><C:calendar/>"},
+		{davName(nsDAV, "displayname"), "Задачи"},
+		{davName(nsDAV, "current-user-principal"), davHref(caldavRoot)},
+		{davName(nsCalDAV, "supported-calendar-component-set"), `<C:comp name="VTODO"/>`},
+		{davName(nsDAV, "supported-report-set"),
+			"<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+				"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>"},
+		{davName(nsCS, "getctag"), ctag},
+		{davName(nsDAV, "getetag"), xmlText(`"` + ctag + `"`)},
+		{davName(nsDAV, "current-user-privilege-set"), davPrivileges},
+	}
+}
+
+// objectProps — свойства ресурса задачи. calendar-data добавляется, только
+// если передан data: клиенты запрашивают его по имени.
+func objectProps(task models.Task, data []byte) []davProp {
+	props := []davProp{
+		{davName(nsDAV, "resourcetype"), ""},
+		{davName(nsDAV, "getetag"), xmlText(calendarETag(task))},
+		{davName(nsDAV, "getcontenttype"), "text/calendar; charset=utf-8; component=VTODO"},
+		{davName(nsDAV, "getlastmodified"), task.UpdatedAt.UTC().Format(http.TimeFormat)},
+		{davName(nsDAV, "current-user-principal"), davHref(caldavRoot)},
+	}
+	if data != nil {
+		props = append(props, davProp{davName(nsCalDAV, "calendar-data"), xmlText(string(data))})
+	}
+
+	return props
+}
+
+// propfind отдаёт свойства ресурса, а с Depth: 1 — и его содержимого.
+// Depth: infinity обрабатывается как 1.
+func (h caldav) propfind(w http.ResponseWriter, r *http.Request, log *slog.Logger, kind caldavKind, name string) {
+	root, err := parseDAVBody(r.Body)
+	if err != nil || (root != nil && root.XMLName != davName(nsDAV, "propfind")) {
+		log.Error("invalid propfind request", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("invalid propfind request"))
+		return
+	}
+	req := newDAVPropRequest(root)
+	withChildren := r.Header.Get("Depth") != "0"
+	withData := req.has(nsCalDAV, "calendar-data")
+
+	index, err := h.index(r.Context())
+	if err != nil {
+		writeError(w, r, log, err, "failed to get calendar objects")
+		return
+	}
+
+	var ms multistatus
+	switch kind {
+	case caldavHome:
+		ms.response(caldavRoot, homeProps(), req)
+		if withChildren {
+			tasks, err := h.allTasks(r.Context())
+			if err != nil {
+				writeError(w, r, log, err, "failed to list tasks")
+				return
+			}
+			ms.response(caldavCollection, collectionProps(tasks, index), req)
+		}
+	case caldavTasks:
+		tasks, err := h.allTasks(r.Context())
+		if err != nil {
+			writeError(w, r, log, err, "failed to list tasks")
+			return
+		}
+		ms.response(caldavCollection, collectionProps(tasks, index), req)
+		if withChildren {
+			for _, task := range tasks {
+				if err := h.writeObject(&ms, index.href(task.ID), task, index, req, withData); err != nil {
+					writeError(w, r, log, err, "failed to write calendar")
+					return
+				}
+			}
+		}
+	case caldavObject:
+		task, err := h.object(r.Context(), index, name)
+		if err != nil {
+			writeTaskError(w, r, log, err, 0, "failed to get task")
+			return
+		}
+		if err := h.writeObject(&ms, index.href(task.ID), *task, index, req, withData); err != nil {
+			writeError(w, r, log, err, "failed to write calendar")
+			return
+		}
+	}
+
+	ms.write(w)
+}
+
+// writeObject добавляет в ответ ресурс задачи.
+func (h caldav) writeObject(ms *multistatus, href string, task models.Task, index *calendarIndex, req davPropRequest, withData bool) error {
+	var data []byte
+	if withData {
+		var err error
+		if data, err = calendarData(task, index); err != nil {
+			return err
+		}
+	}
+
+	ms.response(href, objectProps(task, data), req)
+
+	return nil
+}
+
+// report выполняет REPORT calendar-query и calendar-multiget (RFC 4791,
+// 7.8 и 7.9) над календарём с задачами.
+func (h caldav) report(w http.ResponseWriter, r *http.Request, log *slog.Logger) {
+	root, err := parseDAVBody(r.Body)
+	if err != nil || root == nil {
+		log.Error("invalid report request", sl.Err(err))
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("invalid report request"))
+		return
+	}
+	req := newDAVPropRequest(root)
+	withData := req.has(nsCalDAV, "calendar-data")
+
+	index, err := h.index(r.Context())
+	if err != nil {
+		writeError(w, r, log, err, "failed to get calendar objects")
+		return
+	}
+
+	var ms multistatus
+	switch root.XMLName {
+	case davName(nsCalDAV, "calendar-query"):
+		tasks, err := h.allTasks(r.Context())
+		if err != nil {
+			writeError(w, r, log, err, "failed to list tasks")
+			return
+		}
+
+		var filter *davNode
+		if node := root.child(nsCalDAV, "filter"); node != nil {
+			filter = node.child(nsCalDAV, "comp-filter")
+		}
+
+		for _, task := range tasks {
+			if filter != nil {
+				matched, err := matchTask(task, index, filter)
+				if err != nil {
+					writeError(w, r, log, err, "failed to filter tasks")
+					return
+				}
+				if !matched {
+					continue
+				}
+			}
+			if err := h.writeObject(&ms, index.href(task.ID), task, index, req, withData); err != nil {
+				writeError(w, r, log, err, "failed to write calendar")
+				return
+			}
+		}
+	case davName(nsCalDAV, "calendar-multiget"):
+		for _, node := range root.Nodes {
+			if node.XMLName != davName(nsDAV, "href") {
+				continue
+			}
+			href := strings.TrimSpace(node.Text)
+
+			task, err := h.multigetObject(r, index, href)
+			if errors.Is(err, storage.ErrTaskNotFound) {
+				ms.notFound(href)
+				continue
+			}
+			if err != nil {
+				writeError(w, r, log, err, "failed to get task")
+				return
+			}
+			if err := h.writeObject(&ms, href, *task, index, req, withData); err != nil {
+				writeError(w, r, log, err, "failed to write calendar")
+				return
+			}
+		}
+	default:
+		log.Info("unsupported report", slog.String("report", root.XMLName.Local))
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, resp.Error("unsupported report, use calendar-query or calendar-multiget"))
+		return
+	}
+
+	ms.write(w)
+}
+
+// multigetObject возвращает задачу по адресу из calendar-multiget.
+func (h caldav) multigetObject(r *http.Request, index *calendarIndex, href string) (*models.Task, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	kind, name, ok := parseCalDAVPath(u.Path)
+	if !ok || kind != caldavObject {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	return h.object(r.Context(), index, name)
+}
+
+// matchTask проверяет задачу фильтром calendar-query. Фильтр применяется
+// к тому же VTODO, который получит клиент.
+func matchTask(task models.Task, index *calendarIndex, filter *davNode) (bool, error) {
+	data, err := calendarData(task, index)
+	if err != nil {
+		return false, err
+	}
+
+	calendar, err := ical.Parse(bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("parse task calendar: %w", err)
+	}
+
+	return strings.EqualFold(filter.attr("name"), calendar.Name) && matchComponent(calendar, filter), nil
+}
+
+// matchComponent проверяет компонент условиями comp-filter: time-range,
+// prop-filter и вложенными comp-filter (RFC 4791, 9.7.1).
+func matchComponent(component *ical.Component, filter *davNode) bool {
+	for i := range filter.Nodes {
+		node := &filter.Nodes[i]
+		if node.XMLName.Space != nsCalDAV {
+			continue
+		}
+
+		switch node.XMLName.Local {
+		case "time-range":
+			if !componentInRange(component, node) {
+				return false
+			}
+		case "prop-filter":
+			if !matchPropFilter(component, node) {
+				return false
+			}
+		case "comp-filter":
+			var children []*ical.Component
+			for _, child := range component.Components {
+				if strings.EqualFold(child.Name, node.attr("name")) {
+					children = append(children, child)
+				}
+			}
+
+			if node.child(nsCalDAV, "is-not-defined") != nil {
+				if len(children) > 0 {
+					return false
+				}
+				continue
+			}
+
+			matched := false
+			for _, child := range children {
+				if matchComponent(child, node) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// matchPropFilter проверяет свойства компонента условием prop-filter:
+// is-not-defined, time-range или text-match без учёта регистра.
+// Фильтры параметров не поддерживаются и не ограничивают выборку.
+func matchPropFilter(component *ical.Component, filter *davNode) bool {
+	var props []ical.Property
+	for _, prop := range component.Props {
+		if strings.EqualFold(prop.Name, filter.attr("name")) {
+			props = append(props, prop)
+		}
+	}
+
+	if filter.child(nsCalDAV, "is-not-defined") != nil {
+		return len(props) == 0
+	}
+
+	timeRange := filter.child(nsCalDAV, "time-range")
+	textMatch := filter.child(nsCalDAV, "text-match")
+	for _, prop := range props {
+		if timeRange != nil && !propInRange(prop, timeRange) {
+			continue
+		}
+		if textMatch != nil && !matchText(prop, textMatch) {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+func matchText(prop ical.Property, textMatch *davNode) bool {
+	contains := strings.Contains(
+		strings.ToLower(prop.Text()),
+		strings.ToLower(strings.TrimSpace(textMatch.Text)),
+	)
+	return contains != (textMatch.attr("negate-condition") == "yes")
+}
+
+// componentInRange проверяет срок VTODO: DUE, а без него DTSTART. Задача
+// без срока подходит под любой интервал.
+func componentInRange(component *ical.Component, timeRange *davNode) bool {
+	prop := component.Prop("DUE")
+	if prop == nil {
+		prop = component.Prop("DTSTART")
+	}
+	if prop == nil {
+		return true
+	}
+
+	return propInRange(*prop, timeRange)
+}
+
+// propInRange проверяет, что время свойства попадает в интервал
+// [start, end). Отсутствующая граница интервал не ограничивает.
+func propInRange(prop ical.Property, timeRange *davNode) bool {
+	t, err := prop.Time(time.UTC)
+	if err != nil {
+		return false
+	}
+
+	for _, bound := range []struct {
+		attr  string
+		check func(bound time.Time) bool
+	}{
+		{"start", func(start time.Time) bool { return !t.Before(start) }},
+		{"end", func(end time.Time) bool { return t.Before(end) }},
+	} {
+		value := timeRange.attr(bound.attr)
+		if value == "" {
+			continue
+		}
+		limit, err := (&ical.Property{Value: value}).Time(time.UTC)
+		if err != nil || !bound.check(limit) {
+			return false
+		}
+	}
+
+	return true
+}
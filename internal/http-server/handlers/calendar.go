@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/ical"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// calendarBatch — сколько задач читается за раз при выгрузке календаря.
+const calendarBatch = 500
+
+// calendarRefresh — как часто клиенту предлагается обновлять календарь
+// (RFC 7986, REFRESH-INTERVAL).
+const calendarRefresh = "PT15M"
+
+//go:generate mockery --name=CalendarService --output=mocks --outpkg=mocks
+type CalendarService interface {
+	CreateFeedToken(ctx context.Context, name, hash string) (*models.FeedToken, error)
+	ListFeedTokens(ctx context.Context) ([]models.FeedToken, error)
+	DeleteFeedToken(ctx context.Context, id uint) error
+	FindFeedToken(ctx context.Context, hash string) (*models.FeedToken, error)
+}
+
+// CreateFeedToken godoc
+// @Summary Выпустить токен календаря
+// @Description Создать токен доступа к календарю задач GET /tasks.ics. Токен возвращается только в ответе на этот запрос; хранится лишь его хеш.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param request body models.FeedToken true "Для кого выпускается токен"
+// @Success 201 {object} handlers.Response{data=models.FeedToken}
+// @Header 201 {string} Location "/calendar/tokens/{id}"
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /calendar/tokens [post]
+func CreateFeedToken(log *slog.Logger, calendarService CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.CreateFeedToken"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		var req models.FeedToken
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidatorError(validateErr))
+			return
+		}
+
+		secret, err := newFeedToken()
+		if err != nil {
+			writeError(w, r, log, err, "failed to create feed token")
+			return
+		}
+
+		token, err := calendarService.CreateFeedToken(r.Context(), req.Name, feedTokenHash(secret))
+		if err != nil {
+			writeError(w, r, log, err, "failed to create feed token")
+			return
+		}
+		token.Token = secret
+
+		log.Info("feed token created", slog.Int64("id", token.ID))
+
+		w.Header().Set("Location", fmt.Sprintf("/calendar/tokens/%d", token.ID))
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   token,
+		})
+	}
+}
+
+// ListFeedTokens godoc
+// @Summary Получить список токенов календаря
+// @Description Получить токены календаря в порядке создания, без самих токенов
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} handlers.Response{data=[]models.FeedToken}
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /calendar/tokens [get]
+func ListFeedTokens(log *slog.Logger, calendarService CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ListFeedTokens"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		tokens, err := calendarService.ListFeedTokens(r.Context())
+		if err != nil {
+			writeError(w, r, log, err, "failed to list feed tokens")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tokens,
+		})
+	}
+}
+
+// DeleteFeedToken godoc
+// @Summary Отозвать токен календаря
+// @Description Удалить токен: календарь по нему больше не выгружается
+// @Tags calendar
+// @Produce json
+// @Param id path int true "ID токена"
+// @Success 200 {object} handlers.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /calendar/tokens/{id} [delete]
+func DeleteFeedToken(log *slog.Logger, calendarService CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.DeleteFeedToken"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		if err := calendarService.DeleteFeedToken(r.Context(), uint(id)); err != nil {
+			if errors.Is(err, storage.ErrFeedTokenNotFound) {
+				log.Info("feed token not found", slog.Int64("id", id))
+				w.WriteHeader(http.StatusNotFound)
+				render.JSON(w, r, resp.Error("feed token not found"))
+				return
+			}
+			writeError(w, r, log, err, "failed to delete feed token")
+			return
+		}
+
+		log.Info("feed token deleted", slog.Int64("id", id))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+		})
+	}
+}
+
+// TaskCalendar godoc
+// @Summary Календарь задач
+// @Description Задачи в формате iCalendar (RFC 5545) для подписки из календарных приложений: VTODO на каждую задачу со сроком DUE, статусом, названием и описанием, с include=events — ещё и VEVENT в момент срока. Фильтры те же, что у GET /tasks; страницы не применяются, выгружаются все подходящие задачи. Токен календаря передаётся параметром token или паролем HTTP Basic; в журнале запросов значение token скрывается.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token query string false "Токен календаря, в журнале запросов заменяется на REDACTED"
+// @Param include query string false "events - добавить VEVENT для каждой задачи" Enums(events)
+// @Param completed query bool false "Совместимость: true - закрытые задачи (done или cancelled), false - открытые"
+// @Param status query []string false "Статусы задачи" collectionFormat(multi) Enums(todo, in_progress, blocked, done, cancelled)
+// @Param blocked query bool false "Блокировка (true - ждёт невыполненных задач, false - можно делать)"
+// @Param date query string false "Дата в формате YYYY-MM-DD"
+// @Param priority query string false "Приоритет задачи" Enums(none, low, medium, high, urgent)
+// @Param tag query []string false "Названия меток" collectionFormat(multi)
+// @Param tag_match query string false "Режим фильтра по меткам: any - хотя бы одна, all - все" Enums(any, all) default(any)
+// @Success 200 {string} string "Календарь iCalendar"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks.ics [get]
+func TaskCalendar(log *slog.Logger, taskService TaskService, calendarService CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.TaskCalendar"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		secret := r.URL.Query().Get("token")
+		if _, password, ok := r.BasicAuth(); ok && secret == "" {
+			secret = password
+		}
+		if secret == "" {
+			log.Info("feed token is missing")
+			w.Header().Set("WWW-Authenticate", `Basic realm="tasks"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("feed token is required"))
+			return
+		}
+
+		token, err := calendarService.FindFeedToken(r.Context(), feedTokenHash(secret))
+		if errors.Is(err, storage.ErrFeedTokenNotFound) {
+			log.Info("invalid feed token")
+			w.Header().Set("WWW-Authenticate", `Basic realm="tasks"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("invalid feed token"))
+			return
+		}
+		if err != nil {
+			writeError(w, r, log, err, "failed to check feed token")
+			return
+		}
+		log = log.With(slog.Int64("feed_token_id", token.ID))
+
+		include := r.URL.Query().Get("include")
+		if include != "" && include != "events" {
+			log.Error("invalid include parameter", slog.String("include", include))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid include parameter, use events"))
+			return
+		}
+
+		filter, err := parseTaskFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		tasks, err := allTasks(r.Context(), taskService, filter)
+		if err != nil {
+			writeError(w, r, log, err, "failed to list tasks")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+		// В адресе календаря может быть токен, поэтому общие кеши не должны
+		// его сохранять.
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		if err := writeTaskCalendar(w, tasks, include == "events"); err != nil {
+			log.Error("failed to write calendar", sl.Err(err))
+			return
+		}
+
+		log.Info("calendar exported", slog.Int("tasks", len(tasks)))
+	}
+}
+
+// allTasks читает все задачи по фильтру, страница за страницей.
+func allTasks(ctx context.Context, taskService TaskService, filter models.TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	err := forEachTaskPage(ctx, taskService, filter, calendarBatch, func(page []models.Task) error {
+		tasks = append(tasks, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// forEachTaskPage читает задачи по фильтру страницами по batch задач и
+// передаёт каждую страницу fn. Ошибка fn прекращает чтение.
+func forEachTaskPage(ctx context.Context, taskService TaskService, filter models.TaskFilter, batch int, fn func(page []models.Task) error) error {
+	filter.Limit = batch
+
+	var read int64
+	for filter.Page = 1; ; filter.Page++ {
+		list, err := taskService.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(list.Data); err != nil {
+			return err
+		}
+
+		read += int64(len(list.Data))
+		if len(list.Data) < batch || read >= list.Total {
+			return nil
+		}
+	}
+}
+
+// writeTaskCalendar пишет задачи календарём iCalendar.
+func writeTaskCalendar(w http.ResponseWriter, tasks []models.Task, withEvents bool) error {
+	cw := ical.NewWriter(w)
+
+	cw.Begin("VCALENDAR")
+	cw.Raw("VERSION", "2.0")
+	cw.Raw("PRODID", "-//todo//ToDo API//RU")
+	cw.Raw("CALSCALE", "GREGORIAN")
+	cw.Text("NAME", "Задачи")
+	cw.Text("X-WR-CALNAME", "Задачи")
+	cw.Raw("REFRESH-INTERVAL;VALUE=DURATION", calendarRefresh)
+	cw.Raw("X-PUBLISHED-TTL", calendarRefresh)
+
+	for _, task := range tasks {
+		writeTaskTodo(cw, task, taskUID)
+		if withEvents {
+			writeTaskDueEvent(cw, task)
+		}
+	}
+
+	cw.End("VCALENDAR")
+
+	return cw.Flush()
+}
+
+// writeTaskTodo пишет задачу компонентом VTODO; uid возвращает UID задачи
+// по её id. DTSTAMP без METHOD означает время последнего изменения
+// (RFC 5545, 3.8.7.2), поэтому совпадает с LAST-MODIFIED, и выгрузка не
+// меняется, пока не меняются задачи.
+func writeTaskTodo(cw *ical.Writer, task models.Task, uid func(id int64) string) {
+	cw.Begin("VTODO")
+	cw.Text("UID", uid(task.ID))
+	cw.Time("DTSTAMP", task.UpdatedAt)
+	cw.Time("CREATED", task.CreatedAt)
+	cw.Time("LAST-MODIFIED", task.UpdatedAt)
+	cw.Raw("SEQUENCE", strconv.FormatInt(max(task.Version-1, 0), 10))
+	cw.Text("SUMMARY", task.Title)
+	if task.Description != "" {
+		cw.Text("DESCRIPTION", task.Description)
+	}
+	cw.Time("DUE", task.DueDate)
+	cw.Raw("STATUS", todoStatus(task.Status))
+	if task.CompletedAt != nil {
+		cw.Time("COMPLETED", *task.CompletedAt)
+		cw.Raw("PERCENT-COMPLETE", "100")
+	}
+	if priority := todoPriority(task.Priority); priority != 0 {
+		cw.Raw("PRIORITY", strconv.Itoa(priority))
+	}
+	if len(task.Tags) > 0 {
+		cw.TextList("CATEGORIES", sortedTagNames(task.Tags))
+	}
+	if task.ParentID != nil {
+		cw.Text("RELATED-TO;RELTYPE=PARENT", uid(*task.ParentID))
+	}
+	cw.End("VTODO")
+}
+
+// writeTaskDueEvent пишет срок задачи событием VEVENT для приложений,
+// которые не показывают VTODO. Событие не занимает время в расписании.
+func writeTaskDueEvent(cw *ical.Writer, task models.Task) {
+	cw.Begin("VEVENT")
+	cw.Text("UID", fmt.Sprintf("task-%d-due@todo", task.ID))
+	cw.Time("DTSTAMP", task.UpdatedAt)
+	cw.Time("CREATED", task.CreatedAt)
+	cw.Time("LAST-MODIFIED", task.UpdatedAt)
+	cw.Raw("SEQUENCE", strconv.FormatInt(max(task.Version-1, 0), 10))
+	cw.Text("SUMMARY", task.Title)
+	if task.Description != "" {
+		cw.Text("DESCRIPTION", task.Description)
+	}
+	cw.Time("DTSTART", task.DueDate)
+	cw.Raw("TRANSP", "TRANSPARENT")
+	if task.Status == models.StatusCancelled {
+		cw.Raw("STATUS", "CANCELLED")
+	}
+	if len(task.Tags) > 0 {
+		cw.TextList("CATEGORIES", sortedTagNames(task.Tags))
+	}
+	cw.End("VEVENT")
+}
+
+// taskUID — постоянный идентификатор задачи в календаре.
+func taskUID(id int64) string {
+	return fmt.Sprintf("task-%d@todo", id)
+}
+
+// todoStatus переводит статус задачи в STATUS компонента VTODO.
+func todoStatus(status models.TaskStatus) string {
+	switch status {
+	case models.StatusInProgress:
+		return "IN-PROCESS"
+	case models.StatusDone:
+		return "COMPLETED"
+	case models.StatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// todoPriority переводит приоритет в PRIORITY: 1 — наивысший, 9 —
+// наименьший, 0 — не задан.
+func todoPriority(priority models.Priority) int {
+	switch priority {
+	case models.PriorityUrgent:
+		return 1
+	case models.PriorityHigh:
+		return 3
+	case models.PriorityMedium:
+		return 5
+	case models.PriorityLow:
+		return 7
+	default:
+		return 0
+	}
+}
+
+// newFeedToken генерирует токен календаря: 32 случайных байта в hex.
+func newFeedToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("generate feed token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// feedTokenHash возвращает SHA-256 токена в hex: хранилище держит только
+// хеш, чтобы утечка базы не открывала календари.
+func feedTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
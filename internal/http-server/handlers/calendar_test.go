@@ -0,0 +1,188 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestTaskCalendarHandler(t *testing.T) {
+	const secret = "feed-secret"
+	sum := sha256.Sum256([]byte(secret))
+	hash := hex.EncodeToString(sum[:])
+
+	created := time.Date(2025, 4, 17, 10, 30, 0, 0, time.UTC)
+	completed := time.Date(2025, 4, 19, 8, 0, 0, 0, time.UTC)
+	parentID := int64(1)
+	tasks := []models.Task{
+		{
+			ID:          2,
+			Title:       "Купить молоко; кефир",
+			Description: "2 литра\nв магазине у дома",
+			DueDate:     time.Date(2025, 4, 20, 15, 0, 0, 0, time.UTC),
+			Status:      models.StatusDone,
+			Priority:    models.PriorityHigh,
+			CreatedAt:   created,
+			UpdatedAt:   completed,
+			CompletedAt: &completed,
+			Version:     3,
+			Tags:        []models.Tag{{Name: "магазин"}, {Name: "дом"}},
+			ParentID:    &parentID,
+		},
+	}
+
+	cases := []struct {
+		name         string
+		query        string
+		basicAuth    bool
+		tokenMissing bool
+		expectFilter func(filter models.TaskFilter) bool
+		expectCode   int
+		expectLines  []string
+		absentLines  []string
+		respError    string
+	}{
+		{
+			name:       "Feed",
+			query:      "?token=" + secret,
+			expectCode: http.StatusOK,
+			expectLines: []string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"BEGIN:VTODO",
+				"UID:task-2@todo",
+				"DTSTAMP:20250419T080000Z",
+				"LAST-MODIFIED:20250419T080000Z",
+				"SEQUENCE:2",
+				`SUMMARY:Купить молоко\; кефир`,
+				`DESCRIPTION:2 литра\nв магазине у дома`,
+				"DUE:20250420T150000Z",
+				"STATUS:COMPLETED",
+				"COMPLETED:20250419T080000Z",
+				"PRIORITY:3",
+				"CATEGORIES:дом,магазин",
+				"RELATED-TO;RELTYPE=PARENT:task-1@todo",
+				"END:VTODO",
+				"END:VCALENDAR",
+			},
+			absentLines: []string{"BEGIN:VEVENT"},
+		},
+		{
+			name:       "Filters",
+			query:      "?token=" + secret + "&completed=false&date=2025-04-20&page=3&limit=1",
+			expectCode: http.StatusOK,
+			expectFilter: func(filter models.TaskFilter) bool {
+				return filter.Completed != nil && !*filter.Completed &&
+					filter.Date != nil && filter.Date.Format("2006-01-02") == "2025-04-20" &&
+					filter.Page == 1 && filter.Limit == 500
+			},
+			expectLines: []string{"BEGIN:VTODO"},
+		},
+		{
+			name:        "Events",
+			query:       "?token=" + secret + "&include=events",
+			expectCode:  http.StatusOK,
+			expectLines: []string{"BEGIN:VTODO", "BEGIN:VEVENT", "UID:task-2-due@todo", "DTSTART:20250420T150000Z", "TRANSP:TRANSPARENT"},
+		},
+		{
+			name:        "Basic auth",
+			basicAuth:   true,
+			expectCode:  http.StatusOK,
+			expectLines: []string{"BEGIN:VTODO"},
+		},
+		{
+			name:         "Missing token",
+			tokenMissing: true,
+			expectCode:   http.StatusUnauthorized,
+			respError:    "feed token is required",
+		},
+		{
+			name:       "Invalid token",
+			query:      "?token=wrong",
+			expectCode: http.StatusUnauthorized,
+			respError:  "invalid feed token",
+		},
+		{
+			name:       "Invalid include",
+			query:      "?token=" + secret + "&include=progress",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid include parameter, use events",
+		},
+		{
+			name:       "Invalid date",
+			query:      "?token=" + secret + "&date=20.04.2025",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid date format, use YYYY-MM-DD",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskServiceMock := mocks.NewTaskService(t)
+			calendarServiceMock := mocks.NewCalendarService(t)
+
+			if !tc.tokenMissing {
+				calendarServiceMock.On("FindFeedToken", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, got string) (*models.FeedToken, error) {
+						if got != hash {
+							return nil, storage.ErrFeedTokenNotFound
+						}
+						return &models.FeedToken{ID: 1, Name: "phone"}, nil
+					}).Once()
+			}
+
+			if tc.expectCode == http.StatusOK {
+				var match any = mock.Anything
+				if tc.expectFilter != nil {
+					match = mock.MatchedBy(tc.expectFilter)
+				}
+				taskServiceMock.On("List", mock.Anything, match).
+					Return(&models.TasksList{Data: tasks, Total: int64(len(tasks))}, nil).Once()
+			}
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.TaskCalendar(log, taskServiceMock, calendarServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks.ics"+tc.query, nil)
+			if tc.basicAuth {
+				req.SetBasicAuth("", secret)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			if tc.respError != "" {
+				require.Contains(t, rr.Body.String(), tc.respError)
+				return
+			}
+
+			require.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+
+			body := rr.Body.String()
+			require.True(t, strings.HasSuffix(body, "\r\n"))
+			lines := strings.Split(strings.ReplaceAll(body, "\r\n ", ""), "\r\n")
+			for _, line := range tc.expectLines {
+				require.Contains(t, lines, line)
+			}
+			for _, line := range tc.absentLines {
+				require.NotContains(t, lines, line)
+			}
+		})
+	}
+}
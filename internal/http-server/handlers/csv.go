@@ -0,0 +1,557 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// exportBatch — сколько задач читается за раз при выгрузке в CSV.
+const exportBatch = 500
+
+// importMaxBody ограничивает размер загружаемого CSV.
+const importMaxBody = 10 << 20
+
+// exportColumns — столбцы выгрузки. Первые одиннадцать импорт узнаёт по
+// названию, так что выгрузку можно загрузить обратно без сопоставления.
+var exportColumns = []string{
+	"id", "title", "description", "due_date", "status", "priority", "tags",
+	"project_id", "parent_id", "rrule", "timezone",
+	"created_at", "updated_at", "completed_at", "cancelled_at",
+}
+
+// importFields — поля задачи, которые можно загрузить из CSV.
+var importFields = []string{
+	"id", "title", "description", "due_date", "status", "priority", "tags",
+	"project_id", "parent_id", "rrule", "timezone",
+}
+
+// importDateLayouts — форматы срока задачи в CSV помимо RFC 3339. Время
+// без пояса считается в поясе задачи, а если он не задан — в UTC.
+var importDateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// ExportTasks godoc
+// @Summary Выгрузить задачи в CSV
+// @Description Выгрузить все задачи, подходящие под фильтры GET /tasks, в CSV с заголовком. Страницы не применяются: задачи читаются из хранилища частями и сразу отправляются клиенту. Метки перечисляются через запятую, даты — в RFC 3339.
+// @Tags tasks
+// @Produce text/csv
+// @Param format query string false "Формат выгрузки" Enums(csv) default(csv)
+// @Param delimiter query string false "Разделитель полей: один символ или tab" default(,)
+// @Param completed query bool false "Совместимость: true - закрытые задачи (done или cancelled), false - открытые"
+// @Param status query []string false "Статусы задачи" collectionFormat(multi) Enums(todo, in_progress, blocked, done, cancelled)
+// @Param blocked query bool false "Блокировка (true - ждёт невыполненных задач, false - можно делать)"
+// @Param date query string false "Дата в формате YYYY-MM-DD"
+// @Param priority query string false "Приоритет задачи" Enums(none, low, medium, high, urgent)
+// @Param tag query []string false "Названия меток" collectionFormat(multi)
+// @Param tag_match query string false "Режим фильтра по меткам: any - хотя бы одна, all - все" Enums(any, all) default(any)
+// @Param sort query string false "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку" Enums(due_date, priority) default(due_date)
+// @Success 200 {string} string "Задачи в CSV"
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/export [get]
+func ExportTasks(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ExportTasks"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+			log.Error("invalid format parameter", slog.String("format", format))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid format parameter, use csv"))
+			return
+		}
+
+		delimiter, err := parseDelimiter(r.URL.Query().Get("delimiter"))
+		if err != nil {
+			log.Error("invalid delimiter parameter", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		filter, err := parseTaskFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		cw := csv.NewWriter(w)
+		cw.Comma = delimiter
+		rc := http.NewResponseController(w)
+
+		// Заголовки ответа отправляются с первой страницей: пока она не
+		// прочитана, об ошибке хранилища ещё можно сообщить кодом ответа.
+		started := false
+		exported := 0
+		err = forEachTaskPage(r.Context(), taskService, filter, exportBatch, func(page []models.Task) error {
+			if !started {
+				started = true
+				w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+				w.Header().Set("Content-Disposition", `attachment; filename="tasks.csv"`)
+				w.WriteHeader(http.StatusOK)
+				if err := cw.Write(exportColumns); err != nil {
+					return err
+				}
+			}
+
+			for _, task := range page {
+				if err := cw.Write(taskRecord(task)); err != nil {
+					return err
+				}
+			}
+			exported += len(page)
+
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			if !started {
+				writeError(w, r, log, err, "failed to list tasks")
+				return
+			}
+			log.Error("failed to export tasks", slog.Int("exported", exported), sl.Err(err))
+			return
+		}
+
+		log.Info("tasks exported", slog.Int("tasks", exported))
+	}
+}
+
+// taskRecord возвращает задачу строкой CSV в порядке exportColumns.
+func taskRecord(task models.Task) []string {
+	return []string{
+		strconv.FormatInt(task.ID, 10),
+		task.Title,
+		task.Description,
+		formatCSVTime(&task.DueDate),
+		string(task.Status),
+		string(task.Priority.OrNone()),
+		strings.Join(sortedTagNames(task.Tags), ","),
+		formatCSVID(task.ProjectID),
+		formatCSVID(task.ParentID),
+		task.RRule,
+		task.Timezone,
+		formatCSVTime(&task.CreatedAt),
+		formatCSVTime(&task.UpdatedAt),
+		formatCSVTime(task.CompletedAt),
+		formatCSVTime(task.CancelledAt),
+	}
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatCSVID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// ImportTasks godoc
+// @Summary Загрузить задачи из CSV
+// @Description Создать задачи из CSV. Первая строка — заголовок; столбцы с названиями полей задачи (id, title, description, due_date, status, priority, tags, project_id, parent_id, rrule, timezone) узнаются сами, прочие сопоставляются параметром map вида "Столбец:поле". Каждая строка проверяется по тем же правилам, что в POST /newtask, и создаётся отдельно: ошибка в одной строке не мешает остальным. Пустые строки и строки с id существующей задачи пропускаются, поэтому выгрузку GET /tasks/export можно загрузить повторно. С dry_run=true строки только проверяются. В отчёте — итог по каждой строке с причиной пропуска или ошибки.
+// @Tags tasks
+// @Accept text/csv
+// @Produce json
+// @Param request body string true "Задачи в CSV"
+// @Param map query []string false "Сопоставление столбца полю задачи: Название:title" collectionFormat(multi)
+// @Param delimiter query string false "Разделитель полей: один символ или tab" default(,)
+// @Param dry_run query bool false "Только проверить строки, не создавая задачи" default(false)
+// @Success 200 {object} handlers.Response{data=models.TaskImportReport}
+// @Failure 400 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/import [post]
+func ImportTasks(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ImportTasks"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		dryRun := false
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				log.Error("invalid dry_run parameter", sl.Err(err))
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid dry_run parameter"))
+				return
+			}
+		}
+
+		delimiter, err := parseDelimiter(r.URL.Query().Get("delimiter"))
+		if err != nil {
+			log.Error("invalid delimiter parameter", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		records, lines, err := readCSV(http.MaxBytesReader(w, r.Body, importMaxBody), delimiter)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				log.Error("request body too large", sl.Err(err))
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				render.JSON(w, r, resp.Error("request body too large"))
+				return
+			}
+			log.Error("invalid CSV", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		columns, ignored, err := importColumns(records[0], r.URL.Query()["map"])
+		if err != nil {
+			log.Error("invalid CSV header", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		report := models.TaskImportReport{
+			DryRun:         dryRun,
+			IgnoredColumns: ignored,
+			Rows:           make([]models.TaskImportRow, 0, len(records)-1),
+		}
+		for i, record := range records[1:] {
+			row, err := importRow(r.Context(), log, taskService, columns, record, dryRun)
+			if err != nil {
+				log.Error("import interrupted",
+					slog.Int("created", report.Created), slog.Int("line", lines[i+1]), sl.Err(err))
+				writeError(w, r, log, err, "failed to import tasks")
+				return
+			}
+			row.Line = lines[i+1]
+
+			switch row.Status {
+			case models.ImportCreated:
+				report.Created++
+			case models.ImportSkipped:
+				report.Skipped++
+			case models.ImportFailed:
+				report.Failed++
+			}
+			report.Rows = append(report.Rows, row)
+		}
+
+		log.Info("tasks imported",
+			slog.Bool("dry_run", dryRun),
+			slog.Int("created", report.Created),
+			slog.Int("skipped", report.Skipped),
+			slog.Int("failed", report.Failed),
+		)
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   report,
+		})
+	}
+}
+
+// parseDelimiter разбирает параметр delimiter: один символ или tab.
+// Текст ошибки предназначен для клиента.
+func parseDelimiter(value string) (rune, error) {
+	switch value {
+	case "":
+		return ',', nil
+	case "tab":
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || r == utf8.RuneError || strings.ContainsRune("\"\r\n", r) {
+		return 0, errors.New("invalid delimiter parameter, use one character or tab")
+	}
+
+	return r, nil
+}
+
+// readCSV читает CSV целиком, чтобы ошибка разбора обнаружилась до
+// создания первой задачи. Возвращает строки и номера их первых строк в
+// файле. Текст ошибки предназначен для клиента.
+func readCSV(body io.Reader, delimiter rune) ([][]string, []int, error) {
+	reader := csv.NewReader(body)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	var (
+		records [][]string
+		lines   []int
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+
+	if len(records) == 0 {
+		return nil, nil, errors.New("CSV header is required")
+	}
+	// Excel сохраняет UTF-8 с BOM.
+	records[0][0] = strings.TrimPrefix(records[0][0], "\uFEFF")
+
+	return records, lines, nil
+}
+
+// importColumns сопоставляет столбцы заголовка полям задачи: сначала по
+// параметрам map вида "Столбец:поле", затем по названию поля. Возвращает
+// поле для каждого столбца (пустое — столбец не загружается) и названия
+// пропущенных столбцов. Текст ошибки предназначен для клиента.
+func importColumns(header []string, mapping []string) ([]string, []string, error) {
+	columns := make([]string, len(header))
+
+	for _, value := range mapping {
+		i := strings.LastIndex(value, ":")
+		if i <= 0 {
+			return nil, nil, errors.New("invalid map parameter, use column:field")
+		}
+		column, field := strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+1:])
+		if !slices.Contains(importFields, field) {
+			return nil, nil, fmt.Errorf("invalid map parameter, unknown field %s", field)
+		}
+
+		found := false
+		for j, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				columns[j] = field
+				found = true
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("invalid map parameter, column %s not found", column)
+		}
+	}
+
+	for j, name := range header {
+		if columns[j] != "" {
+			continue
+		}
+		if field := strings.ToLower(strings.TrimSpace(name)); slices.Contains(importFields, field) {
+			columns[j] = field
+		}
+	}
+
+	ignored := []string{}
+	for j, field := range columns {
+		if field == "" {
+			ignored = append(ignored, header[j])
+			continue
+		}
+		if slices.Index(columns, field) != j {
+			return nil, nil, fmt.Errorf("field %s is mapped to more than one column", field)
+		}
+	}
+
+	for _, field := range []string{"title", "due_date"} {
+		if !slices.Contains(columns, field) {
+			return nil, nil, fmt.Errorf("no column for required field %s", field)
+		}
+	}
+
+	return columns, ignored, nil
+}
+
+// importRow проверяет строку и создаёт по ней задачу. Ошибки строки
+// попадают в отчёт; ошибка возвращается, только если импорт нужно
+// прервать: истёк таймаут запроса или клиент отключился.
+func importRow(ctx context.Context, log *slog.Logger, taskService TaskService, columns, record []string, dryRun bool) (models.TaskImportRow, error) {
+	failed := func(reason string) (models.TaskImportRow, error) {
+		return models.TaskImportRow{Status: models.ImportFailed, Reason: reason}, nil
+	}
+
+	if len(record) != len(columns) {
+		return failed(fmt.Sprintf("row has %d fields, header has %d", len(record), len(columns)))
+	}
+
+	values := make(url.Values, len(columns))
+	empty := true
+	for j, field := range columns {
+		if field == "" {
+			continue
+		}
+		value := strings.TrimSpace(record[j])
+		values.Set(field, value)
+		if value != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return models.TaskImportRow{Status: models.ImportSkipped, Reason: "empty row"}, nil
+	}
+
+	id, task, err := parseImportTask(values)
+	if err != nil {
+		return failed(err.Error())
+	}
+
+	if err := validateTask(&task); err != nil {
+		return failed(err.Error())
+	}
+
+	if id != 0 {
+		_, err := taskService.GetByID(ctx, uint(id))
+		switch {
+		case err == nil:
+			return models.TaskImportRow{Status: models.ImportSkipped, TaskID: id, Reason: "task already exists"}, nil
+		case errors.Is(err, storage.ErrTaskNotFound):
+		case ctx.Err() != nil:
+			return models.TaskImportRow{}, err
+		default:
+			log.Error("failed to check task", slog.Int64("id", id), sl.Err(err))
+			return failed("failed to check task")
+		}
+	}
+
+	if dryRun {
+		return models.TaskImportRow{Status: models.ImportCreated}, nil
+	}
+
+	created, err := taskService.CreateTask(ctx, task)
+	if err != nil {
+		if _, text := taskErrorResponse(err); text != "" {
+			return failed(text)
+		}
+		if ctx.Err() != nil {
+			return models.TaskImportRow{}, err
+		}
+		log.Error("failed to create task", sl.Err(err))
+		return failed("failed to create task")
+	}
+
+	return models.TaskImportRow{Status: models.ImportCreated, TaskID: created.ID}, nil
+}
+
+// parseImportTask собирает задачу из полей строки. id не переносится в
+// задачу: им отмечены строки, уже загруженные раньше. Текст ошибки
+// предназначен для клиента.
+func parseImportTask(values url.Values) (int64, models.Task, error) {
+	task := models.Task{
+		Title:       values.Get("title"),
+		Description: values.Get("description"),
+		Status:      models.TaskStatus(strings.ToLower(values.Get("status"))),
+		Priority:    models.Priority(strings.ToLower(values.Get("priority"))),
+		RRule:       values.Get("rrule"),
+		Timezone:    values.Get("timezone"),
+	}
+
+	var id int64
+	if value := values.Get("id"); value != "" {
+		var err error
+		if id, err = strconv.ParseInt(value, 10, 64); err != nil || id < 1 {
+			return 0, task, errors.New("invalid id")
+		}
+	}
+
+	for _, field := range []struct {
+		name string
+		dst  **int64
+	}{
+		{"project_id", &task.ProjectID},
+		{"parent_id", &task.ParentID},
+	} {
+		value := values.Get(field.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, task, fmt.Errorf("invalid %s", field.name)
+		}
+		*field.dst = &parsed
+	}
+
+	if value := values.Get("due_date"); value != "" {
+		due, err := parseImportDate(value, task.Timezone)
+		if err != nil {
+			return 0, task, err
+		}
+		task.DueDate = due
+	}
+
+	for _, name := range strings.Split(values.Get("tags"), ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(models.TagNames(task.Tags), name) {
+			task.Tags = append(task.Tags, models.Tag{Name: name})
+		}
+	}
+
+	return id, task, nil
+}
+
+// parseImportDate разбирает срок задачи в RFC 3339 или в одном из
+// importDateLayouts.
+func parseImportDate(value, timezone string) (time.Time, error) {
+	if due, err := time.Parse(time.RFC3339, value); err == nil {
+		return due, nil
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			location = loc
+		}
+	}
+	for _, layout := range importDateLayouts {
+		if due, err := time.ParseInLocation(layout, value, location); err == nil {
+			return due, nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid due_date format, use RFC 3339 or YYYY-MM-DD HH:MM")
+}
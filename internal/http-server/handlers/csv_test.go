@@ -0,0 +1,326 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestExportTasksHandler(t *testing.T) {
+	created := time.Date(2025, 4, 17, 10, 30, 0, 0, time.UTC)
+	completed := time.Date(2025, 4, 19, 8, 0, 0, 0, time.UTC)
+	parentID := int64(1)
+	tasks := []models.Task{
+		{
+			ID:          2,
+			Title:       "Купить молоко, кефир",
+			Description: "2 литра\nв магазине у дома",
+			DueDate:     time.Date(2025, 4, 20, 15, 0, 0, 0, time.UTC),
+			Status:      models.StatusDone,
+			Priority:    models.PriorityHigh,
+			CreatedAt:   created,
+			UpdatedAt:   completed,
+			CompletedAt: &completed,
+			Tags:        []models.Tag{{Name: "магазин"}, {Name: "дом"}},
+			ParentID:    &parentID,
+		},
+	}
+
+	cases := []struct {
+		name         string
+		query        string
+		listErr      error
+		expectFilter func(filter models.TaskFilter) bool
+		expectCode   int
+		expectBody   string
+		respError    string
+	}{
+		{
+			name:       "Export",
+			query:      "?format=csv",
+			expectCode: http.StatusOK,
+			expectBody: "id,title,description,due_date,status,priority,tags,project_id,parent_id,rrule,timezone,created_at,updated_at,completed_at,cancelled_at\n" +
+				"2,\"Купить молоко, кефир\",\"2 литра\nв магазине у дома\",2025-04-20T15:00:00Z,done,high,\"дом,магазин\",,1,,," +
+				"2025-04-17T10:30:00Z,2025-04-19T08:00:00Z,2025-04-19T08:00:00Z,\n",
+		},
+		{
+			name:       "Filters",
+			query:      "?completed=true&tag=дом&page=3&limit=1",
+			expectCode: http.StatusOK,
+			expectFilter: func(filter models.TaskFilter) bool {
+				return filter.Completed != nil && *filter.Completed &&
+					len(filter.Tags) == 1 && filter.Tags[0] == "дом" &&
+					filter.Page == 1 && filter.Limit == 500
+			},
+		},
+		{
+			name:       "Delimiter",
+			query:      "?delimiter=%3B",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Invalid format",
+			query:      "?format=xlsx",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid format parameter, use csv",
+		},
+		{
+			name:       "Invalid delimiter",
+			query:      "?delimiter=ab",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid delimiter parameter, use one character or tab",
+		},
+		{
+			name:       "Storage error",
+			listErr:    errors.New("db is down"),
+			expectCode: http.StatusInternalServerError,
+			respError:  "failed to list tasks",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskServiceMock := mocks.NewTaskService(t)
+
+			if tc.expectCode == http.StatusOK || tc.listErr != nil {
+				var match any = mock.Anything
+				if tc.expectFilter != nil {
+					match = mock.MatchedBy(tc.expectFilter)
+				}
+				if tc.listErr != nil {
+					taskServiceMock.On("List", mock.Anything, match).Return(nil, tc.listErr).Once()
+				} else {
+					taskServiceMock.On("List", mock.Anything, match).
+						Return(&models.TasksList{Data: tasks, Total: int64(len(tasks))}, nil).Once()
+				}
+			}
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.ExportTasks(log, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks/export"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			if tc.respError != "" {
+				require.Contains(t, rr.Body.String(), tc.respError)
+				return
+			}
+
+			require.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+			require.Contains(t, rr.Header().Get("Content-Disposition"), "tasks.csv")
+			if tc.expectBody != "" {
+				require.Equal(t, tc.expectBody, rr.Body.String())
+			}
+			if tc.name == "Delimiter" {
+				require.True(t, strings.HasPrefix(rr.Body.String(), "id;title;description;"))
+			}
+		})
+	}
+}
+
+func TestImportTasksHandler(t *testing.T) {
+	existing := &models.Task{ID: 3, Title: "Уже есть"}
+
+	cases := []struct {
+		name          string
+		query         string
+		body          string
+		expectCreated []string
+		expectCode    int
+		expectReport  models.TaskImportReport
+		respError     string
+	}{
+		{
+			name: "Import",
+			body: "\uFEFFtitle,due_date,tags,priority,note\n" +
+				"Купить молоко,2025-04-20 18:00,\"дом, магазин\",high,x\n" +
+				",2025-04-20,,,\n" +
+				",,,,\n" +
+				"Позвонить,20.04.2025,,,\n" +
+				"Отчёт,2025-04-21T09:00:00Z,,someday,\n",
+			expectCreated: []string{"Купить молоко"},
+			expectCode:    http.StatusOK,
+			expectReport: models.TaskImportReport{
+				Created:        1,
+				Skipped:        1,
+				Failed:         3,
+				IgnoredColumns: []string{"note"},
+				Rows: []models.TaskImportRow{
+					{Line: 2, Status: models.ImportCreated, TaskID: 10},
+					{Line: 3, Status: models.ImportFailed, Reason: "field title is a required field"},
+					{Line: 4, Status: models.ImportSkipped, Reason: "empty row"},
+					{Line: 5, Status: models.ImportFailed, Reason: "invalid due_date format, use RFC 3339 or YYYY-MM-DD HH:MM"},
+					{Line: 6, Status: models.ImportFailed, Reason: "field priority is not valid"},
+				},
+			},
+		},
+		{
+			name:          "Mapping",
+			query:         "?delimiter=%3B&map=Название:title&map=Срок:due_date",
+			body:          "Название;Срок;Комментарий\nКупить молоко;2025-04-20;в магазине у дома\n",
+			expectCreated: []string{"Купить молоко"},
+			expectCode:    http.StatusOK,
+			expectReport: models.TaskImportReport{
+				Created:        1,
+				IgnoredColumns: []string{"Комментарий"},
+				Rows:           []models.TaskImportRow{{Line: 2, Status: models.ImportCreated, TaskID: 10}},
+			},
+		},
+		{
+			name:       "Dry run",
+			query:      "?dry_run=true",
+			body:       "title,due_date\nКупить молоко,2025-04-20\nПозвонить,\n",
+			expectCode: http.StatusOK,
+			expectReport: models.TaskImportReport{
+				DryRun:         true,
+				Created:        1,
+				Failed:         1,
+				IgnoredColumns: []string{},
+				Rows: []models.TaskImportRow{
+					{Line: 2, Status: models.ImportCreated},
+					{Line: 3, Status: models.ImportFailed, Reason: "field due_date is a required field"},
+				},
+			},
+		},
+		{
+			name:          "Existing task",
+			body:          "id,title,due_date\n3,Уже есть,2025-04-20\n4,Новая,2025-04-20\n",
+			expectCreated: []string{"Новая"},
+			expectCode:    http.StatusOK,
+			expectReport: models.TaskImportReport{
+				Created:        1,
+				Skipped:        1,
+				IgnoredColumns: []string{},
+				Rows: []models.TaskImportRow{
+					{Line: 2, Status: models.ImportSkipped, TaskID: 3, Reason: "task already exists"},
+					{Line: 3, Status: models.ImportCreated, TaskID: 10},
+				},
+			},
+		},
+		{
+			name:       "Missing column",
+			body:       "title\nКупить молоко\n",
+			expectCode: http.StatusBadRequest,
+			respError:  "no column for required field due_date",
+		},
+		{
+			name:       "Unknown field",
+			query:      "?map=Название:name",
+			body:       "Название,due_date\n",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid map parameter, unknown field name",
+		},
+		{
+			name:       "Duplicate field",
+			query:      "?map=Название:title",
+			body:       "Название,title,due_date\n",
+			expectCode: http.StatusBadRequest,
+			respError:  "field title is mapped to more than one column",
+		},
+		{
+			name:       "Invalid CSV",
+			body:       "title,due_date\n\"Купить,2025-04-20\n",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid CSV",
+		},
+		{
+			name:       "Empty body",
+			expectCode: http.StatusBadRequest,
+			respError:  "CSV header is required",
+		},
+		{
+			name:       "Invalid dry_run",
+			query:      "?dry_run=maybe",
+			body:       "title,due_date\n",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid dry_run parameter",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskServiceMock := mocks.NewTaskService(t)
+
+			if tc.name == "Existing task" {
+				taskServiceMock.On("GetByID", mock.Anything, uint(3)).Return(existing, nil).Once()
+				taskServiceMock.On("GetByID", mock.Anything, uint(4)).Return(nil, storage.ErrTaskNotFound).Once()
+			}
+			for _, title := range tc.expectCreated {
+				taskServiceMock.On("CreateTask", mock.Anything, mock.MatchedBy(func(task models.Task) bool {
+					return task.Title == title
+				})).Return(func(_ context.Context, task models.Task) (*models.Task, error) {
+					task.ID = 10
+					return &task, nil
+				}).Once()
+			}
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.ImportTasks(log, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/import"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "text/csv")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			if tc.respError != "" {
+				require.Contains(t, rr.Body.String(), tc.respError)
+				return
+			}
+
+			var body struct {
+				Status string                  `json:"status"`
+				Data   models.TaskImportReport `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, "OK", body.Status)
+			require.Equal(t, tc.expectReport, body.Data)
+		})
+	}
+}
+
+func TestImportTasksDueDate(t *testing.T) {
+	taskServiceMock := mocks.NewTaskService(t)
+
+	var got models.Task
+	taskServiceMock.On("CreateTask", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, task models.Task) (*models.Task, error) {
+			got = task
+			task.ID = 1
+			return &task, nil
+		}).Once()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.ImportTasks(log, taskServiceMock)
+
+	body := "title,due_date,timezone,tags,project_id\nКупить молоко,2025-04-20 18:00,Europe/Moscow,\"дом,дом, магазин\",5\n"
+	req := httptest.NewRequest(http.MethodPost, "/tasks/import", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, got.DueDate.Equal(time.Date(2025, 4, 20, 15, 0, 0, 0, time.UTC)))
+	require.Equal(t, "Europe/Moscow", got.Timezone)
+	require.Equal(t, []string{"дом", "магазин"}, models.TagNames(got.Tags))
+	require.NotNil(t, got.ProjectID)
+	require.Equal(t, int64(5), *got.ProjectID)
+}
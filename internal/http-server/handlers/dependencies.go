@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+)
+
+// Blockers godoc
+// @Summary Получить блокирующие задачи
+// @Description Получить задачи, которые нужно выполнить до начала задачи, упорядоченные по сроку выполнения
+// @Tags dependencies
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} handlers.Response{data=[]models.Task}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/blockers [get]
+func Blockers(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.Blockers"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		tasks, err := taskService.Blockers(r.Context(), uint(id))
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to list blockers")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tasks,
+		})
+	}
+}
+
+// AddBlocker godoc
+// @Summary Добавить блокирующую задачу
+// @Description Задачу нельзя начать, пока не выполнена блокирующая. Зависимость, замыкающая цикл, отклоняется с 409. Повторное добавление ничего не меняет.
+// @Tags dependencies
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param blocker_id path int true "ID блокирующей задачи"
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
+// @Success 200 {object} handlers.Response{data=models.Task}
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/blockers/{blocker_id} [put]
+func AddBlocker(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return changeBlocker(log, taskService, "handlers.AddBlocker", taskService.AddBlocker)
+}
+
+// RemoveBlocker godoc
+// @Summary Убрать блокирующую задачу
+// @Description Убрать зависимость задачи от блокирующей задачи
+// @Tags dependencies
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param blocker_id path int true "ID блокирующей задачи"
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
+// @Success 200 {object} handlers.Response{data=models.Task}
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/blockers/{blocker_id} [delete]
+func RemoveBlocker(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return changeBlocker(log, taskService, "handlers.RemoveBlocker", taskService.RemoveBlocker)
+}
+
+// changeBlocker — общая часть AddBlocker и RemoveBlocker: разбирает
+// идентификаторы и If-Match, выполняет change и отдаёт изменённую задачу.
+func changeBlocker(
+	log *slog.Logger,
+	taskService TaskService,
+	op string,
+	change func(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		blockerID, err := strconv.ParseInt(chi.URLParam(r, "blocker_id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse blocker id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid blocker id"))
+			return
+		}
+
+		version, err := ifMatchVersion(r.Header.Get("If-Match"), func() (int64, error) {
+			task, err := taskService.GetByID(r.Context(), uint(id))
+			if err != nil {
+				return 0, err
+			}
+			return task.Version, nil
+		})
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to change blockers")
+			return
+		}
+
+		task, err := change(r.Context(), uint(id), uint(blockerID), version)
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to change blockers")
+			return
+		}
+
+		log.Info("blockers changed", slog.Int64("id", id), slog.Int64("blocker_id", blockerID))
+
+		w.Header().Set("ETag", etag(task.Version))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   task,
+		})
+	}
+}
+
+// NextTasks godoc
+// @Summary Что делать дальше
+// @Description Получить невыполненные задачи в порядке, в котором их можно делать: каждая задача идёт после своих блокирующих, а среди доступных первой идёт задача с ближайшим сроком
+// @Tags dependencies
+// @Produce json
+// @Param limit query int false "Количество задач" default(10)
+// @Success 200 {object} handlers.Response{data=[]models.Task}
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/next [get]
+func NextTasks(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.NextTasks"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		limit := 10
+		if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value >= 1 {
+			limit = value
+		}
+
+		tasks, err := taskService.NextTasks(r.Context(), limit)
+		if err != nil {
+			writeError(w, r, log, err, "failed to plan tasks")
+			return
+		}
+
+		log.Info("next tasks retrieved", slog.Int("count", len(tasks)))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tasks,
+		})
+	}
+}
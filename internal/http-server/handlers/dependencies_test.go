@@ -0,0 +1,213 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestAddBlockerHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		blockerID  string
+		ifMatch    string
+		version    int64
+		mockResp   *models.Task
+		mockError  error
+		respError  string
+		expectCode int
+	}{
+		{
+			name:       "Success",
+			blockerID:  "2",
+			ifMatch:    `"3"`,
+			version:    3,
+			mockResp:   &models.Task{ID: 1, Title: "deploy", Version: 4, Blocked: true},
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Invalid blocker id",
+			blockerID:  "abc",
+			respError:  "invalid blocker id",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Blocker not found",
+			blockerID:  "2",
+			mockError:  storage.ErrBlockerNotFound,
+			respError:  "blocker task not found",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "Cycle",
+			blockerID:  "2",
+			mockError:  storage.ErrDependencyCycle,
+			respError:  "dependency cycle, the blocker already waits for this task",
+			expectCode: http.StatusConflict,
+		},
+		{
+			name:       "Version mismatch",
+			blockerID:  "2",
+			ifMatch:    `"2"`,
+			version:    2,
+			mockError:  storage.ErrVersionMismatch,
+			respError:  "task version mismatch",
+			expectCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+			if tc.mockResp != nil || tc.mockError != nil {
+				taskServiceMock.On("AddBlocker", mock.Anything, uint(1), uint(2), tc.version).
+					Return(tc.mockResp, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.AddBlocker(logger, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodPut, "/tasks/1/blockers/"+tc.blockerID, nil)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, withBlocker(req, "1", tc.blockerID))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp struct {
+				Error string      `json:"error"`
+				Data  models.Task `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusOK {
+				require.Equal(t, `"4"`, rr.Header().Get("ETag"))
+				require.True(t, resp.Data.Blocked)
+			}
+		})
+	}
+}
+
+func TestRemoveBlockerHandler(t *testing.T) {
+	taskServiceMock := mocks.NewTaskService(t)
+	taskServiceMock.On("RemoveBlocker", mock.Anything, uint(1), uint(2), int64(0)).
+		Return(&models.Task{ID: 1, Version: 5}, nil).
+		Once()
+	taskServiceMock.On("RemoveBlocker", mock.Anything, uint(1), uint(3), int64(0)).
+		Return(nil, storage.ErrDependencyNotFound).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.RemoveBlocker(logger, taskServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withBlocker(httptest.NewRequest(http.MethodDelete, "/tasks/1/blockers/2", nil), "1", "2"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, `"5"`, rr.Header().Get("ETag"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, withBlocker(httptest.NewRequest(http.MethodDelete, "/tasks/1/blockers/3", nil), "1", "3"))
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.JSONEq(t, `{"status":"Error","error":"dependency not found"}`, rr.Body.String())
+}
+
+func TestBlockersHandler(t *testing.T) {
+	taskServiceMock := mocks.NewTaskService(t)
+	taskServiceMock.On("Blockers", mock.Anything, uint(1)).
+		Return([]models.Task{{ID: 2, Title: "build"}}, nil).
+		Once()
+	taskServiceMock.On("Blockers", mock.Anything, uint(5)).
+		Return(nil, storage.ErrTaskNotFound).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.Blockers(logger, taskServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/1/blockers", nil), "1"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Data []models.Task `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	require.Equal(t, "build", resp.Data[0].Title)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/5/blockers", nil), "5"))
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestNextTasksHandler(t *testing.T) {
+	cases := []struct {
+		name        string
+		queryParams string
+		limit       int
+	}{
+		{name: "Default limit", queryParams: "", limit: 10},
+		{name: "Custom limit", queryParams: "limit=3", limit: 3},
+		{name: "Invalid limit", queryParams: "limit=0", limit: 10},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+			taskServiceMock.On("NextTasks", mock.Anything, tc.limit).
+				Return([]models.Task{{ID: 2, Title: "design"}, {ID: 1, Title: "deploy", Blocked: true}}, nil).
+				Once()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.NextTasks(logger, taskServiceMock)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tasks/next?"+tc.queryParams, nil))
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp struct {
+				Data []models.Task `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Len(t, resp.Data, 2)
+			require.Equal(t, "design", resp.Data[0].Title)
+		})
+	}
+}
+
+func withBlocker(req *http.Request, id, blockerID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	rctx.URLParams.Add("blocker_id", blockerID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"todo/internal/storage"
+)
+
+// etag формирует сильный ETag задачи из её версии.
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ifMatchVersion возвращает версию, которую клиент ожидает увидеть согласно
+// заголовку If-Match. Ноль означает, что проверка версии не нужна (заголовка
+// нет или указан "*"). Если заголовок перечисляет несколько ETag, текущая
+// версия запрашивается через current. Несовпадение возвращается как
+// storage.ErrVersionMismatch.
+func ifMatchVersion(header string, current func() (int64, error)) (int64, error) {
+	tags := parseETags(header)
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	if len(tags) == 1 {
+		if tags[0] == "*" {
+			return 0, nil
+		}
+		version, ok := parseVersion(tags[0])
+		if !ok {
+			return 0, storage.ErrVersionMismatch
+		}
+		return version, nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tag := range tags {
+		if tag == "*" || tag == etag(version) {
+			return version, nil
+		}
+	}
+
+	return 0, storage.ErrVersionMismatch
+}
+
+// noneMatch сообщает, совпадает ли ETag задачи с одним из значений заголовка
+// If-None-Match. Используется слабое сравнение.
+func noneMatch(header string, version int64) bool {
+	current := etag(version)
+
+	for _, tag := range parseETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseETags(header string) []string {
+	var tags []string
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// parseVersion извлекает версию из сильного ETag. Слабые ETag при If-Match
+// никогда не совпадают.
+func parseVersion(tag string) (int64, bool) {
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil || strings.HasPrefix(tag, "W/") {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
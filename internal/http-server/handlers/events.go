@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// replayBatch — сколько событий журнала читается за раз при продолжении
+// потока.
+const replayBatch = 500
+
+//go:generate mockery --name=TaskEventService --output=mocks --outpkg=mocks
+type TaskEventService interface {
+	ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error)
+	Subscribe(filter models.TaskEventFilter) (<-chan models.TaskEvent, func())
+}
+
+// TaskEvents godoc
+// @Summary Поток изменений задач
+// @Description Server-Sent Events: события task.created, task.updated и task.deleted по мере изменения задач. Поле id события — его номер в журнале; после переподключения поток продолжается с события, следующего за заголовком Last-Event-ID (или параметром last_event_id). Если журнал уже вытеснил нужные события, сначала приходит событие reset: клиенту нужно заново загрузить задачи. Фильтры применяются к задаче в том состоянии, которое записано в событии.
+// @Tags tasks
+// @Produce text/event-stream
+// @Param project_id query int false "ID проекта"
+// @Param tag query []string false "Название метки; можно указать несколько, событие проходит, если у задачи есть хотя бы одна" collectionFormat(multi)
+// @Param status query []string false "Статус задачи; можно указать несколько" collectionFormat(multi) Enums(todo, in_progress, blocked, done, cancelled)
+// @Param last_event_id query int false "ID последнего полученного события, если нельзя передать заголовок Last-Event-ID"
+// @Param Last-Event-ID header int false "ID последнего полученного события"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /tasks/events [get]
+func TaskEvents(log *slog.Logger, eventService TaskEventService, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.TaskEvents"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		filter, err := parseTaskEventFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		lastID, err := lastEventID(r)
+		if err != nil {
+			log.Error("invalid last event id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid last event id"))
+			return
+		}
+
+		// Подписка оформляется до чтения журнала, чтобы не потерять
+		// события, записанные между чтением и подпиской; повторы
+		// отбрасываются по ID.
+		events, unsubscribe := eventService.Subscribe(filter)
+		defer unsubscribe()
+
+		var backlog []models.TaskEvent
+		replayed := lastID
+		expired := false
+		if lastID > 0 {
+			backlog, replayed, err = replayTaskEvents(r.Context(), eventService, lastID, filter)
+			switch {
+			case errors.Is(err, storage.ErrEventsExpired):
+				expired = true
+			case err != nil:
+				writeError(w, r, log, err, "failed to read task events")
+				return
+			}
+		}
+
+		// Поток живёт дольше WriteTimeout сервера.
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Warn("failed to clear write deadline", sl.Err(err))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if expired {
+			log.Info("task events expired, client must reload", slog.Int64("last_event_id", lastID))
+			if _, err := fmt.Fprint(w, "event: reset\ndata: {}\n\n"); err != nil {
+				return
+			}
+		}
+
+		for _, event := range backlog {
+			if err := writeTaskEvent(w, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			log.Error("streaming is not supported", sl.Err(err))
+			return
+		}
+
+		log.Info("task events stream opened", slog.Int64("last_event_id", lastID), slog.Int("replayed", len(backlog)))
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					// Поток закрыт при остановке сервера или потому, что
+					// клиент отстал; он переподключится с Last-Event-ID.
+					log.Info("task events stream closed")
+					return
+				}
+				if event.ID <= replayed {
+					continue
+				}
+				if err := writeTaskEvent(w, event); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// replayTaskEvents читает события журнала после lastID, проходящие filter,
+// и возвращает их вместе с ID последнего прочитанного события.
+func replayTaskEvents(ctx context.Context, eventService TaskEventService, lastID int64, filter models.TaskEventFilter) ([]models.TaskEvent, int64, error) {
+	var backlog []models.TaskEvent
+	for {
+		events, err := eventService.ListTaskEvents(ctx, lastID, replayBatch)
+		if err != nil {
+			return nil, lastID, err
+		}
+
+		for _, event := range events {
+			if filter.Match(event) {
+				backlog = append(backlog, event)
+			}
+			lastID = event.ID
+		}
+		if len(events) < replayBatch {
+			return backlog, lastID, nil
+		}
+	}
+}
+
+// writeTaskEvent пишет событие в формате text/event-stream.
+func writeTaskEvent(w http.ResponseWriter, event models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// lastEventID возвращает ID, с которого клиент продолжает поток: из
+// заголовка Last-Event-ID, который браузер отправляет при
+// переподключении, или из параметра last_event_id.
+func lastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("parse last event id %q: invalid value", value)
+	}
+
+	return id, nil
+}
+
+// parseTaskEventFilter разбирает фильтры потока изменений задач. Текст
+// ошибки предназначен для клиента.
+func parseTaskEventFilter(query url.Values) (models.TaskEventFilter, error) {
+	var filter models.TaskEventFilter
+
+	if projectStr := query.Get("project_id"); projectStr != "" {
+		projectID, err := strconv.ParseInt(projectStr, 10, 64)
+		if err != nil || projectID < 1 {
+			return filter, errors.New("invalid project_id parameter")
+		}
+		filter.ProjectID = &projectID
+	}
+
+	for _, value := range query["status"] {
+		status := models.TaskStatus(value)
+		if !status.Valid() {
+			return filter, errors.New("invalid status parameter")
+		}
+		if !slices.Contains(filter.Statuses, status) {
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	filter.Tags = tagFilter(query["tag"])
+
+	return filter, nil
+}
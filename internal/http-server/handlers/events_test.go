@@ -0,0 +1,182 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestTaskEventsHandler(t *testing.T) {
+	inbox := int64(1)
+	other := int64(2)
+	event := func(id int64, eventType models.WebhookEventType, projectID *int64) models.TaskEvent {
+		return models.TaskEvent{
+			ID: id,
+			WebhookEvent: models.WebhookEvent{
+				Type:       eventType,
+				OccurredAt: time.Date(2025, 4, 17, 10, 0, 0, 0, time.UTC),
+				Task:       models.Task{ID: 7, Title: "dashboard", ProjectID: projectID},
+			},
+		}
+	}
+
+	cases := []struct {
+		name        string
+		query       string
+		lastEventID string
+		expectAfter int64
+		backlog     []models.TaskEvent
+		listError   error
+		live        []models.TaskEvent
+		expectCode  int
+		expectIDs   []int64
+		expectReset bool
+		respError   string
+	}{
+		{
+			name:       "Live",
+			live:       []models.TaskEvent{event(5, models.EventTaskCreated, nil), event(6, models.EventTaskDeleted, nil)},
+			expectCode: http.StatusOK,
+			expectIDs:  []int64{5, 6},
+		},
+		{
+			name:        "Resume",
+			query:       "?project_id=1",
+			lastEventID: "3",
+			expectAfter: 3,
+			backlog:     []models.TaskEvent{event(4, models.EventTaskUpdated, &inbox), event(5, models.EventTaskUpdated, &other)},
+			// Событие 4 уже отправлено из журнала.
+			live:       []models.TaskEvent{event(4, models.EventTaskUpdated, &inbox), event(6, models.EventTaskUpdated, &inbox)},
+			expectCode: http.StatusOK,
+			expectIDs:  []int64{4, 6},
+		},
+		{
+			name:        "Resume from query",
+			query:       "?last_event_id=3",
+			expectAfter: 3,
+			backlog:     []models.TaskEvent{event(4, models.EventTaskUpdated, nil)},
+			expectCode:  http.StatusOK,
+			expectIDs:   []int64{4},
+		},
+		{
+			name:        "Expired",
+			lastEventID: "1",
+			expectAfter: 1,
+			listError:   storage.ErrEventsExpired,
+			live:        []models.TaskEvent{event(9, models.EventTaskUpdated, nil)},
+			expectCode:  http.StatusOK,
+			expectIDs:   []int64{9},
+			expectReset: true,
+		},
+		{
+			name:        "Storage error",
+			lastEventID: "1",
+			expectAfter: 1,
+			listError:   errors.New("database error"),
+			expectCode:  http.StatusInternalServerError,
+			respError:   "failed to read task events",
+		},
+		{
+			name:       "Invalid status",
+			query:      "?status=archived",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid status parameter",
+		},
+		{
+			name:       "Invalid project",
+			query:      "?project_id=inbox",
+			expectCode: http.StatusBadRequest,
+			respError:  "invalid project_id parameter",
+		},
+		{
+			name:        "Invalid last event id",
+			lastEventID: "abc",
+			expectCode:  http.StatusBadRequest,
+			respError:   "invalid last event id",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			eventServiceMock := mocks.NewTaskEventService(t)
+
+			if tc.expectCode != http.StatusBadRequest {
+				// Канал закрывается после живых событий, как при остановке
+				// сервера, и обработчик завершает поток.
+				live := make(chan models.TaskEvent, len(tc.live))
+				for _, event := range tc.live {
+					live <- event
+				}
+				close(live)
+
+				eventServiceMock.On("Subscribe", mock.Anything).
+					Return((<-chan models.TaskEvent)(live), func() {}).
+					Once()
+			}
+			if tc.expectAfter > 0 {
+				eventServiceMock.On("ListTaskEvents", mock.Anything, tc.expectAfter, 500).
+					Return(tc.backlog, tc.listError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.TaskEvents(logger, eventServiceMock, time.Minute)
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks/events"+tc.query, nil)
+			if tc.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			if tc.expectCode != http.StatusOK {
+				var resp handlers.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+				return
+			}
+
+			body := rr.Body.String()
+			require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			require.Equal(t, tc.expectReset, strings.HasPrefix(body, "event: reset\n"))
+			require.Equal(t, tc.expectIDs, eventIDs(t, body))
+			require.Contains(t, body, `data: {"id":`+strconv.FormatInt(tc.expectIDs[0], 10)+`,"event":"task.`)
+		})
+	}
+}
+
+// eventIDs возвращает поля id событий потока по порядку.
+func eventIDs(t *testing.T, body string) []int64 {
+	t.Helper()
+
+	var ids []int64
+	for _, match := range regexp.MustCompile(`(?m)^id: (\d+)$`).FindAllStringSubmatch(body, -1) {
+		id, err := strconv.ParseInt(match[1], 10, 64)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"todo/internal/lib/logger/sl"
+	"todo/internal/storage/migrations"
+)
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// Pinger проверяет доступность хранилища.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// MigrationReporter сообщает о состоянии миграций схемы. Реализуется
+// хранилищами, у которых есть схема базы данных.
+type MigrationReporter interface {
+	MigrationStatus(ctx context.Context) ([]migrations.Status, error)
+}
+
+// PoolReporter отдаёт статистику пула соединений с базой данных.
+type PoolReporter interface {
+	Stats() sql.DBStats
+}
+
+// HealthResponse представляет ответ проверки состояния сервиса
+// @Description Состояние сервиса
+type HealthResponse struct {
+	Status     string            `json:"status" example:"ok"`  // ok или unavailable
+	Checks     map[string]Check  `json:"checks,omitempty"`     // Результаты отдельных проверок
+	Pool       *PoolStats        `json:"pool,omitempty"`       // Статистика пула соединений
+	Migrations *MigrationsReport `json:"migrations,omitempty"` // Состояние миграций
+}
+
+// Check представляет результат одной проверки
+// @Description Результат проверки
+type Check struct {
+	Status    string `json:"status" example:"ok"`                                 // ok или unavailable
+	LatencyMS int64  `json:"latency_ms" example:"2"`                              // Длительность проверки в миллисекундах
+	Error     string `json:"error,omitempty" example:"context deadline exceeded"` // Причина сбоя
+}
+
+// PoolStats представляет статистику пула соединений
+// @Description Статистика пула соединений с базой данных
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections" example:"0"`
+	OpenConnections    int   `json:"open_connections" example:"2"`
+	InUse              int   `json:"in_use" example:"1"`
+	Idle               int   `json:"idle" example:"1"`
+	WaitCount          int64 `json:"wait_count" example:"0"`
+	WaitDurationMS     int64 `json:"wait_duration_ms" example:"0"`
+}
+
+// MigrationsReport представляет состояние миграций схемы
+// @Description Состояние миграций схемы
+type MigrationsReport struct {
+	CurrentVersion int64 `json:"current_version" example:"2"` // Последняя применённая версия
+	LatestVersion  int64 `json:"latest_version" example:"2"`  // Последняя известная версия
+	Pending        int   `json:"pending" example:"0"`         // Количество неприменённых миграций
+}
+
+// Healthz godoc
+// @Summary Проверка живости
+// @Description Возвращает 200, пока процесс запущен и обслуживает запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} handlers.HealthResponse
+// @Router /healthz [get]
+func Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, HealthResponse{Status: HealthStatusOK})
+	}
+}
+
+// Readyz godoc
+// @Summary Проверка готовности
+// @Description Проверяет доступность базы данных и состояние миграций. Во время остановки сервера всегда возвращает 503, чтобы балансировщик перестал направлять запросы.
+// @Tags health
+// @Produce json
+// @Success 200 {object} handlers.HealthResponse
+// @Failure 503 {object} handlers.HealthResponse
+// @Router /readyz [get]
+func Readyz(log *slog.Logger, pinger Pinger, timeout time.Duration, ready *atomic.Bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.Readyz"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		res := HealthResponse{
+			Status: HealthStatusOK,
+			Checks: make(map[string]Check),
+		}
+
+		if !ready.Load() {
+			res.Status = HealthStatusUnavailable
+			res.Checks["server"] = Check{Status: HealthStatusUnavailable, Error: "shutting down"}
+		}
+
+		start := time.Now()
+		check := Check{Status: HealthStatusOK}
+		if err := pinger.Ping(ctx); err != nil {
+			log.Error("database ping failed", sl.Err(err))
+			check.Status = HealthStatusUnavailable
+			check.Error = err.Error()
+			res.Status = HealthStatusUnavailable
+		}
+		check.LatencyMS = time.Since(start).Milliseconds()
+		res.Checks["database"] = check
+
+		if reporter, ok := pinger.(MigrationReporter); ok {
+			start := time.Now()
+			check := Check{Status: HealthStatusOK}
+
+			statuses, err := reporter.MigrationStatus(ctx)
+			if err != nil {
+				log.Error("failed to get migration status", sl.Err(err))
+				check.Status = HealthStatusUnavailable
+				check.Error = err.Error()
+				res.Status = HealthStatusUnavailable
+			} else {
+				res.Migrations = migrationsReport(statuses)
+				if res.Migrations.Pending > 0 {
+					check.Status = HealthStatusUnavailable
+					check.Error = "schema has pending migrations"
+					res.Status = HealthStatusUnavailable
+				}
+			}
+			check.LatencyMS = time.Since(start).Milliseconds()
+			res.Checks["migrations"] = check
+		}
+
+		if reporter, ok := pinger.(PoolReporter); ok {
+			stats := reporter.Stats()
+			res.Pool = &PoolStats{
+				MaxOpenConnections: stats.MaxOpenConnections,
+				OpenConnections:    stats.OpenConnections,
+				InUse:              stats.InUse,
+				Idle:               stats.Idle,
+				WaitCount:          stats.WaitCount,
+				WaitDurationMS:     stats.WaitDuration.Milliseconds(),
+			}
+		}
+
+		if res.Status != HealthStatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		render.JSON(w, r, res)
+	}
+}
+
+func migrationsReport(statuses []migrations.Status) *MigrationsReport {
+	report := &MigrationsReport{}
+
+	for _, status := range statuses {
+		report.LatestVersion = status.Version
+		if status.Applied {
+			report.CurrentVersion = status.Version
+		} else {
+			report.Pending++
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/storage/migrations"
+)
+
+type stubStorage struct {
+	pingErr    error
+	statuses   []migrations.Status
+	migrateErr error
+}
+
+func (s stubStorage) Ping(ctx context.Context) error {
+	return s.pingErr
+}
+
+func (s stubStorage) MigrationStatus(ctx context.Context) ([]migrations.Status, error) {
+	return s.statuses, s.migrateErr
+}
+
+func (s stubStorage) Stats() sql.DBStats {
+	return sql.DBStats{OpenConnections: 2, InUse: 1, Idle: 1}
+}
+
+func TestHealthzHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	handlers.Healthz().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+}
+
+func TestReadyzHandler(t *testing.T) {
+	applied := []migrations.Status{
+		{Version: 1, Name: "create_tasks", Applied: true},
+		{Version: 2, Name: "add_tasks_version", Applied: true},
+	}
+	pending := []migrations.Status{
+		{Version: 1, Name: "create_tasks", Applied: true},
+		{Version: 2, Name: "add_tasks_version"},
+	}
+
+	cases := []struct {
+		name         string
+		storage      stubStorage
+		shuttingDown bool
+		expectCode   int
+		failedCheck  string
+	}{
+		{
+			name:       "Ready",
+			storage:    stubStorage{statuses: applied},
+			expectCode: http.StatusOK,
+		},
+		{
+			name:        "Database unavailable",
+			storage:     stubStorage{pingErr: errors.New("connection refused"), statuses: applied},
+			expectCode:  http.StatusServiceUnavailable,
+			failedCheck: "database",
+		},
+		{
+			name:        "Pending migrations",
+			storage:     stubStorage{statuses: pending},
+			expectCode:  http.StatusServiceUnavailable,
+			failedCheck: "migrations",
+		},
+		{
+			name:        "Migration status error",
+			storage:     stubStorage{migrateErr: errors.New("permission denied")},
+			expectCode:  http.StatusServiceUnavailable,
+			failedCheck: "migrations",
+		},
+		{
+			name:         "Shutting down",
+			storage:      stubStorage{statuses: applied},
+			shuttingDown: true,
+			expectCode:   http.StatusServiceUnavailable,
+			failedCheck:  "server",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ready atomic.Bool
+			ready.Store(!tc.shuttingDown)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.Readyz(logger, tc.storage, time.Second, &ready)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.HealthResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Pool)
+			require.Equal(t, 2, resp.Pool.OpenConnections)
+
+			if tc.failedCheck == "" {
+				require.Equal(t, handlers.HealthStatusOK, resp.Status)
+				require.EqualValues(t, 2, resp.Migrations.CurrentVersion)
+				return
+			}
+
+			require.Equal(t, handlers.HealthStatusUnavailable, resp.Status)
+			require.Equal(t, handlers.HealthStatusUnavailable, resp.Checks[tc.failedCheck].Status)
+			require.NotEmpty(t, resp.Checks[tc.failedCheck].Error)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "todo/internal/models"
+)
+
+// CalendarObjectService is an autogenerated mock type for the CalendarObjectService type
+type CalendarObjectService struct {
+	mock.Mock
+}
+
+// CalendarObjects provides a mock function with given fields: ctx
+func (_m *CalendarObjectService) CalendarObjects(ctx context.Context) ([]models.CalendarObject, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CalendarObjects")
+	}
+
+	var r0 []models.CalendarObject
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.CalendarObject, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.CalendarObject); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CalendarObject)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveCalendarObject provides a mock function with given fields: ctx, object
+func (_m *CalendarObjectService) SaveCalendarObject(ctx context.Context, object models.CalendarObject) error {
+	ret := _m.Called(ctx, object)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCalendarObject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.CalendarObject) error); ok {
+		r0 = rf(ctx, object)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCalendarObjectService creates a new instance of CalendarObjectService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCalendarObjectService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CalendarObjectService {
+	mock := &CalendarObjectService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
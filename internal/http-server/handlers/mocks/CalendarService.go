@@ -0,0 +1,138 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "todo/internal/models"
+)
+
+// CalendarService is an autogenerated mock type for the CalendarService type
+type CalendarService struct {
+	mock.Mock
+}
+
+// CreateFeedToken provides a mock function with given fields: ctx, name, hash
+func (_m *CalendarService) CreateFeedToken(ctx context.Context, name string, hash string) (*models.FeedToken, error) {
+	ret := _m.Called(ctx, name, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateFeedToken")
+	}
+
+	var r0 *models.FeedToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.FeedToken, error)); ok {
+		return rf(ctx, name, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.FeedToken); ok {
+		r0 = rf(ctx, name, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.FeedToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, name, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteFeedToken provides a mock function with given fields: ctx, id
+func (_m *CalendarService) DeleteFeedToken(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFeedToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindFeedToken provides a mock function with given fields: ctx, hash
+func (_m *CalendarService) FindFeedToken(ctx context.Context, hash string) (*models.FeedToken, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindFeedToken")
+	}
+
+	var r0 *models.FeedToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.FeedToken, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.FeedToken); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.FeedToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFeedTokens provides a mock function with given fields: ctx
+func (_m *CalendarService) ListFeedTokens(ctx context.Context) ([]models.FeedToken, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFeedTokens")
+	}
+
+	var r0 []models.FeedToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.FeedToken, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.FeedToken); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FeedToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCalendarService creates a new instance of CalendarService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCalendarService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CalendarService {
+	mock := &CalendarService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,150 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "todo/internal/models"
+)
+
+// ProjectService is an autogenerated mock type for the ProjectService type
+type ProjectService struct {
+	mock.Mock
+}
+
+// CreateProject provides a mock function with given fields: ctx, project
+func (_m *ProjectService) CreateProject(ctx context.Context, project models.Project) (*models.Project, error) {
+	ret := _m.Called(ctx, project)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateProject")
+	}
+
+	var r0 *models.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Project) (*models.Project, error)); ok {
+		return rf(ctx, project)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Project) *models.Project); ok {
+		r0 = rf(ctx, project)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Project) error); ok {
+		r1 = rf(ctx, project)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProject provides a mock function with given fields: ctx, id
+func (_m *ProjectService) GetProject(ctx context.Context, id uint) (*models.Project, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProject")
+	}
+
+	var r0 *models.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*models.Project, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Project); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListProjects provides a mock function with given fields: ctx, archived
+func (_m *ProjectService) ListProjects(ctx context.Context, archived *bool) ([]models.Project, error) {
+	ret := _m.Called(ctx, archived)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProjects")
+	}
+
+	var r0 []models.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *bool) ([]models.Project, error)); ok {
+		return rf(ctx, archived)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *bool) []models.Project); ok {
+		r0 = rf(ctx, archived)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *bool) error); ok {
+		r1 = rf(ctx, archived)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProject provides a mock function with given fields: ctx, id, patch
+func (_m *ProjectService) UpdateProject(ctx context.Context, id uint, patch models.ProjectPatch) (*models.Project, error) {
+	ret := _m.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProject")
+	}
+
+	var r0 *models.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, models.ProjectPatch) (*models.Project, error)); ok {
+		return rf(ctx, id, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, models.ProjectPatch) *models.Project); ok {
+		r0 = rf(ctx, id, patch)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, models.ProjectPatch) error); ok {
+		r1 = rf(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewProjectService creates a new instance of ProjectService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProjectService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProjectService {
+	mock := &ProjectService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,108 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "todo/internal/models"
+)
+
+// ReminderService is an autogenerated mock type for the ReminderService type
+type ReminderService struct {
+	mock.Mock
+}
+
+// CreateReminder provides a mock function with given fields: ctx, reminder
+func (_m *ReminderService) CreateReminder(ctx context.Context, reminder models.Reminder) (*models.Reminder, error) {
+	ret := _m.Called(ctx, reminder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateReminder")
+	}
+
+	var r0 *models.Reminder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Reminder) (*models.Reminder, error)); ok {
+		return rf(ctx, reminder)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Reminder) *models.Reminder); ok {
+		r0 = rf(ctx, reminder)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Reminder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Reminder) error); ok {
+		r1 = rf(ctx, reminder)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteReminder provides a mock function with given fields: ctx, taskID, id
+func (_m *ReminderService) DeleteReminder(ctx context.Context, taskID uint, id uint) error {
+	ret := _m.Called(ctx, taskID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteReminder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, taskID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListReminders provides a mock function with given fields: ctx, taskID
+func (_m *ReminderService) ListReminders(ctx context.Context, taskID uint) ([]models.Reminder, error) {
+	ret := _m.Called(ctx, taskID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListReminders")
+	}
+
+	var r0 []models.Reminder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]models.Reminder, error)); ok {
+		return rf(ctx, taskID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []models.Reminder); ok {
+		r0 = rf(ctx, taskID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Reminder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, taskID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReminderService creates a new instance of ReminderService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReminderService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReminderService {
+	mock := &ReminderService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
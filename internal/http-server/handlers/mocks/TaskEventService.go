@@ -0,0 +1,92 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "todo/internal/models"
+)
+
+// TaskEventService is an autogenerated mock type for the TaskEventService type
+type TaskEventService struct {
+	mock.Mock
+}
+
+// ListTaskEvents provides a mock function with given fields: ctx, afterID, limit
+func (_m *TaskEventService) ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTaskEvents")
+	}
+
+	var r0 []models.TaskEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([]models.TaskEvent, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) []models.TaskEvent); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TaskEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: filter
+func (_m *TaskEventService) Subscribe(filter models.TaskEventFilter) (<-chan models.TaskEvent, func()) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan models.TaskEvent
+	var r1 func()
+	if rf, ok := ret.Get(0).(func(models.TaskEventFilter) (<-chan models.TaskEvent, func())); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.TaskEventFilter) <-chan models.TaskEvent); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan models.TaskEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.TaskEventFilter) func()); ok {
+		r1 = rf(filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	return r0, r1
+}
+
+// NewTaskEventService creates a new instance of TaskEventService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaskEventService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaskEventService {
+	mock := &TaskEventService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -1,13 +1,13 @@
-// Code generated by mockery v2.53.3. DO NOT EDIT.
+// Code generated by mockery v2.53.7. DO NOT EDIT.
 
 package mocks
 
 import (
-	models "todo/internal/models"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 
-	time "time"
+	models "todo/internal/models"
 )
 
 // TaskService is an autogenerated mock type for the TaskService type
@@ -15,35 +15,137 @@ type TaskService struct {
 	mock.Mock
 }
 
-// CreateTask provides a mock function with given fields: task
-func (_m *TaskService) CreateTask(task models.Task) error {
-	ret := _m.Called(task)
+// AddBlocker provides a mock function with given fields: ctx, id, blockerID, version
+func (_m *TaskService) AddBlocker(ctx context.Context, id uint, blockerID uint, version int64) (*models.Task, error) {
+	ret := _m.Called(ctx, id, blockerID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddBlocker")
+	}
+
+	var r0 *models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, int64) (*models.Task, error)); ok {
+		return rf(ctx, id, blockerID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, int64) *models.Task); ok {
+		r0 = rf(ctx, id, blockerID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, int64) error); ok {
+		r1 = rf(ctx, id, blockerID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Blockers provides a mock function with given fields: ctx, id
+func (_m *TaskService) Blockers(ctx context.Context, id uint) ([]models.Task, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Blockers")
+	}
+
+	var r0 []models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]models.Task, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []models.Task); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateTask provides a mock function with given fields: ctx, task
+func (_m *TaskService) CreateTask(ctx context.Context, task models.Task) (*models.Task, error) {
+	ret := _m.Called(ctx, task)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateTask")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(models.Task) error); ok {
-		r0 = rf(task)
+	var r0 *models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Task) (*models.Task, error)); ok {
+		return rf(ctx, task)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Task) *models.Task); ok {
+		r0 = rf(ctx, task)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Task)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, models.Task) error); ok {
+		r1 = rf(ctx, task)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// DeleteTask provides a mock function with given fields: id
-func (_m *TaskService) DeleteTask(id uint) error {
-	ret := _m.Called(id)
+// DeleteProject provides a mock function with given fields: ctx, id, policy
+func (_m *TaskService) DeleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error) {
+	ret := _m.Called(ctx, id, policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProject")
+	}
+
+	var r0 []models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, models.ProjectDeletePolicy) ([]models.Task, error)); ok {
+		return rf(ctx, id, policy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, models.ProjectDeletePolicy) []models.Task); ok {
+		r0 = rf(ctx, id, policy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, models.ProjectDeletePolicy) error); ok {
+		r1 = rf(ctx, id, policy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteTask provides a mock function with given fields: ctx, id, version
+func (_m *TaskService) DeleteTask(ctx context.Context, id uint, version int64) error {
+	ret := _m.Called(ctx, id, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteTask")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uint) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) error); ok {
+		r0 = rf(ctx, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -51,9 +153,9 @@ func (_m *TaskService) DeleteTask(id uint) error {
 	return r0
 }
 
-// GetByID provides a mock function with given fields: id
-func (_m *TaskService) GetByID(id uint) (*models.Task, error) {
-	ret := _m.Called(id)
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *TaskService) GetByID(ctx context.Context, id uint) (*models.Task, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByID")
@@ -61,19 +163,19 @@ func (_m *TaskService) GetByID(id uint) (*models.Task, error) {
 
 	var r0 *models.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uint) (*models.Task, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*models.Task, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(uint) *models.Task); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Task); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -81,9 +183,9 @@ func (_m *TaskService) GetByID(id uint) (*models.Task, error) {
 	return r0, r1
 }
 
-// List provides a mock function with given fields: page, limit, completed, date
-func (_m *TaskService) List(page int, limit int, completed *bool, date *time.Time) (*models.TasksList, error) {
-	ret := _m.Called(page, limit, completed, date)
+// List provides a mock function with given fields: ctx, filter
+func (_m *TaskService) List(ctx context.Context, filter models.TaskFilter) (*models.TasksList, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
@@ -91,19 +193,169 @@ func (_m *TaskService) List(page int, limit int, completed *bool, date *time.Tim
 
 	var r0 *models.TasksList
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int, int, *bool, *time.Time) (*models.TasksList, error)); ok {
-		return rf(page, limit, completed, date)
+	if rf, ok := ret.Get(0).(func(context.Context, models.TaskFilter) (*models.TasksList, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(int, int, *bool, *time.Time) *models.TasksList); ok {
-		r0 = rf(page, limit, completed, date)
+	if rf, ok := ret.Get(0).(func(context.Context, models.TaskFilter) *models.TasksList); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.TasksList)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int, int, *bool, *time.Time) error); ok {
-		r1 = rf(page, limit, completed, date)
+	if rf, ok := ret.Get(1).(func(context.Context, models.TaskFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NextTasks provides a mock function with given fields: ctx, limit
+func (_m *TaskService) NextTasks(ctx context.Context, limit int) ([]models.Task, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NextTasks")
+	}
+
+	var r0 []models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.Task, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.Task); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchTask provides a mock function with given fields: ctx, id, patch
+func (_m *TaskService) PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	ret := _m.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchTask")
+	}
+
+	var r0 *models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, models.TaskPatch) (*models.Task, error)); ok {
+		return rf(ctx, id, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, models.TaskPatch) *models.Task); ok {
+		r0 = rf(ctx, id, patch)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, models.TaskPatch) error); ok {
+		r1 = rf(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Progress provides a mock function with given fields: ctx, id
+func (_m *TaskService) Progress(ctx context.Context, id uint) (*models.TaskProgress, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Progress")
+	}
+
+	var r0 *models.TaskProgress
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*models.TaskProgress, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.TaskProgress); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.TaskProgress)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveBlocker provides a mock function with given fields: ctx, id, blockerID, version
+func (_m *TaskService) RemoveBlocker(ctx context.Context, id uint, blockerID uint, version int64) (*models.Task, error) {
+	ret := _m.Called(ctx, id, blockerID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveBlocker")
+	}
+
+	var r0 *models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, int64) (*models.Task, error)); ok {
+		return rf(ctx, id, blockerID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, int64) *models.Task); ok {
+		r0 = rf(ctx, id, blockerID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, int64) error); ok {
+		r1 = rf(ctx, id, blockerID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subtree provides a mock function with given fields: ctx, id
+func (_m *TaskService) Subtree(ctx context.Context, id uint) ([]models.Task, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subtree")
+	}
+
+	var r0 []models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]models.Task, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []models.Task); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -111,17 +363,17 @@ func (_m *TaskService) List(page int, limit int, completed *bool, date *time.Tim
 	return r0, r1
 }
 
-// UpdateTask provides a mock function with given fields: task
-func (_m *TaskService) UpdateTask(task *models.Task) error {
-	ret := _m.Called(task)
+// UpdateTask provides a mock function with given fields: ctx, task, children
+func (_m *TaskService) UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	ret := _m.Called(ctx, task, children)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateTask")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Task) error); ok {
-		r0 = rf(task)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Task, models.SubtaskPolicy) error); ok {
+		r0 = rf(ctx, task, children)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+//go:generate mockery --name=ProjectService --output=mocks --outpkg=mocks
+type ProjectService interface {
+	CreateProject(ctx context.Context, project models.Project) (*models.Project, error)
+	GetProject(ctx context.Context, id uint) (*models.Project, error)
+	ListProjects(ctx context.Context, archived *bool) ([]models.Project, error)
+	UpdateProject(ctx context.Context, id uint, patch models.ProjectPatch) (*models.Project, error)
+}
+
+// CreateProject godoc
+// @Summary Создать проект
+// @Description Создать проект для группировки задач
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param request body models.Project true "Данные проекта"
+// @Success 201 {object} handlers.Response{data=models.Project}
+// @Header 201 {string} Location "/projects/{id}"
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /projects [post]
+func CreateProject(log *slog.Logger, projectService ProjectService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.CreateProject"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		var req models.Project
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidatorError(validateErr))
+			return
+		}
+
+		project, err := projectService.CreateProject(r.Context(), req)
+		if err != nil {
+			writeError(w, r, log, err, "failed to create project")
+			return
+		}
+
+		log.Info("project created", slog.Int64("id", project.ID))
+
+		w.Header().Set("Location", fmt.Sprintf("/projects/%d", project.ID))
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   project,
+		})
+	}
+}
+
+// ListProjects godoc
+// @Summary Получить список проектов
+// @Description Получить проекты в порядке position
+// @Tags projects
+// @Produce json
+// @Param archived query bool false "true - только архивные, false - только активные; без параметра - все"
+// @Success 200 {object} handlers.Response{data=[]models.Project}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /projects [get]
+func ListProjects(log *slog.Logger, projectService ProjectService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ListProjects"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		var archived *bool
+		if archivedStr := r.URL.Query().Get("archived"); archivedStr != "" {
+			value, err := strconv.ParseBool(archivedStr)
+			if err != nil {
+				log.Error("invalid archived parameter", sl.Err(err))
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid archived parameter"))
+				return
+			}
+			archived = &value
+		}
+
+		projects, err := projectService.ListProjects(r.Context(), archived)
+		if err != nil {
+			writeError(w, r, log, err, "failed to list projects")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   projects,
+		})
+	}
+}
+
+// GetProject godoc
+// @Summary Получить проект по ID
+// @Tags projects
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Success 200 {object} handlers.Response{data=models.Project}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /projects/{id} [get]
+func GetProject(log *slog.Logger, projectService ProjectService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.GetProject"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		project, err := projectService.GetProject(r.Context(), uint(id))
+		if err != nil {
+			writeProjectError(w, r, log, err, id, "failed to get project")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   project,
+		})
+	}
+}
+
+// UpdateProject godoc
+// @Summary Изменить проект
+// @Description Изменить название, описание, место в списке проектов или перенести проект в архив
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Param request body models.ProjectPatch true "Изменяемые поля"
+// @Success 200 {object} handlers.Response{data=models.Project}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /projects/{id} [patch]
+func UpdateProject(log *slog.Logger, projectService ProjectService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.UpdateProject"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		var req models.ProjectPatch
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidatorError(validateErr))
+			return
+		}
+
+		project, err := projectService.UpdateProject(r.Context(), uint(id), req)
+		if err != nil {
+			writeProjectError(w, r, log, err, id, "failed to update project")
+			return
+		}
+
+		log.Info("project updated", slog.Int64("id", id))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   project,
+		})
+	}
+}
+
+// DeleteProject godoc
+// @Summary Удалить проект
+// @Description Удалить проект. Параметр tasks определяет судьбу задач проекта: refuse - вернуть 409, если задачи есть; inbox - перенести их во входящие; cascade - удалить вместе с проектом.
+// @Tags projects
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Param tasks query string false "Что делать с задачами проекта" Enums(refuse, inbox, cascade) default(refuse)
+// @Success 200 {object} handlers.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /projects/{id} [delete]
+func DeleteProject(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.DeleteProject"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		policy := models.ProjectDeletePolicy(r.URL.Query().Get("tasks"))
+		if policy == "" {
+			policy = models.ProjectDeleteRefuse
+		}
+		if !policy.Valid() {
+			log.Error("invalid tasks parameter", slog.String("tasks", string(policy)))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid tasks parameter, use refuse, inbox or cascade"))
+			return
+		}
+
+		tasks, err := taskService.DeleteProject(r.Context(), uint(id), policy)
+		if err != nil {
+			writeProjectError(w, r, log, err, id, "failed to delete project")
+			return
+		}
+
+		log.Info("project deleted",
+			slog.Int64("id", id),
+			slog.String("tasks", string(policy)),
+			slog.Int("affected", len(tasks)),
+		)
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+		})
+	}
+}
+
+// ProjectTasks godoc
+// @Summary Получить задачи проекта
+// @Description Получить задачи проекта с теми же пагинацией, фильтрами и сортировкой, что и у списка задач
+// @Tags projects
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Param completed query bool false "Совместимость: true - закрытые задачи (done или cancelled), false - открытые"
+// @Param status query []string false "Статусы задачи" collectionFormat(multi) Enums(todo, in_progress, blocked, done, cancelled)
+// @Param blocked query bool false "Блокировка (true - ждёт невыполненных задач, false - можно делать)"
+// @Param date query string false "Дата в формате YYYY-MM-DD"
+// @Param priority query string false "Приоритет задачи" Enums(none, low, medium, high, urgent)
+// @Param tag query []string false "Названия меток" collectionFormat(multi)
+// @Param tag_match query string false "Режим фильтра по меткам: any - хотя бы одна, all - все" Enums(any, all) default(any)
+// @Param sort query string false "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку" Enums(due_date, priority) default(due_date)
+// @Success 200 {object} models.TasksList
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /projects/{id}/tasks [get]
+func ProjectTasks(log *slog.Logger, projectService ProjectService, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ProjectTasks"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		filter, err := parseTaskFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		// Пустой список не отличить от несуществующего проекта, поэтому
+		// проект проверяется отдельно.
+		if _, err := projectService.GetProject(r.Context(), uint(id)); err != nil {
+			writeProjectError(w, r, log, err, id, "failed to list tasks")
+			return
+		}
+
+		filter.ProjectID = &id
+		listTasks(w, r, log, taskService, filter)
+	}
+}
+
+// writeProjectError отвечает 404, если проекта нет, 409 при попытке удалить
+// проект с задачами, остальные ошибки обрабатывает writeError.
+func writeProjectError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, id int64, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrProjectNotFound):
+		log.Info("project not found", slog.Int64("id", id))
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error("project not found"))
+	case errors.Is(err, storage.ErrProjectNotEmpty):
+		log.Info("project has tasks", slog.Int64("id", id))
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, resp.Error("project has tasks, use tasks=inbox or tasks=cascade"))
+	default:
+		writeError(w, r, log, err, msg)
+	}
+}
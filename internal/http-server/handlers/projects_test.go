@@ -0,0 +1,283 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestCreateProjectHandler(t *testing.T) {
+	cases := []struct {
+		name          string
+		body          string
+		expectProject *models.Project
+		mockError     error
+		respError     string
+		expectCode    int
+	}{
+		{
+			name:          "Success",
+			body:          `{"name": "Ремонт", "description": "Квартира", "position": 2}`,
+			expectProject: &models.Project{Name: "Ремонт", Description: "Квартира", Position: 2},
+			expectCode:    http.StatusCreated,
+		},
+		{
+			name:       "Empty name",
+			body:       `{"description": "Квартира"}`,
+			respError:  "field name is a required field",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:          "Internal error",
+			body:          `{"name": "Ремонт"}`,
+			expectProject: &models.Project{Name: "Ремонт"},
+			mockError:     errors.New("database error"),
+			respError:     "failed to create project",
+			expectCode:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			projectServiceMock := mocks.NewProjectService(t)
+
+			if tc.expectProject != nil {
+				var created *models.Project
+				if tc.mockError == nil {
+					project := *tc.expectProject
+					project.ID = 3
+					created = &project
+				}
+				projectServiceMock.On("CreateProject", mock.Anything, *tc.expectProject).
+					Return(created, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.CreateProject(logger, projectServiceMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/projects", bytes.NewReader([]byte(tc.body)))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusCreated {
+				require.Equal(t, "/projects/3", rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestDeleteProjectHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		id           string
+		query        string
+		expectPolicy models.ProjectDeletePolicy
+		mockError    error
+		respError    string
+		expectCode   int
+	}{
+		{
+			name:         "Refuse by default",
+			id:           "1",
+			expectPolicy: models.ProjectDeleteRefuse,
+			expectCode:   http.StatusOK,
+		},
+		{
+			name:         "Move tasks to inbox",
+			id:           "1",
+			query:        "?tasks=inbox",
+			expectPolicy: models.ProjectDeleteInbox,
+			expectCode:   http.StatusOK,
+		},
+		{
+			name:         "Cascade",
+			id:           "1",
+			query:        "?tasks=cascade",
+			expectPolicy: models.ProjectDeleteCascade,
+			expectCode:   http.StatusOK,
+		},
+		{
+			name:         "Project has tasks",
+			id:           "1",
+			expectPolicy: models.ProjectDeleteRefuse,
+			mockError:    storage.ErrProjectNotEmpty,
+			respError:    "project has tasks, use tasks=inbox or tasks=cascade",
+			expectCode:   http.StatusConflict,
+		},
+		{
+			name:         "Project not found",
+			id:           "1",
+			expectPolicy: models.ProjectDeleteRefuse,
+			mockError:    storage.ErrProjectNotFound,
+			respError:    "project not found",
+			expectCode:   http.StatusNotFound,
+		},
+		{
+			name:       "Invalid policy",
+			id:         "1",
+			query:      "?tasks=archive",
+			respError:  "invalid tasks parameter, use refuse, inbox or cascade",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid ID format",
+			id:         "abc",
+			respError:  "invalid id",
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+
+			if tc.expectPolicy != "" {
+				taskServiceMock.On("DeleteProject", mock.Anything, uint(1), tc.expectPolicy).
+					Return(nil, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.DeleteProject(logger, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodDelete, "/projects/"+tc.id+tc.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tc.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func TestProjectTasksHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		query        string
+		getError     error
+		expectFilter *models.TaskFilter
+		respError    string
+		expectCode   int
+	}{
+		{
+			name:  "Success",
+			query: "?page=2&limit=5&priority=high",
+			expectFilter: &models.TaskFilter{
+				Page:      2,
+				Limit:     5,
+				Priority:  priorityPtr(models.PriorityHigh),
+				TagMatch:  models.TagMatchAny,
+				ProjectID: int64Ptr(1),
+				Sort:      models.SortByDueDate,
+			},
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Project not found",
+			getError:   storage.ErrProjectNotFound,
+			respError:  "project not found",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid filter",
+			query:      "?sort=title",
+			respError:  "invalid sort parameter, use due_date or priority",
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			projectServiceMock := mocks.NewProjectService(t)
+			taskServiceMock := mocks.NewTaskService(t)
+
+			if tc.expectFilter != nil || tc.getError != nil {
+				var project *models.Project
+				if tc.getError == nil {
+					project = &models.Project{ID: 1, Name: "Ремонт"}
+				}
+				projectServiceMock.On("GetProject", mock.Anything, uint(1)).
+					Return(project, tc.getError).
+					Once()
+			}
+
+			if tc.expectFilter != nil {
+				taskServiceMock.On("List", mock.Anything, mock.MatchedBy(func(filter models.TaskFilter) bool {
+					return assert.ObjectsAreEqual(*tc.expectFilter, filter)
+				})).
+					Return(&models.TasksList{Data: []models.Task{{ID: 4, Title: "Покрасить стены"}}, Total: 1, Page: 2, Limit: 5}, nil).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.ProjectTasks(logger, projectServiceMock, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/projects/1/tasks"+tc.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			if tc.respError != "" {
+				var resp handlers.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+				return
+			}
+
+			var resp models.TasksList
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.EqualValues(t, 1, resp.Total)
+			require.Len(t, resp.Data, 1)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/lib/rrule"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// maxOccurrences ограничивает число повторений в одном ответе Occurrences.
+const maxOccurrences = 100
+
+// Occurrences godoc
+// @Summary Предпросмотр повторений
+// @Description Получить сроки следующих повторений задачи после её текущего срока, в часовом поясе задачи
+// @Tags recurrence
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param count query int false "Количество повторений, не больше 100" default(5)
+// @Success 200 {object} handlers.Response{data=[]string}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/occurrences [get]
+func Occurrences(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.Occurrences"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		count := 5
+		if value, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && value >= 1 {
+			count = min(value, maxOccurrences)
+		}
+
+		task, err := taskService.GetByID(r.Context(), uint(id))
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to get occurrences")
+			return
+		}
+
+		occurrences, err := task.Occurrences(count)
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to get occurrences")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   occurrences,
+		})
+	}
+}
+
+// SkipOccurrence godoc
+// @Summary Пропустить повторение
+// @Description Перенести срок повторяющейся задачи на следующее повторение, не выполняя её. COUNT в правиле уменьшается на единицу.
+// @Tags recurrence
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
+// @Success 200 {object} handlers.Response{data=models.Task}
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/skip [post]
+func SkipOccurrence(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return changeRecurrence(log, taskService, "handlers.SkipOccurrence", func(task models.Task) (models.TaskPatch, error) {
+		next, ok, err := task.NextOccurrence()
+		if err != nil {
+			return models.TaskPatch{}, err
+		}
+		if !ok {
+			return models.TaskPatch{}, models.ErrSeriesEnded
+		}
+		return models.TaskPatch{DueDate: &next.DueDate, RRule: &next.RRule}, nil
+	})
+}
+
+// EndRecurrence godoc
+// @Summary Завершить серию повторений
+// @Description Убрать правило повторения: задача остаётся, но при выполнении следующее повторение не создаётся
+// @Tags recurrence
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
+// @Success 200 {object} handlers.Response{data=models.Task}
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/recurrence [delete]
+func EndRecurrence(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return changeRecurrence(log, taskService, "handlers.EndRecurrence", func(task models.Task) (models.TaskPatch, error) {
+		if task.RRule == "" {
+			return models.TaskPatch{}, models.ErrNotRecurring
+		}
+		rule := ""
+		return models.TaskPatch{RRule: &rule}, nil
+	})
+}
+
+// changeRecurrence — общая часть SkipOccurrence и EndRecurrence: читает
+// задачу, проверяет If-Match и применяет патч, который вычисляет change.
+// Патч применяется только к прочитанной версии задачи, чтобы не перезаписать
+// параллельное изменение.
+func changeRecurrence(
+	log *slog.Logger,
+	taskService TaskService,
+	op string,
+	change func(task models.Task) (models.TaskPatch, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		current, err := taskService.GetByID(r.Context(), uint(id))
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to change recurrence")
+			return
+		}
+
+		version, err := ifMatchVersion(r.Header.Get("If-Match"), func() (int64, error) {
+			return current.Version, nil
+		})
+		if err == nil && version != 0 && version != current.Version {
+			err = storage.ErrVersionMismatch
+		}
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to change recurrence")
+			return
+		}
+
+		patch, err := change(*current)
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to change recurrence")
+			return
+		}
+		patch.Version = current.Version
+
+		task, err := taskService.PatchTask(r.Context(), uint(id), patch)
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to change recurrence")
+			return
+		}
+
+		log.Info("recurrence changed", slog.Int64("id", id))
+
+		w.Header().Set("ETag", etag(task.Version))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   task,
+		})
+	}
+}
+
+// normalizeRecurrence проверяет правило повторения задачи и приводит его
+// к каноническому виду, чтобы одинаковые правила не отличались записью.
+// Текст ошибки предназначен для клиента.
+func normalizeRecurrence(task *models.Task) error {
+	if task.RRule == "" {
+		return nil
+	}
+
+	rule, err := rrule.Parse(task.RRule)
+	if err != nil {
+		return err
+	}
+	task.RRule = rule.String()
+
+	return nil
+}
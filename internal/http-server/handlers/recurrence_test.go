@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+)
+
+func TestOccurrencesHandler(t *testing.T) {
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name        string
+		queryParams string
+		task        models.Task
+		respError   string
+		expectCode  int
+		expect      []string
+	}{
+		{
+			name:       "Default count",
+			task:       models.Task{ID: 1, DueDate: day, RRule: "FREQ=WEEKLY;COUNT=3"},
+			expectCode: http.StatusOK,
+			expect:     []string{"2025-04-24T09:00:00Z", "2025-05-01T09:00:00Z"},
+		},
+		{
+			name:        "Custom count in timezone",
+			queryParams: "count=2",
+			task:        models.Task{ID: 1, DueDate: day, RRule: "FREQ=DAILY", Timezone: "Europe/Moscow"},
+			expectCode:  http.StatusOK,
+			expect:      []string{"2025-04-18T12:00:00+03:00", "2025-04-19T12:00:00+03:00"},
+		},
+		{
+			name:       "Not recurring",
+			task:       models.Task{ID: 1, DueDate: day},
+			respError:  "task is not recurring",
+			expectCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+			taskServiceMock.On("GetByID", mock.Anything, uint(1)).
+				Return(&tc.task, nil).
+				Once()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.Occurrences(logger, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks/1/occurrences?"+tc.queryParams, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, withID(req, "1"))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp struct {
+				Error string   `json:"error"`
+				Data  []string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+			require.Equal(t, tc.expect, resp.Data)
+		})
+	}
+}
+
+func TestSkipOccurrenceHandler(t *testing.T) {
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+
+	cases := []struct {
+		name       string
+		task       models.Task
+		ifMatch    string
+		patch      *models.TaskPatch
+		respError  string
+		expectCode int
+	}{
+		{
+			name: "Success",
+			task: models.Task{ID: 1, DueDate: day, RRule: "FREQ=DAILY;COUNT=3", Version: 2},
+			patch: &models.TaskPatch{
+				DueDate: &nextDay,
+				RRule:   strPtr("FREQ=DAILY;COUNT=2"),
+				Version: 2,
+			},
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Last occurrence",
+			task:       models.Task{ID: 1, DueDate: day, RRule: "FREQ=DAILY;COUNT=1", Version: 2},
+			respError:  "recurrence has no more occurrences, end it instead",
+			expectCode: http.StatusConflict,
+		},
+		{
+			name:       "Not recurring",
+			task:       models.Task{ID: 1, DueDate: day, Version: 2},
+			respError:  "task is not recurring",
+			expectCode: http.StatusConflict,
+		},
+		{
+			name:       "Version mismatch",
+			task:       models.Task{ID: 1, DueDate: day, RRule: "FREQ=DAILY", Version: 2},
+			ifMatch:    `"1"`,
+			respError:  "task version mismatch",
+			expectCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+			taskServiceMock.On("GetByID", mock.Anything, uint(1)).
+				Return(&tc.task, nil).
+				Once()
+			if tc.patch != nil {
+				taskServiceMock.On("PatchTask", mock.Anything, uint(1), *tc.patch).
+					Return(&models.Task{ID: 1, DueDate: nextDay, RRule: *tc.patch.RRule, Version: 3}, nil).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.SkipOccurrence(logger, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/1/skip", nil)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, withID(req, "1"))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp struct {
+				Error string `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusOK {
+				require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestEndRecurrenceHandler(t *testing.T) {
+	taskServiceMock := mocks.NewTaskService(t)
+	taskServiceMock.On("GetByID", mock.Anything, uint(1)).
+		Return(&models.Task{ID: 1, RRule: "FREQ=DAILY", Version: 4}, nil).
+		Once()
+	taskServiceMock.On("PatchTask", mock.Anything, uint(1), models.TaskPatch{RRule: strPtr(""), Version: 4}).
+		Return(&models.Task{ID: 1, Version: 5}, nil).
+		Once()
+	taskServiceMock.On("GetByID", mock.Anything, uint(2)).
+		Return(&models.Task{ID: 2, Version: 1}, nil).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.EndRecurrence(logger, taskServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodDelete, "/tasks/1/recurrence", nil), "1"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, `"5"`, rr.Header().Get("ETag"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodDelete, "/tasks/2/recurrence", nil), "2"))
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	require.JSONEq(t, `{"status":"Error","error":"task is not recurring"}`, rr.Body.String())
+}
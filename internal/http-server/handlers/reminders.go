@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+//go:generate mockery --name=ReminderService --output=mocks --outpkg=mocks
+type ReminderService interface {
+	CreateReminder(ctx context.Context, reminder models.Reminder) (*models.Reminder, error)
+	ListReminders(ctx context.Context, taskID uint) ([]models.Reminder, error)
+	DeleteReminder(ctx context.Context, taskID, id uint) error
+}
+
+// CreateReminder godoc
+// @Summary Добавить напоминание
+// @Description Добавить напоминание о задаче: на момент remind_at или за before до срока задачи (например, "15m", "1h30m"). Напоминание относительно срока переносится вместе со сроком.
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.Reminder true "Напоминание: remind_at или before"
+// @Success 201 {object} handlers.Response{data=models.Reminder}
+// @Header 201 {string} Location "/tasks/{id}/reminders/{reminder_id}"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/reminders [post]
+func CreateReminder(log *slog.Logger, reminderService ReminderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.CreateReminder"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		var req models.Reminder
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+		req.TaskID = id
+
+		reminder, err := reminderService.CreateReminder(r.Context(), req)
+		if err != nil {
+			writeReminderError(w, r, log, err, id, "failed to create reminder")
+			return
+		}
+
+		log.Info("reminder created", slog.Int64("id", reminder.ID), slog.Int64("task_id", id))
+
+		w.Header().Set("Location", fmt.Sprintf("/tasks/%d/reminders/%d", id, reminder.ID))
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   reminder,
+		})
+	}
+}
+
+// ListReminders godoc
+// @Summary Получить напоминания задачи
+// @Description Получить напоминания задачи в порядке срабатывания, вместе с состоянием доставки
+// @Tags reminders
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} handlers.Response{data=[]models.Reminder}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/reminders [get]
+func ListReminders(log *slog.Logger, reminderService ReminderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ListReminders"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		reminders, err := reminderService.ListReminders(r.Context(), uint(id))
+		if err != nil {
+			writeReminderError(w, r, log, err, id, "failed to list reminders")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   reminders,
+		})
+	}
+}
+
+// DeleteReminder godoc
+// @Summary Удалить напоминание
+// @Description Удалить напоминание задачи
+// @Tags reminders
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param reminder_id path int true "ID напоминания"
+// @Success 200 {object} handlers.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/reminders/{reminder_id} [delete]
+func DeleteReminder(log *slog.Logger, reminderService ReminderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.DeleteReminder"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		reminderID, err := strconv.ParseInt(chi.URLParam(r, "reminder_id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse reminder id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid reminder id"))
+			return
+		}
+
+		if err := reminderService.DeleteReminder(r.Context(), uint(id), uint(reminderID)); err != nil {
+			writeReminderError(w, r, log, err, id, "failed to delete reminder")
+			return
+		}
+
+		log.Info("reminder deleted", slog.Int64("id", reminderID), slog.Int64("task_id", id))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+		})
+	}
+}
+
+// writeReminderError отвечает 404, если нет задачи или напоминания,
+// остальные ошибки обрабатывает writeError.
+func writeReminderError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, id int64, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrTaskNotFound):
+		log.Info("task not found", slog.Int64("id", id))
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error("task not found"))
+	case errors.Is(err, storage.ErrReminderNotFound):
+		log.Info("reminder not found", slog.Int64("task_id", id))
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error("reminder not found"))
+	default:
+		writeError(w, r, log, err, msg)
+	}
+}
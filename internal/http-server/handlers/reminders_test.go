@@ -0,0 +1,218 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestCreateReminderHandler(t *testing.T) {
+	before := models.Duration(15 * time.Minute)
+	remindAt := time.Date(2025, 4, 21, 6, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name           string
+		body           string
+		expectReminder *models.Reminder
+		mockError      error
+		respError      string
+		expectCode     int
+	}{
+		{
+			name:           "Before due date",
+			body:           `{"before": "15m"}`,
+			expectReminder: &models.Reminder{TaskID: 1, Before: &before},
+			expectCode:     http.StatusCreated,
+		},
+		{
+			name:           "Absolute time",
+			body:           `{"remind_at": "2025-04-21T06:00:00Z"}`,
+			expectReminder: &models.Reminder{TaskID: 1, RemindAt: &remindAt},
+			expectCode:     http.StatusCreated,
+		},
+		{
+			name:       "Neither",
+			body:       `{}`,
+			respError:  "set either remind_at or before",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Both",
+			body:       `{"before": "15m", "remind_at": "2025-04-21T06:00:00Z"}`,
+			respError:  "set either remind_at or before",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Negative offset",
+			body:       `{"before": "-5m"}`,
+			respError:  "before must not be negative",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid duration",
+			body:       `{"before": "soon"}`,
+			respError:  "failed to decode request",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Task not found",
+			body:           `{"before": "15m"}`,
+			expectReminder: &models.Reminder{TaskID: 1, Before: &before},
+			mockError:      storage.ErrTaskNotFound,
+			respError:      "task not found",
+			expectCode:     http.StatusNotFound,
+		},
+		{
+			name:           "Internal error",
+			body:           `{"before": "15m"}`,
+			expectReminder: &models.Reminder{TaskID: 1, Before: &before},
+			mockError:      errors.New("database error"),
+			respError:      "failed to create reminder",
+			expectCode:     http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reminderServiceMock := mocks.NewReminderService(t)
+
+			if tc.expectReminder != nil {
+				var created *models.Reminder
+				if tc.mockError == nil {
+					reminder := *tc.expectReminder
+					reminder.ID = 5
+					created = &reminder
+				}
+				reminderServiceMock.On("CreateReminder", mock.Anything, *tc.expectReminder).
+					Return(created, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.CreateReminder(logger, reminderServiceMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/1/reminders", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, withID(req, "1"))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusCreated {
+				require.Equal(t, "/tasks/1/reminders/5", rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestListRemindersHandler(t *testing.T) {
+	before := models.Duration(time.Hour)
+	fireAt := time.Date(2025, 4, 21, 5, 0, 0, 0, time.UTC)
+
+	reminderServiceMock := mocks.NewReminderService(t)
+	reminderServiceMock.On("ListReminders", mock.Anything, uint(1)).
+		Return([]models.Reminder{{ID: 5, TaskID: 1, Before: &before, FireAt: fireAt}}, nil).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.ListReminders(logger, reminderServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/1/reminders", nil), "1"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"before":"1h0m0s"`)
+
+	var resp struct {
+		Data []models.Reminder `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	require.Equal(t, fireAt, resp.Data[0].FireAt)
+}
+
+func TestDeleteReminderHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		reminderID string
+		mockError  error
+		respError  string
+		expectCode int
+	}{
+		{
+			name:       "Success",
+			reminderID: "5",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Reminder not found",
+			reminderID: "5",
+			mockError:  storage.ErrReminderNotFound,
+			respError:  "reminder not found",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid reminder id",
+			reminderID: "abc",
+			respError:  "invalid reminder id",
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reminderServiceMock := mocks.NewReminderService(t)
+			if tc.expectCode != http.StatusBadRequest {
+				reminderServiceMock.On("DeleteReminder", mock.Anything, uint(1), uint(5)).
+					Return(tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.DeleteReminder(logger, reminderServiceMock)
+
+			req := httptest.NewRequest(http.MethodDelete, "/tasks/1/reminders/"+tc.reminderID, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			rctx.URLParams.Add("reminder_id", tc.reminderID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Children godoc
+// @Summary Получить подзадачи
+// @Description Получить прямые подзадачи задачи с теми же пагинацией, фильтрами и сортировкой, что и у списка задач
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Param completed query bool false "Совместимость: true - закрытые задачи (done или cancelled), false - открытые"
+// @Param status query []string false "Статусы задачи" collectionFormat(multi) Enums(todo, in_progress, blocked, done, cancelled)
+// @Param blocked query bool false "Блокировка (true - ждёт невыполненных задач, false - можно делать)"
+// @Param date query string false "Дата в формате YYYY-MM-DD"
+// @Param priority query string false "Приоритет задачи" Enums(none, low, medium, high, urgent)
+// @Param tag query []string false "Названия меток" collectionFormat(multi)
+// @Param tag_match query string false "Режим фильтра по меткам: any - хотя бы одна, all - все" Enums(any, all) default(any)
+// @Param sort query string false "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку" Enums(due_date, priority) default(due_date)
+// @Success 200 {object} models.TasksList
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/children [get]
+func Children(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.Children"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		filter, err := parseTaskFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		// Пустой список не отличить от несуществующей задачи, поэтому
+		// задача проверяется отдельно.
+		if _, err := taskService.GetByID(r.Context(), uint(id)); err != nil {
+			writeTaskError(w, r, log, err, id, "failed to list tasks")
+			return
+		}
+
+		filter.ParentID = &id
+		listTasks(w, r, log, taskService, filter)
+	}
+}
+
+// Subtree godoc
+// @Summary Получить дерево задачи
+// @Description Получить задачу со всеми подзадачами на любой глубине. Подзадачи каждого уровня упорядочены по сроку выполнения.
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} handlers.Response{data=models.TaskTree}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id}/subtree [get]
+func Subtree(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.Subtree"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		tasks, err := taskService.Subtree(r.Context(), uint(id))
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to get subtree")
+			return
+		}
+
+		tree, ok := models.BuildTaskTree(id, tasks)
+		if !ok {
+			writeTaskError(w, r, log, storage.ErrTaskNotFound, id, "failed to get subtree")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tree,
+		})
+	}
+}
+
+// parseSubtaskPolicy разбирает параметр children: пустое значение означает
+// SubtasksBlock. Текст ошибки предназначен для клиента.
+func parseSubtaskPolicy(value string) (models.SubtaskPolicy, error) {
+	switch policy := models.SubtaskPolicy(value); policy {
+	case "":
+		return models.SubtasksBlock, nil
+	case models.SubtasksBlock, models.SubtasksComplete:
+		return policy, nil
+	default:
+		return "", errors.New("invalid children parameter, use block or complete")
+	}
+}
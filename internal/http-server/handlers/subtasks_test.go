@@ -0,0 +1,244 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/storage/memory"
+	"todo/internal/workflow"
+)
+
+func TestSubtreeHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		mockResp   []models.Task
+		mockError  error
+		respError  string
+		expectCode int
+	}{
+		{
+			name: "Success",
+			mockResp: []models.Task{
+				{ID: 2, Title: "build", ParentID: int64Ptr(1)},
+				{ID: 3, Title: "tests", ParentID: int64Ptr(2)},
+				{ID: 4, Title: "docs", ParentID: int64Ptr(1)},
+				{ID: 1, Title: "release"},
+			},
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Not found",
+			mockError:  storage.ErrTaskNotFound,
+			respError:  "task not found",
+			expectCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+			taskServiceMock.On("Subtree", mock.Anything, uint(1)).Return(tc.mockResp, tc.mockError).Once()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.Subtree(logger, taskServiceMock)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/1/subtree", nil), "1"))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp struct {
+				Error string          `json:"error"`
+				Data  models.TaskTree `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusOK {
+				require.Equal(t, "release", resp.Data.Title)
+				require.Len(t, resp.Data.Children, 2)
+				require.Equal(t, "build", resp.Data.Children[0].Title)
+				require.Equal(t, "tests", resp.Data.Children[0].Children[0].Title)
+				require.Equal(t, "docs", resp.Data.Children[1].Title)
+				require.Empty(t, resp.Data.Children[1].Children)
+			}
+		})
+	}
+}
+
+func TestChildrenHandler(t *testing.T) {
+	taskServiceMock := mocks.NewTaskService(t)
+	taskServiceMock.On("GetByID", mock.Anything, uint(1)).Return(&models.Task{ID: 1}, nil).Once()
+	taskServiceMock.On("List", mock.Anything, mock.MatchedBy(func(filter models.TaskFilter) bool {
+		return filter.ParentID != nil && *filter.ParentID == 1 && filter.Completed != nil && !*filter.Completed
+	})).
+		Return(&models.TasksList{Data: []models.Task{{ID: 2, Title: "build"}}, Total: 1, Page: 1, Limit: 10}, nil).
+		Once()
+	taskServiceMock.On("GetByID", mock.Anything, uint(5)).Return(nil, storage.ErrTaskNotFound).Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.Children(logger, taskServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/1/children?completed=false", nil), "1"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var list models.TasksList
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.EqualValues(t, 1, list.Total)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/5/children", nil), "5"))
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetByIDHandlerProgress(t *testing.T) {
+	taskServiceMock := mocks.NewTaskService(t)
+	taskServiceMock.On("GetByID", mock.Anything, uint(1)).
+		Return(&models.Task{ID: 1, Title: "release", Version: 3}, nil).
+		Once()
+	taskServiceMock.On("Progress", mock.Anything, uint(1)).
+		Return(&models.TaskProgress{Children: 2, Subtasks: 3, Completed: 1, Percent: 33}, nil).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.GetByID(logger, taskServiceMock)
+
+	// С include=progress If-None-Match не приводит к 304: прогресс мог
+	// измениться без изменения версии задачи.
+	req := withID(httptest.NewRequest(http.MethodGet, "/tasks/1?include=progress", nil), "1")
+	req.Header.Set("If-None-Match", `"3"`)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Data models.Task `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Data.Progress)
+	require.Equal(t, 33, resp.Data.Progress.Percent)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/tasks/1?include=children", nil), "1"))
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestPatchTaskHandlerCompleteSubtasks(t *testing.T) {
+	current := &models.Task{
+		ID:       1,
+		Title:    "release",
+		DueDate:  time.Now().UTC().Truncate(time.Second),
+		Priority: models.PriorityNone,
+		Tags:     []models.Tag{},
+	}
+
+	taskServiceMock := mocks.NewTaskService(t)
+	taskServiceMock.On("GetByID", mock.Anything, uint(1)).Return(current, nil).Once()
+	taskServiceMock.On("PatchTask", mock.Anything, uint(1), models.TaskPatch{
+		Status:   statusPtr(models.StatusDone),
+		Children: models.SubtasksComplete,
+	}).
+		Return(current, nil).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.PatchTask(logger, taskServiceMock)
+
+	req := withID(httptest.NewRequest(http.MethodPatch, "/tasks/1?children=complete", strings.NewReader(`{"status": true}`)), "1")
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodPatch, "/tasks/1?children=skip", strings.NewReader(`{}`)), "1"))
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var resp handlers.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "invalid children parameter, use block or complete", resp.Error)
+}
+
+func TestCompleteSubtasksRejected(t *testing.T) {
+	cases := []struct {
+		name       string
+		method     string
+		status     models.TaskStatus
+		ifMatch    string
+		expectCode int
+	}{
+		{name: "PUT stale version", method: http.MethodPut, ifMatch: `"7"`, expectCode: http.StatusPreconditionFailed},
+		{name: "PUT invalid transition", method: http.MethodPut, status: models.StatusBlocked, expectCode: http.StatusConflict},
+		{name: "PATCH stale version", method: http.MethodPatch, ifMatch: `"7"`, expectCode: http.StatusPreconditionFailed},
+		{name: "PATCH invalid transition", method: http.MethodPatch, status: models.StatusBlocked, expectCode: http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			tasks := workflow.WrapTaskService(memory.New(), models.DefaultWorkflow)
+
+			due := time.Date(2025, 4, 20, 15, 0, 0, 0, time.UTC)
+			parent, err := tasks.CreateTask(ctx, models.Task{Title: "release", DueDate: due, Status: tc.status})
+			require.NoError(t, err)
+			child, err := tasks.CreateTask(ctx, models.Task{Title: "docs", DueDate: due, ParentID: &parent.ID})
+			require.NoError(t, err)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.UpdateTask(logger, tasks)
+			body := `{"title": "release", "due_date": "2025-04-20T15:00:00Z", "status": "done"}`
+			if tc.method == http.MethodPatch {
+				handler = handlers.PatchTask(logger, tasks)
+				body = `{"status": "done"}`
+			}
+
+			req := withID(httptest.NewRequest(tc.method, "/tasks/1?children=complete", strings.NewReader(body)), "1")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			stored, err := tasks.GetByID(ctx, uint(child.ID))
+			require.NoError(t, err)
+			require.Equal(t, models.StatusTodo, stored.Status)
+			require.Equal(t, child.Version, stored.Version)
+		})
+	}
+}
+
+func withID(req *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+//go:generate mockery --name=TagService --output=mocks --outpkg=mocks
+type TagService interface {
+	CreateTag(ctx context.Context, tag models.Tag) (*models.Tag, error)
+	GetTag(ctx context.Context, id uint) (*models.Tag, error)
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	UpdateTag(ctx context.Context, id uint, patch models.TagPatch) (*models.Tag, error)
+	DeleteTag(ctx context.Context, id uint) error
+}
+
+// CreateTag godoc
+// @Summary Создать метку
+// @Description Создать метку с уникальным названием
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body models.Tag true "Данные метки"
+// @Success 201 {object} handlers.Response{data=models.Tag}
+// @Header 201 {string} Location "/tags/{id}"
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tags [post]
+func CreateTag(log *slog.Logger, tagService TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.CreateTag"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		var req models.Tag
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidatorError(validateErr))
+			return
+		}
+
+		tag, err := tagService.CreateTag(r.Context(), req)
+		if err != nil {
+			writeTagError(w, r, log, err, 0, "failed to create tag")
+			return
+		}
+
+		log.Info("tag created", slog.Int64("id", tag.ID))
+
+		w.Header().Set("Location", fmt.Sprintf("/tags/%d", tag.ID))
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tag,
+		})
+	}
+}
+
+// ListTags godoc
+// @Summary Получить список меток
+// @Description Получить все метки, упорядоченные по названию
+// @Tags tags
+// @Produce json
+// @Success 200 {object} handlers.Response{data=[]models.Tag}
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tags [get]
+func ListTags(log *slog.Logger, tagService TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ListTags"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		tags, err := tagService.ListTags(r.Context())
+		if err != nil {
+			writeError(w, r, log, err, "failed to list tags")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tags,
+		})
+	}
+}
+
+// GetTag godoc
+// @Summary Получить метку по ID
+// @Tags tags
+// @Produce json
+// @Param id path int true "ID метки"
+// @Success 200 {object} handlers.Response{data=models.Tag}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tags/{id} [get]
+func GetTag(log *slog.Logger, tagService TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.GetTag"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		tag, err := tagService.GetTag(r.Context(), uint(id))
+		if err != nil {
+			writeTagError(w, r, log, err, id, "failed to get tag")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tag,
+		})
+	}
+}
+
+// UpdateTag godoc
+// @Summary Изменить метку
+// @Description Переименовать метку или изменить её цвет. Изменение видно во всех задачах с этой меткой.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID метки"
+// @Param request body models.TagPatch true "Изменяемые поля"
+// @Success 200 {object} handlers.Response{data=models.Tag}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tags/{id} [patch]
+func UpdateTag(log *slog.Logger, tagService TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.UpdateTag"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		var req models.TagPatch
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidatorError(validateErr))
+			return
+		}
+
+		tag, err := tagService.UpdateTag(r.Context(), uint(id), req)
+		if err != nil {
+			writeTagError(w, r, log, err, id, "failed to update tag")
+			return
+		}
+
+		log.Info("tag updated", slog.Int64("id", id))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   tag,
+		})
+	}
+}
+
+// DeleteTag godoc
+// @Summary Удалить метку
+// @Description Удалить метку и снять её со всех задач
+// @Tags tags
+// @Produce json
+// @Param id path int true "ID метки"
+// @Success 200 {object} handlers.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tags/{id} [delete]
+func DeleteTag(log *slog.Logger, tagService TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.DeleteTag"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		if err := tagService.DeleteTag(r.Context(), uint(id)); err != nil {
+			writeTagError(w, r, log, err, id, "failed to delete tag")
+			return
+		}
+
+		log.Info("tag deleted", slog.Int64("id", id))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+		})
+	}
+}
+
+// writeTagError отвечает 404, если метки нет, 409 при конфликте названий,
+// остальные ошибки обрабатывает writeError.
+func writeTagError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, id int64, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrTagNotFound):
+		log.Info("tag not found", slog.Int64("id", id))
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error("tag not found"))
+	case errors.Is(err, storage.ErrTagExists):
+		log.Info("tag already exists")
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, resp.Error("tag already exists"))
+	default:
+		writeError(w, r, log, err, msg)
+	}
+}
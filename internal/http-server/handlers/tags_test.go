@@ -0,0 +1,308 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestCreateTagHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		expectTag  *models.Tag
+		mockError  error
+		respError  string
+		expectCode int
+	}{
+		{
+			name:       "Success",
+			body:       `{"name": "backend", "color": "#1e90ff"}`,
+			expectTag:  &models.Tag{Name: "backend", Color: "#1e90ff"},
+			expectCode: http.StatusCreated,
+		},
+		{
+			name:       "Success without color",
+			body:       `{"name": "errand"}`,
+			expectTag:  &models.Tag{Name: "errand"},
+			expectCode: http.StatusCreated,
+		},
+		{
+			name:       "Empty name",
+			body:       `{"color": "#1e90ff"}`,
+			respError:  "field name is a required field",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid color",
+			body:       `{"name": "backend", "color": "blue"}`,
+			respError:  "field color is not valid",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Tag exists",
+			body:       `{"name": "backend"}`,
+			expectTag:  &models.Tag{Name: "backend"},
+			mockError:  storage.ErrTagExists,
+			respError:  "tag already exists",
+			expectCode: http.StatusConflict,
+		},
+		{
+			name:       "Internal error",
+			body:       `{"name": "backend"}`,
+			expectTag:  &models.Tag{Name: "backend"},
+			mockError:  errors.New("database error"),
+			respError:  "failed to create tag",
+			expectCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tagServiceMock := mocks.NewTagService(t)
+
+			if tc.expectTag != nil {
+				var created *models.Tag
+				if tc.mockError == nil {
+					created = &models.Tag{ID: 7, Name: tc.expectTag.Name, Color: tc.expectTag.Color}
+				}
+				tagServiceMock.On("CreateTag", mock.Anything, *tc.expectTag).
+					Return(created, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.CreateTag(logger, tagServiceMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/tags", bytes.NewReader([]byte(tc.body)))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusCreated {
+				require.Equal(t, "/tags/7", rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestUpdateTagHandler(t *testing.T) {
+	cases := []struct {
+		name        string
+		id          string
+		body        string
+		expectPatch *models.TagPatch
+		mockError   error
+		respError   string
+		expectCode  int
+	}{
+		{
+			name:        "Rename",
+			id:          "1",
+			body:        `{"name": "frontend"}`,
+			expectPatch: &models.TagPatch{Name: strPtr("frontend")},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Clear color",
+			id:          "1",
+			body:        `{"color": ""}`,
+			expectPatch: &models.TagPatch{Color: strPtr("")},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:       "Empty name",
+			id:         "1",
+			body:       `{"name": ""}`,
+			respError:  "field name is not valid",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid color",
+			id:         "1",
+			body:       `{"color": "#12"}`,
+			respError:  "field color is not valid",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid ID format",
+			id:         "abc",
+			body:       `{"name": "frontend"}`,
+			respError:  "invalid id",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Tag not found",
+			id:          "1",
+			body:        `{"name": "frontend"}`,
+			expectPatch: &models.TagPatch{Name: strPtr("frontend")},
+			mockError:   storage.ErrTagNotFound,
+			respError:   "tag not found",
+			expectCode:  http.StatusNotFound,
+		},
+		{
+			name:        "Name taken",
+			id:          "1",
+			body:        `{"name": "frontend"}`,
+			expectPatch: &models.TagPatch{Name: strPtr("frontend")},
+			mockError:   storage.ErrTagExists,
+			respError:   "tag already exists",
+			expectCode:  http.StatusConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tagServiceMock := mocks.NewTagService(t)
+
+			if tc.expectPatch != nil {
+				var updated *models.Tag
+				if tc.mockError == nil {
+					updated = &models.Tag{ID: 1, Name: "frontend"}
+				}
+				tagServiceMock.On("UpdateTag", mock.Anything, uint(1), *tc.expectPatch).
+					Return(updated, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.UpdateTag(logger, tagServiceMock)
+
+			req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tags/%s", tc.id), bytes.NewReader([]byte(tc.body)))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tc.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func TestDeleteTagHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		id         string
+		mockError  error
+		respError  string
+		expectCode int
+	}{
+		{
+			name:       "Success",
+			id:         "1",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Invalid ID format",
+			id:         "abc",
+			respError:  "invalid id",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Tag not found",
+			id:         "1",
+			mockError:  storage.ErrTagNotFound,
+			respError:  "tag not found",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "Internal error",
+			id:         "1",
+			mockError:  errors.New("database error"),
+			respError:  "failed to delete tag",
+			expectCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tagServiceMock := mocks.NewTagService(t)
+
+			if tc.id == "1" {
+				tagServiceMock.On("DeleteTag", mock.Anything, uint(1)).Return(tc.mockError).Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.DeleteTag(logger, tagServiceMock)
+
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tags/%s", tc.id), nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tc.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func TestListTagsHandler(t *testing.T) {
+	tagServiceMock := mocks.NewTagService(t)
+	tagServiceMock.On("ListTags", mock.Anything).
+		Return([]models.Tag{{ID: 1, Name: "backend"}, {ID: 2, Name: "errand", Color: "#ff4500"}}, nil).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.ListTags(logger, tagServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tags", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Data []models.Tag `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 2)
+	require.Equal(t, "errand", resp.Data[1].Name)
+	require.Equal(t, "#ff4500", resp.Data[1].Color)
+}
@@ -1,13 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"time"
 
 	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/jsonpatch"
 	"todo/internal/lib/logger/sl"
 	"todo/internal/models"
 	"todo/internal/storage"
@@ -33,11 +41,22 @@ type Response struct {
 //
 //go:generate mockery --name=TaskService --output=mocks --outpkg=mocks
 type TaskService interface {
-	CreateTask(task models.Task) error
-	GetByID(id uint) (*models.Task, error)
-	UpdateTask(task *models.Task) error
-	DeleteTask(id uint) error
-	List(page, limit int, completed *bool, date *time.Time) (*models.TasksList, error)
+	CreateTask(ctx context.Context, task models.Task) (*models.Task, error)
+	GetByID(ctx context.Context, id uint) (*models.Task, error)
+	UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error
+	PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error)
+	DeleteTask(ctx context.Context, id uint, version int64) error
+	List(ctx context.Context, filter models.TaskFilter) (*models.TasksList, error)
+	Subtree(ctx context.Context, id uint) ([]models.Task, error)
+	Progress(ctx context.Context, id uint) (*models.TaskProgress, error)
+	Blockers(ctx context.Context, id uint) ([]models.Task, error)
+	AddBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error)
+	RemoveBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error)
+	NextTasks(ctx context.Context, limit int) ([]models.Task, error)
+	// DeleteProject переносит или удаляет задачи проекта, поэтому, как и
+	// остальные изменения задач, проходит через обёртки с событиями и
+	// метриками. Возвращает затронутые задачи.
+	DeleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error)
 }
 
 // New godoc
@@ -47,17 +66,20 @@ type TaskService interface {
 // @Accept json
 // @Produce json
 // @Param request body models.Task true "Данные задачи"
-// @Success 200 {object} handlers.Response
+// @Success 201 {object} handlers.Response{data=models.Task}
+// @Header 201 {string} Location "/tasks/{id}"
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /newtask [post]
 func New(log *slog.Logger, TaskService TaskService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
 		)
 
 		var req models.Task
@@ -72,27 +94,26 @@ func New(log *slog.Logger, TaskService TaskService) http.HandlerFunc {
 
 		log.Info("request body decoded", slog.Any("request", req))
 
-		if err := validator.New().Struct(req); err != nil {
-			validateErr := err.(validator.ValidationErrors)
+		if err := validateTask(&req); err != nil {
 			log.Error("invalid request", sl.Err(err))
 			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, resp.ValidatorError(validateErr))
+			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		err = TaskService.CreateTask(req)
+		task, err := TaskService.CreateTask(r.Context(), req)
 		if err != nil {
-			log.Error("failed to add url", sl.Err(err))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("failed to create task"))
+			writeTaskError(w, r, log, err, 0, "failed to create task")
 			return
 		}
 
-		log.Info("task created")
+		log.Info("task created", slog.Int64("id", task.ID))
 
-		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Location", fmt.Sprintf("/tasks/%d", task.ID))
+		w.WriteHeader(http.StatusCreated)
 		respObj := Response{
 			Status: "OK",
+			Data:   task,
 		}
 		render.JSON(w, r, respObj)
 	}
@@ -105,18 +126,24 @@ func New(log *slog.Logger, TaskService TaskService) http.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param include query string false "progress - добавить число подзадач и процент выполнения" Enums(progress)
+// @Param If-None-Match header string false "ETag задачи, известный клиенту"
 // @Success 200 {object} models.Task
+// @Header 200 {string} ETag "Версия задачи"
+// @Success 304 "Задача не изменилась"
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /tasks/{id} [get]
 func GetByID(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.GetByID"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
 		)
 
 		idStr := chi.URLParam(r, "id")
@@ -128,17 +155,32 @@ func GetByID(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 			return
 		}
 
-		task, err := taskService.GetByID(uint(id))
+		include := r.URL.Query().Get("include")
+		if include != "" && include != "progress" {
+			log.Error("invalid include parameter", slog.String("include", include))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid include parameter, use progress"))
+			return
+		}
+
+		task, err := taskService.GetByID(r.Context(), uint(id))
 		if err != nil {
-			if errors.Is(err, storage.ErrTaskNotFound) {
-				log.Info("task not found", slog.Uint64("id", uint64(id)))
-				w.WriteHeader(http.StatusNotFound)
-				render.JSON(w, r, resp.Error("task not found"))
+			writeTaskError(w, r, log, err, id, "failed to get task")
+			return
+		}
+
+		w.Header().Set("ETag", etag(task.Version))
+
+		// Прогресс меняется вместе с подзадачами, а версия задачи при этом
+		// остаётся прежней, поэтому с include=progress 304 не отдаётся.
+		if include == "progress" {
+			task.Progress, err = taskService.Progress(r.Context(), uint(id))
+			if err != nil {
+				writeTaskError(w, r, log, err, id, "failed to get task")
 				return
 			}
-			log.Error("failed to get task", sl.Err(err))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("failed to get task"))
+		} else if noneMatch(r.Header.Get("If-None-Match"), task.Version) {
+			w.WriteHeader(http.StatusNotModified)
 			return
 		}
 
@@ -153,24 +195,32 @@ func GetByID(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 
 // UpdateTask godoc
 // @Summary Обновить задачу
-// @Description Обновить существующую задачу по ID
+// @Description Обновить существующую задачу по ID. Выполнение повторяющейся задачи создаёт её следующее повторение.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
 // @Param request body models.Task true "Данные задачи для обновления"
+// @Param children query string false "Выполнение задачи с открытыми подзадачами: block - отказать с 409, complete - выполнить и подзадачи" Enums(block, complete) default(block)
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
 // @Success 200 {object} handlers.Response
+// @Header 200 {string} ETag "Новая версия задачи"
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /tasks/{id} [put]
 func UpdateTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.Update"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
 		)
 
 		idStr := chi.URLParam(r, "id")
@@ -182,6 +232,14 @@ func UpdateTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 			return
 		}
 
+		policy, err := parseSubtaskPolicy(r.URL.Query().Get("children"))
+		if err != nil {
+			log.Error("invalid children parameter", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
 		var req models.Task
 		err = render.DecodeJSON(r.Body, &req)
 		if err != nil {
@@ -192,39 +250,253 @@ func UpdateTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 		}
 
 		req.ID = id
+		req.Version, err = ifMatchVersion(r.Header.Get("If-Match"), func() (int64, error) {
+			task, err := taskService.GetByID(r.Context(), uint(id))
+			if err != nil {
+				return 0, err
+			}
+			return task.Version, nil
+		})
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to update task")
+			return
+		}
+
 		log.Info("request body decoded", slog.Any("request", req))
 
-		if err := validator.New().Struct(req); err != nil {
-			validateErr := err.(validator.ValidationErrors)
+		if err := validateTask(&req); err != nil {
 			log.Error("invalid request", sl.Err(err))
 			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, resp.ValidatorError(validateErr))
+			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		err = taskService.UpdateTask(&req)
+		err = taskService.UpdateTask(r.Context(), &req, policy)
 		if err != nil {
-			if errors.Is(err, storage.ErrTaskNotFound) {
-				log.Info("task not found", slog.Int64("id", id))
-				w.WriteHeader(http.StatusNotFound)
-				render.JSON(w, r, resp.Error("task not found"))
-				return
-			}
-			log.Error("failed to update task", sl.Err(err))
+			writeTaskError(w, r, log, err, id, "failed to update task")
+			return
+		}
+
+		log.Info("task updated", slog.Int64("id", id))
+		w.Header().Set("ETag", etag(req.Version))
+		w.WriteHeader(http.StatusOK)
+		respObj := Response{
+			Status: "OK",
+		}
+		render.JSON(w, r, respObj)
+	}
+}
+
+// PatchTask godoc
+// @Summary Частично обновить задачу
+// @Description Изменить только переданные поля задачи. Поддерживаются JSON Merge Patch (RFC 7396, application/merge-patch+json или application/json) и JSON Patch (RFC 6902, application/json-patch+json). Валидация применяется к результату слияния. Выполнение повторяющейся задачи создаёт её следующее повторение.
+// @Tags tasks
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body object true "Патч задачи"
+// @Param children query string false "Выполнение задачи с открытыми подзадачами: block - отказать с 409, complete - выполнить и подзадачи" Enums(block, complete) default(block)
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
+// @Success 200 {object} handlers.Response{data=models.Task}
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /tasks/{id} [patch]
+func PatchTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.Patch"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		policy, err := parseSubtaskPolicy(r.URL.Query().Get("children"))
+		if err != nil {
+			log.Error("invalid children parameter", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		applyPatch, err := patchFunc(r.Header.Get("Content-Type"))
+		if err != nil {
+			log.Error("unsupported content type", sl.Err(err))
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			render.JSON(w, r, resp.Error("unsupported content type"))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Error("failed to read request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		current, err := taskService.GetByID(r.Context(), uint(id))
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to update task")
+			return
+		}
+
+		version, err := ifMatchVersion(r.Header.Get("If-Match"), func() (int64, error) {
+			return current.Version, nil
+		})
+		if err == nil && version != 0 && version != current.Version {
+			err = storage.ErrVersionMismatch
+		}
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to update task")
+			return
+		}
+
+		doc, err := json.Marshal(current)
+		if err != nil {
+			log.Error("failed to encode task", sl.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			render.JSON(w, r, resp.Error("failed to update task"))
 			return
 		}
 
-		log.Info("task updated", slog.Int64("id", id))
+		patched, err := applyPatch(doc, body)
+		if err != nil {
+			log.Error("failed to apply patch", sl.Err(err))
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				w.WriteHeader(http.StatusConflict)
+				render.JSON(w, r, resp.Error("patch test failed"))
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid patch"))
+			return
+		}
+
+		var req models.Task
+		if err := json.Unmarshal(patched, &req); err != nil {
+			log.Error("failed to decode patched task", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid patch"))
+			return
+		}
+
+		log.Info("patch applied", slog.Any("request", req))
+
+		if err := validateTask(&req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		// Хранилище сохраняет пустой приоритет как none и пустой статус как
+		// todo, поэтому их сброс через null не должен считаться изменением.
+		req.Priority = req.Priority.OrNone()
+		req.Status = req.Status.OrTodo()
+
+		patch := diffTask(*current, req)
+		patch.Version = version
+		patch.Children = policy
+
+		task, err := taskService.PatchTask(r.Context(), uint(id), patch)
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to update task")
+			return
+		}
+
+		log.Info("task patched", slog.Int64("id", id))
+		w.Header().Set("ETag", etag(task.Version))
 		w.WriteHeader(http.StatusOK)
 		respObj := Response{
 			Status: "OK",
+			Data:   task,
 		}
 		render.JSON(w, r, respObj)
 	}
 }
 
+// patchFunc выбирает формат патча по заголовку Content-Type. Обычный
+// application/json трактуется как merge patch.
+func patchFunc(contentType string) (func(doc, patch []byte) ([]byte, error), error) {
+	if contentType == "" {
+		return jsonpatch.MergePatch, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mediaType {
+	case jsonpatch.MergePatchContentType, "application/json":
+		return jsonpatch.MergePatch, nil
+	case jsonpatch.JSONPatchContentType:
+		return jsonpatch.JSONPatch, nil
+	default:
+		return nil, fmt.Errorf("unsupported media type %q", mediaType)
+	}
+}
+
+// diffTask возвращает патч только с теми изменяемыми полями, которые
+// отличаются от текущего состояния задачи.
+func diffTask(current, patched models.Task) models.TaskPatch {
+	var patch models.TaskPatch
+
+	if patched.Title != current.Title {
+		patch.Title = &patched.Title
+	}
+	if patched.Description != current.Description {
+		patch.Description = &patched.Description
+	}
+	if !patched.DueDate.Equal(current.DueDate) {
+		patch.DueDate = &patched.DueDate
+	}
+	if patched.Status != current.Status {
+		patch.Status = &patched.Status
+	}
+	if patched.Priority != current.Priority {
+		patch.Priority = &patched.Priority
+	}
+	if !slices.Equal(sortedTagNames(patched.Tags), sortedTagNames(current.Tags)) {
+		patch.Tags = &patched.Tags
+	}
+	if refID(patched.ProjectID) != refID(current.ProjectID) {
+		id := refID(patched.ProjectID)
+		patch.ProjectID = &id
+	}
+	if refID(patched.ParentID) != refID(current.ParentID) {
+		id := refID(patched.ParentID)
+		patch.ParentID = &id
+	}
+	if patched.RRule != current.RRule {
+		patch.RRule = &patched.RRule
+	}
+	if patched.Timezone != current.Timezone {
+		patch.Timezone = &patched.Timezone
+	}
+
+	return patch
+}
+
 // DeleteTask godoc
 // @Summary Удалить задачу
 // @Description Удалить задачу по её идентификатору
@@ -232,18 +504,23 @@ func UpdateTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param If-Match header string false "ETag задачи; при несовпадении возвращается 412"
 // @Success 200 {object} handlers.Response
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /tasks/{id} [delete]
 func DeleteTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.Delete"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
 		)
 
 		idStr := chi.URLParam(r, "id")
@@ -255,17 +532,21 @@ func DeleteTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 			return
 		}
 
-		err = taskService.DeleteTask(uint(id))
-		if err != nil {
-			if errors.Is(err, storage.ErrTaskNotFound) {
-				log.Info("task not found", slog.Uint64("id", uint64(id)))
-				w.WriteHeader(http.StatusNotFound)
-				render.JSON(w, r, resp.Error("task not found"))
-				return
+		version, err := ifMatchVersion(r.Header.Get("If-Match"), func() (int64, error) {
+			task, err := taskService.GetByID(r.Context(), uint(id))
+			if err != nil {
+				return 0, err
 			}
-			log.Error("failed to delete task", sl.Err(err))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("failed to delete task"))
+			return task.Version, nil
+		})
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to delete task")
+			return
+		}
+
+		err = taskService.DeleteTask(r.Context(), uint(id), version)
+		if err != nil {
+			writeTaskError(w, r, log, err, id, "failed to delete task")
 			return
 		}
 
@@ -286,76 +567,259 @@ func DeleteTask(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 // @Produce json
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Количество элементов на странице" default(10)
-// @Param completed query bool false "Статус задачи (true - выполнена, false - не выполнена)"
+// @Param completed query bool false "Совместимость: true - закрытые задачи (done или cancelled), false - открытые"
+// @Param status query []string false "Статусы задачи" collectionFormat(multi) Enums(todo, in_progress, blocked, done, cancelled)
+// @Param blocked query bool false "Блокировка (true - ждёт невыполненных задач, false - можно делать)"
 // @Param date query string false "Дата в формате YYYY-MM-DD"
+// @Param priority query string false "Приоритет задачи" Enums(none, low, medium, high, urgent)
+// @Param tag query []string false "Названия меток" collectionFormat(multi)
+// @Param tag_match query string false "Режим фильтра по меткам: any - хотя бы одна, all - все" Enums(any, all) default(any)
+// @Param sort query string false "Порядок: due_date - по сроку, priority - по приоритету, затем по сроку" Enums(due_date, priority) default(due_date)
 // @Success 200 {object} models.TasksList
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
 // @Router /tasks [get]
 func List(log *slog.Logger, taskService TaskService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.List"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
 		)
 
-		pageStr := r.URL.Query().Get("page")
-		page, err := strconv.Atoi(pageStr)
-		if err != nil || page < 1 {
-			page = 1
+		filter, err := parseTaskFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
 		}
 
-		limitStr := r.URL.Query().Get("limit")
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit < 1 {
-			limit = 10
-		}
+		listTasks(w, r, log, taskService, filter)
+	}
+}
 
-		var completed *bool
-		completedStr := r.URL.Query().Get("completed")
-		if completedStr != "" {
-			comp, err := strconv.ParseBool(completedStr)
-			if err != nil {
-				log.Error("invalid completed parameter", sl.Err(err))
-				w.WriteHeader(http.StatusBadRequest)
-				render.JSON(w, r, resp.Error("invalid completed parameter"))
-				return
-			}
-			completed = &comp
+// parseTaskFilter разбирает параметры страницы, фильтров и сортировки
+// списка задач. Текст ошибки предназначен для клиента.
+func parseTaskFilter(query url.Values) (models.TaskFilter, error) {
+	filter := models.TaskFilter{
+		Page:     1,
+		Limit:    10,
+		TagMatch: models.TagMatchAny,
+		Sort:     models.SortByDueDate,
+	}
+
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page >= 1 {
+		filter.Page = page
+	}
+
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 1 {
+		filter.Limit = limit
+	}
+
+	if completedStr := query.Get("completed"); completedStr != "" {
+		completed, err := strconv.ParseBool(completedStr)
+		if err != nil {
+			return filter, errors.New("invalid completed parameter")
 		}
+		filter.Completed = &completed
+	}
 
-		var date *time.Time
-		dateStr := r.URL.Query().Get("date")
-		if dateStr != "" {
-			parsedDate, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
-			if err != nil {
-				log.Error("invalid date format", sl.Err(err))
-				w.WriteHeader(http.StatusBadRequest)
-				render.JSON(w, r, resp.Error("invalid date format, use YYYY-MM-DD"))
-				return
-			}
-			date = &parsedDate
+	for _, value := range query["status"] {
+		status := models.TaskStatus(value)
+		if !status.Valid() {
+			return filter, errors.New("invalid status parameter")
+		}
+		if !slices.Contains(filter.Statuses, status) {
+			filter.Statuses = append(filter.Statuses, status)
 		}
+	}
 
-		log.Info("parsed query parameters",
-			slog.Int("page", page),
-			slog.Int("limit", limit),
-			slog.Any("completed", completed),
-			slog.Any("date", date),
-		)
+	if blockedStr := query.Get("blocked"); blockedStr != "" {
+		blocked, err := strconv.ParseBool(blockedStr)
+		if err != nil {
+			return filter, errors.New("invalid blocked parameter")
+		}
+		filter.Blocked = &blocked
+	}
 
-		tasksList, err := taskService.List(page, limit, completed, date)
+	if dateStr := query.Get("date"); dateStr != "" {
+		date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
 		if err != nil {
-			log.Error("failed to list tasks", sl.Err(err))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("failed to list tasks"))
-			return
+			return filter, errors.New("invalid date format, use YYYY-MM-DD")
 		}
+		filter.Date = &date
+	}
 
-		log.Info("tasks retrieved", slog.Int64("total", tasksList.Total))
-		w.WriteHeader(http.StatusOK)
-		render.JSON(w, r, tasksList)
+	if priorityStr := query.Get("priority"); priorityStr != "" {
+		priority := models.Priority(priorityStr)
+		if !priority.Valid() {
+			return filter, errors.New("invalid priority parameter")
+		}
+		filter.Priority = &priority
+	}
+
+	filter.Tags = tagFilter(query["tag"])
+
+	switch tagMatch := models.TagMatch(query.Get("tag_match")); tagMatch {
+	case "":
+	case models.TagMatchAny, models.TagMatchAll:
+		filter.TagMatch = tagMatch
+	default:
+		return filter, errors.New("invalid tag_match parameter, use any or all")
+	}
+
+	switch sort := models.TaskSort(query.Get("sort")); sort {
+	case "":
+	case models.SortByDueDate, models.SortByPriority:
+		filter.Sort = sort
+	default:
+		return filter, errors.New("invalid sort parameter, use due_date or priority")
+	}
+
+	return filter, nil
+}
+
+// listTasks отдаёт клиенту страницу задач по фильтру.
+func listTasks(w http.ResponseWriter, r *http.Request, log *slog.Logger, taskService TaskService, filter models.TaskFilter) {
+	log.Info("parsed query parameters",
+		slog.Int("page", filter.Page),
+		slog.Int("limit", filter.Limit),
+		slog.Any("completed", filter.Completed),
+		slog.Any("statuses", filter.Statuses),
+		slog.Any("blocked", filter.Blocked),
+		slog.Any("date", filter.Date),
+		slog.Any("priority", filter.Priority),
+		slog.Any("tags", filter.Tags),
+		slog.String("tag_match", string(filter.TagMatch)),
+		slog.Any("project_id", filter.ProjectID),
+		slog.String("sort", string(filter.Sort)),
+	)
+
+	tasksList, err := taskService.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, log, err, "failed to list tasks")
+		return
 	}
+
+	log.Info("tasks retrieved", slog.Int64("total", tasksList.Total))
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, tasksList)
+}
+
+// writeTaskError отвечает клиенту по ошибке операции над задачей с кодом,
+// который подбирает taskErrorResponse; остальные ошибки обрабатывает
+// writeError.
+func writeTaskError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, id int64, msg string) {
+	status, text := taskErrorResponse(err)
+	if status == 0 {
+		writeError(w, r, log, err, msg)
+		return
+	}
+
+	log.Info(text, slog.Int64("id", id), sl.Err(err))
+	w.WriteHeader(status)
+	render.JSON(w, r, resp.Error(text))
+}
+
+// taskErrorResponse возвращает HTTP-код и сообщение для клиента по ошибке
+// операции над задачей: 404, если задачи, блокирующей задачи или
+// зависимости нет, 412 при несовпадении версии, 400, если задача
+// ссылается на несуществующий проект или родителя, 409 при цикле в
+// иерархии или зависимостях, при открытых подзадачах, при недопустимой
+// смене статуса и если у задачи нет повторений. Для прочих ошибок
+// возвращается нулевой код.
+func taskErrorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, storage.ErrTaskNotFound):
+		return http.StatusNotFound, "task not found"
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, "task version mismatch"
+	case errors.Is(err, storage.ErrProjectNotFound):
+		return http.StatusBadRequest, "project not found"
+	case errors.Is(err, storage.ErrParentNotFound):
+		return http.StatusBadRequest, "parent task not found"
+	case errors.Is(err, storage.ErrTaskCycle):
+		return http.StatusConflict, "task cannot be nested under itself or its subtask"
+	case errors.Is(err, storage.ErrOpenSubtasks):
+		return http.StatusConflict, "task has open subtasks, use children=complete"
+	case errors.Is(err, storage.ErrBlockerNotFound):
+		return http.StatusNotFound, "blocker task not found"
+	case errors.Is(err, storage.ErrDependencyNotFound):
+		return http.StatusNotFound, "dependency not found"
+	case errors.Is(err, storage.ErrDependencyCycle):
+		return http.StatusConflict, "dependency cycle, the blocker already waits for this task"
+	case errors.Is(err, models.ErrInvalidTransition):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, models.ErrNotRecurring):
+		return http.StatusConflict, "task is not recurring"
+	case errors.Is(err, models.ErrSeriesEnded):
+		return http.StatusConflict, "recurrence has no more occurrences, end it instead"
+	default:
+		return 0, ""
+	}
+}
+
+// validateTask проверяет задачу по правилам validator и нормализует
+// правило повторения. Текст ошибки предназначен для клиента.
+func validateTask(task *models.Task) error {
+	if err := validator.New().Struct(task); err != nil {
+		var validateErr validator.ValidationErrors
+		if !errors.As(err, &validateErr) {
+			return err
+		}
+		return errors.New(resp.ValidatorError(validateErr).Error)
+	}
+
+	return normalizeRecurrence(task)
+}
+
+// writeError отвечает 504, если истёк таймаут запроса к хранилищу, и 500
+// с сообщением msg в остальных случаях. Если клиент отключился, ответ не
+// отправляется.
+func writeError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, msg string) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Error("storage request timed out", sl.Err(err))
+		w.WriteHeader(http.StatusGatewayTimeout)
+		render.JSON(w, r, resp.Error("request timed out"))
+	case errors.Is(err, context.Canceled):
+		log.Info("request canceled by client", sl.Err(err))
+	default:
+		log.Error(msg, sl.Err(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error(msg))
+	}
+}
+
+// tagFilter возвращает названия меток из параметров tag без пустых
+// значений и повторов: хранилища ожидают уникальные названия.
+func tagFilter(values []string) []string {
+	var names []string
+	for _, value := range values {
+		if value != "" && !slices.Contains(names, value) {
+			names = append(names, value)
+		}
+	}
+	return names
+}
+
+// refID возвращает идентификатор проекта или родителя задачи либо 0, если
+// ссылки нет.
+func refID(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// sortedTagNames возвращает упорядоченные названия меток для сравнения
+// наборов меток без учёта порядка.
+func sortedTagNames(tags []models.Tag) []string {
+	names := models.TagNames(tags)
+	slices.Sort(names)
+	return names
 }
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -32,6 +33,8 @@ func TestCreateHandler(t *testing.T) {
 		description string
 		due_date    time.Time
 		status      bool
+		rrule       string
+		timezone    string
 		respError   string
 		mockError   error
 		expectCode  int
@@ -42,7 +45,7 @@ func TestCreateHandler(t *testing.T) {
 			description: "test_decription",
 			due_date:    time.Now(),
 			status:      false,
-			expectCode:  http.StatusOK,
+			expectCode:  http.StatusCreated,
 		},
 		{
 			name:        "Success status true",
@@ -50,7 +53,7 @@ func TestCreateHandler(t *testing.T) {
 			description: "test_decription",
 			due_date:    time.Now(),
 			status:      true,
-			expectCode:  http.StatusOK,
+			expectCode:  http.StatusCreated,
 		},
 		{
 			name:        "Empty title",
@@ -67,7 +70,7 @@ func TestCreateHandler(t *testing.T) {
 			description: "",
 			due_date:    time.Now(),
 			status:      false,
-			expectCode:  http.StatusOK,
+			expectCode:  http.StatusCreated,
 		},
 		{
 			name:        "Empty due_date",
@@ -82,7 +85,7 @@ func TestCreateHandler(t *testing.T) {
 			title:       "test_title",
 			description: "test_decription",
 			due_date:    time.Now(),
-			expectCode:  http.StatusOK,
+			expectCode:  http.StatusCreated,
 		},
 		{
 			name:        "Database error",
@@ -94,6 +97,31 @@ func TestCreateHandler(t *testing.T) {
 			respError:   "failed to create task",
 			expectCode:  http.StatusInternalServerError,
 		},
+		{
+			name:       "Success recurring",
+			title:      "test_title",
+			due_date:   time.Now(),
+			rrule:      "freq=weekly;byday=mo",
+			timezone:   "Europe/Moscow",
+			expectCode: http.StatusCreated,
+		},
+		{
+			name:       "Invalid rrule",
+			title:      "test_title",
+			due_date:   time.Now(),
+			rrule:      "FREQ=HOURLY",
+			respError:  `invalid rrule: FREQ: unsupported frequency "HOURLY"`,
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid timezone",
+			title:      "test_title",
+			due_date:   time.Now(),
+			rrule:      "FREQ=DAILY",
+			timezone:   "Mars/Olympus",
+			respError:  "field timezone is not valid",
+			expectCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range cases {
@@ -105,8 +133,24 @@ func TestCreateHandler(t *testing.T) {
 			taskCreaterMock := mocks.NewTaskService(t)
 
 			if tc.respError == "" || tc.mockError != nil {
-				taskCreaterMock.On("CreateTask", mock.AnythingOfType("models.Task")).
-					Return(tc.mockError).
+				var created *models.Task
+				if tc.mockError == nil {
+					created = &models.Task{
+						ID:          42,
+						Title:       tc.title,
+						Description: tc.description,
+						DueDate:     tc.due_date,
+						Status:      models.StatusTodo,
+					}
+					if tc.status {
+						created.Status = models.StatusDone
+					}
+				}
+				// Правило повторения сохраняется в каноническом виде.
+				taskCreaterMock.On("CreateTask", mock.Anything, mock.MatchedBy(func(task models.Task) bool {
+					return tc.rrule == "" || task.RRule == "FREQ=WEEKLY;BYDAY=MO"
+				})).
+					Return(created, tc.mockError).
 					Once()
 			}
 
@@ -118,11 +162,15 @@ func TestCreateHandler(t *testing.T) {
 				Description string    `json:"description"`
 				DueDate     time.Time `json:"due_date"`
 				Status      bool      `json:"status"`
+				RRule       string    `json:"rrule"`
+				Timezone    string    `json:"timezone"`
 			}{
 				Title:       tc.title,
 				Description: tc.description,
 				DueDate:     tc.due_date,
 				Status:      tc.status,
+				RRule:       tc.rrule,
+				Timezone:    tc.timezone,
 			}
 
 			jsonInput, err := json.Marshal(input)
@@ -142,6 +190,15 @@ func TestCreateHandler(t *testing.T) {
 
 			require.Equal(t, tc.respError, resp.Error)
 
+			if tc.expectCode == http.StatusCreated {
+				require.Equal(t, "/tasks/42", rr.Header().Get("Location"))
+
+				data, ok := resp.Data.(map[string]interface{})
+				require.True(t, ok)
+				require.EqualValues(t, 42, data["id"])
+				require.Equal(t, tc.title, data["title"])
+			}
+
 			taskCreaterMock.AssertExpectations(t)
 		})
 	}
@@ -155,14 +212,16 @@ func TestGetByIDHandler(t *testing.T) {
 		Title:       "test_title",
 		Description: "test_description",
 		DueDate:     now,
-		Status:      false,
+		Status:      models.StatusTodo,
 		CreatedAt:   now,
 		UpdatedAt:   now,
+		Version:     3,
 	}
 
 	cases := []struct {
 		name         string
 		id           string
+		ifNoneMatch  string
 		mockResp     *models.Task
 		mockError    error
 		expectedCode int
@@ -174,6 +233,20 @@ func TestGetByIDHandler(t *testing.T) {
 			mockResp:     task,
 			expectedCode: http.StatusOK,
 		},
+		{
+			name:         "If-None-Match stale",
+			id:           "1",
+			ifNoneMatch:  `"2"`,
+			mockResp:     task,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "If-None-Match current",
+			id:           "1",
+			ifNoneMatch:  `"1", W/"3"`,
+			mockResp:     task,
+			expectedCode: http.StatusNotModified,
+		},
 		{
 			name:         "Not Found",
 			id:           "2",
@@ -194,6 +267,13 @@ func TestGetByIDHandler(t *testing.T) {
 			expectedCode: http.StatusInternalServerError,
 			expectedErr:  "failed to get task",
 		},
+		{
+			name:         "Storage timeout",
+			id:           "4",
+			mockError:    fmt.Errorf("storage.postgres.GetByID: %w", context.DeadlineExceeded),
+			expectedCode: http.StatusGatewayTimeout,
+			expectedErr:  "request timed out",
+		},
 	}
 
 	for _, tc := range cases {
@@ -207,7 +287,7 @@ func TestGetByIDHandler(t *testing.T) {
 			if tc.mockResp != nil || tc.mockError != nil {
 				id, err := strconv.Atoi(tc.id)
 				if err == nil {
-					taskGetterMock.On("GetByID", uint(id)).
+					taskGetterMock.On("GetByID", mock.Anything, uint(id)).
 						Return(tc.mockResp, tc.mockError).
 						Once()
 				}
@@ -217,6 +297,9 @@ func TestGetByIDHandler(t *testing.T) {
 			handler := handlers.GetByID(logger, taskGetterMock)
 
 			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%s", tc.id), nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
 			rctx := chi.NewRouteContext()
 			rctx.URLParams.Add("id", tc.id)
 
@@ -233,6 +316,13 @@ func TestGetByIDHandler(t *testing.T) {
 				require.Contains(t, rr.Body.String(), tc.expectedErr)
 			}
 
+			if tc.mockResp != nil {
+				require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+			}
+			if tc.expectedCode == http.StatusNotModified {
+				require.Empty(t, rr.Body.String())
+			}
+
 			taskGetterMock.AssertExpectations(t)
 		})
 	}
@@ -246,18 +336,20 @@ func TestUpdateTaskHandler(t *testing.T) {
 		Title:       "updated_title",
 		Description: "updated_description",
 		DueDate:     now,
-		Status:      true,
+		Status:      models.StatusDone,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 
 	cases := []struct {
-		name       string
-		id         string
-		task       *models.Task
-		mockError  error
-		respError  string
-		expectCode int
+		name          string
+		id            string
+		ifMatch       string
+		task          *models.Task
+		expectVersion int64
+		mockError     error
+		respError     string
+		expectCode    int
 	}{
 		{
 			name:       "Success status true",
@@ -265,6 +357,32 @@ func TestUpdateTaskHandler(t *testing.T) {
 			task:       task,
 			expectCode: http.StatusOK,
 		},
+		{
+			name:          "Success with If-Match",
+			id:            "1",
+			ifMatch:       `"4"`,
+			task:          task,
+			expectVersion: 4,
+			expectCode:    http.StatusOK,
+		},
+		{
+			name:          "Version mismatch",
+			id:            "1",
+			ifMatch:       `"4"`,
+			task:          task,
+			expectVersion: 4,
+			mockError:     storage.ErrVersionMismatch,
+			respError:     "task version mismatch",
+			expectCode:    http.StatusPreconditionFailed,
+		},
+		{
+			name:       "Weak If-Match never matches",
+			id:         "1",
+			ifMatch:    `W/"4"`,
+			task:       task,
+			respError:  "task version mismatch",
+			expectCode: http.StatusPreconditionFailed,
+		},
 		{
 			name: "Success status false",
 			id:   "1",
@@ -273,7 +391,7 @@ func TestUpdateTaskHandler(t *testing.T) {
 				Title:       "updated_title",
 				Description: "updated_description",
 				DueDate:     now,
-				Status:      false,
+				Status:      models.StatusTodo,
 				CreatedAt:   now,
 				UpdatedAt:   now,
 			},
@@ -294,7 +412,7 @@ func TestUpdateTaskHandler(t *testing.T) {
 				Title:       "",
 				Description: "updated_description",
 				DueDate:     now,
-				Status:      true,
+				Status:      models.StatusDone,
 				CreatedAt:   now,
 				UpdatedAt:   now,
 			},
@@ -309,7 +427,7 @@ func TestUpdateTaskHandler(t *testing.T) {
 				Title:       "updated_title",
 				Description: "updated_description",
 				DueDate:     time.Time{},
-				Status:      true,
+				Status:      models.StatusDone,
 				CreatedAt:   now,
 				UpdatedAt:   now,
 			},
@@ -343,7 +461,9 @@ func TestUpdateTaskHandler(t *testing.T) {
 			taskServiceMock := mocks.NewTaskService(t)
 
 			if tc.expectCode == http.StatusOK || tc.mockError != nil {
-				taskServiceMock.On("UpdateTask", mock.AnythingOfType("*models.Task")).
+				taskServiceMock.On("UpdateTask", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					return task.Version == tc.expectVersion
+				}), models.SubtasksBlock).
 					Return(tc.mockError).
 					Once()
 			}
@@ -355,6 +475,9 @@ func TestUpdateTaskHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/tasks/%s", tc.id), bytes.NewReader(jsonInput))
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
 			rctx := chi.NewRouteContext()
 			rctx.URLParams.Add("id", tc.id)
 
@@ -379,19 +502,300 @@ func TestUpdateTaskHandler(t *testing.T) {
 	}
 }
 
+func TestPatchTaskHandler(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	current := &models.Task{
+		ID:          1,
+		Title:       "test_title",
+		Description: "test_description",
+		DueDate:     now,
+		Status:      models.StatusTodo,
+		Priority:    models.PriorityNone,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Tags:        []models.Tag{{ID: 1, Name: "backend"}},
+	}
+
+	cases := []struct {
+		name        string
+		id          string
+		contentType string
+		body        string
+		getError    error
+		expectPatch *models.TaskPatch
+		mockError   error
+		respError   string
+		expectCode  int
+	}{
+		{
+			name:        "Merge patch status",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": true}`,
+			expectPatch: &models.TaskPatch{Status: statusPtr(models.StatusDone)},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Merge patch priority",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"priority": "urgent"}`,
+			expectPatch: &models.TaskPatch{Priority: priorityPtr(models.PriorityUrgent)},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "JSON patch add tag",
+			id:          "1",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "add", "path": "/tags/-", "value": {"name": "errand"}}]`,
+			expectPatch: &models.TaskPatch{Tags: &[]models.Tag{{ID: 1, Name: "backend"}, {Name: "errand"}}},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Same tag names are not a change",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"tags": [{"name": "backend"}]}`,
+			expectPatch: &models.TaskPatch{},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Merge patch project",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"project_id": 3}`,
+			expectPatch: &models.TaskPatch{ProjectID: int64Ptr(3)},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Unknown project",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"project_id": 42}`,
+			expectPatch: &models.TaskPatch{ProjectID: int64Ptr(42)},
+			mockError:   storage.ErrProjectNotFound,
+			respError:   "project not found",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Open subtasks",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": true}`,
+			expectPatch: &models.TaskPatch{Status: statusPtr(models.StatusDone)},
+			mockError:   storage.ErrOpenSubtasks,
+			respError:   "task has open subtasks, use children=complete",
+			expectCode:  http.StatusConflict,
+		},
+		{
+			name:        "Invalid transition",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": "cancelled"}`,
+			expectPatch: &models.TaskPatch{Status: statusPtr(models.StatusCancelled)},
+			mockError:   fmt.Errorf("%w from done to cancelled", models.ErrInvalidTransition),
+			respError:   "invalid status transition from done to cancelled",
+			expectCode:  http.StatusConflict,
+		},
+		{
+			name:        "Parent cycle",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"parent_id": 5}`,
+			expectPatch: &models.TaskPatch{ParentID: int64Ptr(5)},
+			mockError:   storage.ErrTaskCycle,
+			respError:   "task cannot be nested under itself or its subtask",
+			expectCode:  http.StatusConflict,
+		},
+		{
+			name:        "Invalid priority",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"priority": "critical"}`,
+			respError:   "field priority is not valid",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Plain JSON treated as merge patch",
+			id:          "1",
+			contentType: "application/json",
+			body:        `{"description": null}`,
+			expectPatch: &models.TaskPatch{Description: strPtr("")},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "JSON patch replace title",
+			id:          "1",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/title", "value": "test_title"}, {"op": "replace", "path": "/title", "value": "new_title"}]`,
+			expectPatch: &models.TaskPatch{Title: strPtr("new_title")},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Read-only fields are ignored",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"id": 5, "created_at": "2000-01-01T00:00:00Z"}`,
+			expectPatch: &models.TaskPatch{},
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "JSON patch test failed",
+			id:          "1",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/title", "value": "other"}]`,
+			respError:   "patch test failed",
+			expectCode:  http.StatusConflict,
+		},
+		{
+			name:        "Merged result is validated",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"title": null}`,
+			respError:   "field title is a required field",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid patch",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": 1}`,
+			respError:   "invalid patch",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid status",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": "yes"}`,
+			respError:   "field status is not valid",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Unsupported content type",
+			id:          "1",
+			contentType: "text/plain",
+			body:        `status=true`,
+			respError:   "unsupported content type",
+			expectCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "Invalid ID format",
+			id:          "abc",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": true}`,
+			respError:   "invalid id",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Task not found",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": true}`,
+			getError:    storage.ErrTaskNotFound,
+			respError:   "task not found",
+			expectCode:  http.StatusNotFound,
+		},
+		{
+			name:        "Internal error",
+			id:          "1",
+			contentType: "application/merge-patch+json",
+			body:        `{"status": true}`,
+			expectPatch: &models.TaskPatch{Status: statusPtr(models.StatusDone)},
+			mockError:   errors.New("database error"),
+			respError:   "failed to update task",
+			expectCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taskServiceMock := mocks.NewTaskService(t)
+
+			if tc.id == "1" && tc.expectCode != http.StatusUnsupportedMediaType {
+				if tc.getError != nil {
+					taskServiceMock.On("GetByID", mock.Anything, uint(1)).Return(nil, tc.getError).Once()
+				} else {
+					task := *current
+					taskServiceMock.On("GetByID", mock.Anything, uint(1)).Return(&task, nil).Once()
+				}
+			}
+
+			if tc.expectPatch != nil {
+				var patched *models.Task
+				if tc.mockError == nil {
+					patched = current
+				}
+				patch := *tc.expectPatch
+				patch.Children = models.SubtasksBlock
+				taskServiceMock.On("PatchTask", mock.Anything, uint(1), patch).
+					Return(patched, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.PatchTask(logger, taskServiceMock)
+
+			req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", tc.id), bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Content-Type", tc.contentType)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tc.id)
+
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp handlers.Response
+			err := json.Unmarshal(rr.Body.Bytes(), &resp)
+			require.NoError(t, err)
+			require.Equal(t, tc.respError, resp.Error)
+
+			taskServiceMock.AssertExpectations(t)
+		})
+	}
+}
+
 func TestDeleteTaskHandler(t *testing.T) {
 	cases := []struct {
-		name       string
-		id         string
-		mockError  error
-		respError  string
-		expectCode int
+		name          string
+		id            string
+		ifMatch       string
+		expectVersion int64
+		mockError     error
+		respError     string
+		expectCode    int
 	}{
 		{
 			name:       "Success",
 			id:         "1",
 			expectCode: http.StatusOK,
 		},
+		{
+			name:          "Success with If-Match",
+			id:            "1",
+			ifMatch:       `"2"`,
+			expectVersion: 2,
+			expectCode:    http.StatusOK,
+		},
+		{
+			name:          "Version mismatch",
+			id:            "1",
+			ifMatch:       `"2"`,
+			expectVersion: 2,
+			mockError:     storage.ErrVersionMismatch,
+			respError:     "task version mismatch",
+			expectCode:    http.StatusPreconditionFailed,
+		},
 		{
 			name:       "Invalid ID format",
 			id:         "abc",
@@ -425,7 +829,7 @@ func TestDeleteTaskHandler(t *testing.T) {
 			if tc.mockError != nil || tc.expectCode == http.StatusOK {
 				id, err := strconv.Atoi(tc.id)
 				if err == nil {
-					taskServiceMock.On("DeleteTask", uint(id)).
+					taskServiceMock.On("DeleteTask", mock.Anything, uint(id), tc.expectVersion).
 						Return(tc.mockError).
 						Once()
 				}
@@ -435,6 +839,9 @@ func TestDeleteTaskHandler(t *testing.T) {
 			handler := handlers.DeleteTask(logger, taskServiceMock)
 
 			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%s", tc.id), nil)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
 			rctx := chi.NewRouteContext()
 			rctx.URLParams.Add("id", tc.id)
 
@@ -467,7 +874,7 @@ func TestListTasksHandler(t *testing.T) {
 		Title:       "test_title",
 		Description: "test_description",
 		DueDate:     date,
-		Status:      false,
+		Status:      models.StatusTodo,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -484,7 +891,13 @@ func TestListTasksHandler(t *testing.T) {
 		page        int
 		limit       int
 		completed   *bool
+		blocked     *bool
+		statuses    []models.TaskStatus
 		date        *time.Time
+		priority    *models.Priority
+		tags        []string
+		tagMatch    models.TagMatch
+		sort        models.TaskSort
 		mockResp    *models.TasksList
 		mockError   error
 		respError   string
@@ -510,6 +923,24 @@ func TestListTasksHandler(t *testing.T) {
 			mockResp:    tasksList,
 			expectCode:  http.StatusOK,
 		},
+		{
+			name:        "Success with blocked false",
+			queryParams: "blocked=false",
+			page:        1,
+			limit:       10,
+			blocked:     boolPtr(false),
+			mockResp:    tasksList,
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Success with statuses",
+			queryParams: "status=in_progress&status=blocked&status=in_progress",
+			page:        1,
+			limit:       10,
+			statuses:    []models.TaskStatus{models.StatusInProgress, models.StatusBlocked},
+			mockResp:    tasksList,
+			expectCode:  http.StatusOK,
+		},
 		{
 			name:        "Success with date filter",
 			queryParams: "page=2&limit=10&date=2025-04-17",
@@ -540,12 +971,79 @@ func TestListTasksHandler(t *testing.T) {
 			mockResp:    tasksList,
 			expectCode:  http.StatusOK,
 		},
+		{
+			name:        "Success with priority filter",
+			queryParams: "priority=high",
+			page:        1,
+			limit:       10,
+			priority:    priorityPtr(models.PriorityHigh),
+			mockResp:    tasksList,
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Success sorted by priority",
+			queryParams: "sort=priority",
+			page:        1,
+			limit:       10,
+			sort:        models.SortByPriority,
+			mockResp:    tasksList,
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Success with tags",
+			queryParams: "tag=backend&tag=errand&tag=backend",
+			page:        1,
+			limit:       10,
+			tags:        []string{"backend", "errand"},
+			mockResp:    tasksList,
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Success with all tags",
+			queryParams: "tag=backend&tag=errand&tag_match=all",
+			page:        1,
+			limit:       10,
+			tags:        []string{"backend", "errand"},
+			tagMatch:    models.TagMatchAll,
+			mockResp:    tasksList,
+			expectCode:  http.StatusOK,
+		},
+		{
+			name:        "Invalid tag_match",
+			queryParams: "tag=backend&tag_match=some",
+			respError:   "invalid tag_match parameter, use any or all",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid priority",
+			queryParams: "priority=critical",
+			respError:   "invalid priority parameter",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid sort",
+			queryParams: "sort=title",
+			respError:   "invalid sort parameter, use due_date or priority",
+			expectCode:  http.StatusBadRequest,
+		},
 		{
 			name:        "Invalid completed",
 			queryParams: "completed=invalid",
 			respError:   "invalid completed parameter",
 			expectCode:  http.StatusBadRequest,
 		},
+		{
+			name:        "Invalid status",
+			queryParams: "status=archived",
+			respError:   "invalid status parameter",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid blocked",
+			queryParams: "blocked=maybe",
+			respError:   "invalid blocked parameter",
+			expectCode:  http.StatusBadRequest,
+		},
 		{
 			name:        "Invalid date format",
 			queryParams: "date=2025-13-01",
@@ -563,6 +1061,15 @@ func TestListTasksHandler(t *testing.T) {
 			respError:   "failed to list tasks",
 			expectCode:  http.StatusInternalServerError,
 		},
+		{
+			name:        "Storage timeout",
+			queryParams: "page=1&limit=10",
+			page:        1,
+			limit:       10,
+			mockError:   fmt.Errorf("storage.postgres.List: %w", context.DeadlineExceeded),
+			respError:   "request timed out",
+			expectCode:  http.StatusGatewayTimeout,
+		},
 	}
 
 	for _, tc := range cases {
@@ -574,7 +1081,26 @@ func TestListTasksHandler(t *testing.T) {
 			taskServiceMock := mocks.NewTaskService(t)
 
 			if tc.mockResp != nil || tc.mockError != nil {
-				taskServiceMock.On("List", tc.page, tc.limit, tc.completed, mock.AnythingOfType("*time.Time")).
+				sort := tc.sort
+				if sort == "" {
+					sort = models.SortByDueDate
+				}
+				tagMatch := tc.tagMatch
+				if tagMatch == "" {
+					tagMatch = models.TagMatchAny
+				}
+				filter := mock.MatchedBy(func(f models.TaskFilter) bool {
+					return f.Page == tc.page &&
+						f.Limit == tc.limit &&
+						assert.ObjectsAreEqual(tc.completed, f.Completed) &&
+						assert.ObjectsAreEqual(tc.blocked, f.Blocked) &&
+						assert.ObjectsAreEqual(tc.statuses, f.Statuses) &&
+						assert.ObjectsAreEqual(tc.priority, f.Priority) &&
+						assert.ObjectsAreEqual(tc.tags, f.Tags) &&
+						f.TagMatch == tagMatch &&
+						f.Sort == sort
+				})
+				taskServiceMock.On("List", mock.Anything, filter).
 					Return(tc.mockResp, tc.mockError).
 					Once()
 			}
@@ -625,3 +1151,19 @@ func TestListTasksHandler(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func statusPtr(s models.TaskStatus) *models.TaskStatus {
+	return &s
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func priorityPtr(p models.Priority) *models.Priority {
+	return &p
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	resp "todo/internal/lib/api/response"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+//go:generate mockery --name=WebhookService --output=mocks --outpkg=mocks
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, webhookID uint, filter models.DeliveryFilter) (*models.DeliveryList, error)
+}
+
+// CreateWebhook godoc
+// @Summary Подписаться на события задач
+// @Description Создать подписку: события task.created, task.updated, task.completed и task.deleted отправляются POST-запросом на url с подписью HMAC-SHA256 тела в заголовке X-Todo-Signature. Ключ подписи возвращается только в ответе на этот запрос.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body models.Webhook true "Адрес, ключ подписи и события"
+// @Success 201 {object} handlers.Response{data=models.Webhook}
+// @Header 201 {string} Location "/webhooks/{id}"
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /webhooks [post]
+func CreateWebhook(log *slog.Logger, webhookService WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.CreateWebhook"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		var req models.Webhook
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidatorError(validateErr))
+			return
+		}
+
+		req.Events = models.SortEvents(req.Events)
+		if req.Secret == "" {
+			if req.Secret, err = newWebhookSecret(); err != nil {
+				writeError(w, r, log, err, "failed to create webhook")
+				return
+			}
+		}
+
+		webhook, err := webhookService.CreateWebhook(r.Context(), req)
+		if err != nil {
+			writeError(w, r, log, err, "failed to create webhook")
+			return
+		}
+
+		log.Info("webhook created", slog.Int64("id", webhook.ID), slog.String("host", webhookHost(webhook.URL)))
+
+		w.Header().Set("Location", fmt.Sprintf("/webhooks/%d", webhook.ID))
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   webhook,
+		})
+	}
+}
+
+// ListWebhooks godoc
+// @Summary Получить список подписок
+// @Description Получить подписки на события задач в порядке создания, без ключей подписи
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} handlers.Response{data=[]models.Webhook}
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /webhooks [get]
+func ListWebhooks(log *slog.Logger, webhookService WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ListWebhooks"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		webhooks, err := webhookService.ListWebhooks(r.Context())
+		if err != nil {
+			writeError(w, r, log, err, "failed to list webhooks")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   webhooks,
+		})
+	}
+}
+
+// GetWebhook godoc
+// @Summary Получить подписку по ID
+// @Description Получить подписку без ключа подписи
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Success 200 {object} handlers.Response{data=models.Webhook}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /webhooks/{id} [get]
+func GetWebhook(log *slog.Logger, webhookService WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.GetWebhook"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		webhook, err := webhookService.GetWebhook(r.Context(), uint(id))
+		if err != nil {
+			writeWebhookError(w, r, log, err, id, "failed to get webhook")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+			Data:   webhook,
+		})
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить подписку
+// @Description Удалить подписку вместе с журналом доставок; неотправленные события больше не доставляются
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Success 200 {object} handlers.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(log *slog.Logger, webhookService WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.DeleteWebhook"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		if err := webhookService.DeleteWebhook(r.Context(), uint(id)); err != nil {
+			writeWebhookError(w, r, log, err, id, "failed to delete webhook")
+			return
+		}
+
+		log.Info("webhook deleted", slog.Int64("id", id))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, Response{
+			Status: "OK",
+		})
+	}
+}
+
+// ListDeliveries godoc
+// @Summary Журнал доставок подписки
+// @Description Получить доставки событий подписке, новые первыми: состояние, число попыток, код ответа и ошибку последней попытки
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Param status query string false "Состояние доставки" Enums(pending, delivered, failed)
+// @Success 200 {object} models.DeliveryList
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 504 {object} response.Response
+// @Router /webhooks/{id}/deliveries [get]
+func ListDeliveries(log *slog.Logger, webhookService WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ListDeliveries"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("failed to parse id", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id"))
+			return
+		}
+
+		filter, err := parseDeliveryFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", sl.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		deliveries, err := webhookService.ListDeliveries(r.Context(), uint(id), filter)
+		if err != nil {
+			writeWebhookError(w, r, log, err, id, "failed to list deliveries")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, deliveries)
+	}
+}
+
+// parseDeliveryFilter разбирает параметры страницы журнала доставок. Текст
+// ошибки предназначен для клиента.
+func parseDeliveryFilter(query url.Values) (models.DeliveryFilter, error) {
+	filter := models.DeliveryFilter{
+		Page:  1,
+		Limit: 10,
+	}
+
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page >= 1 {
+		filter.Page = page
+	}
+
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 1 {
+		filter.Limit = limit
+	}
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		status := models.DeliveryStatus(statusStr)
+		if !status.Valid() {
+			return filter, errors.New("invalid status parameter, use pending, delivered or failed")
+		}
+		filter.Status = &status
+	}
+
+	return filter, nil
+}
+
+// newWebhookSecret генерирует ключ подписи: 32 случайных байта в hex.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// webhookHost возвращает хост адреса подписки для журнала: путь и запрос
+// могут содержать токены.
+func webhookHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// writeWebhookError отвечает 404, если подписки нет, остальные ошибки
+// обрабатывает writeError.
+func writeWebhookError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, id int64, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrWebhookNotFound):
+		log.Info("webhook not found", slog.Int64("id", id))
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error("webhook not found"))
+	default:
+		writeError(w, r, log, err, msg)
+	}
+}
@@ -0,0 +1,244 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func TestCreateWebhookHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		body         string
+		expectEvents []models.WebhookEventType
+		expectSecret string
+		mockError    error
+		respError    string
+		expectCode   int
+	}{
+		{
+			name:         "All events",
+			body:         `{"url": "https://ci.example.com/hooks"}`,
+			expectEvents: models.WebhookEventTypes,
+			expectCode:   http.StatusCreated,
+		},
+		{
+			name:         "Event filter",
+			body:         `{"url": "https://ci.example.com/hooks", "secret": "0123456789abcdef", "events": ["task.deleted", "task.created", "task.deleted"]}`,
+			expectEvents: []models.WebhookEventType{models.EventTaskCreated, models.EventTaskDeleted},
+			expectSecret: "0123456789abcdef",
+			expectCode:   http.StatusCreated,
+		},
+		{
+			name:       "Missing url",
+			body:       `{"events": ["task.created"]}`,
+			respError:  "field url is a required field",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid url",
+			body:       `{"url": "ftp://ci.example.com/hooks"}`,
+			respError:  "field url is not valid",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown event",
+			body:       `{"url": "https://ci.example.com/hooks", "events": ["task.moved"]}`,
+			respError:  "field events[0] is not valid",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Short secret",
+			body:       `{"url": "https://ci.example.com/hooks", "secret": "short"}`,
+			respError:  "field secret is not valid",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Internal error",
+			body:         `{"url": "https://ci.example.com/hooks"}`,
+			expectEvents: models.WebhookEventTypes,
+			mockError:    errors.New("database error"),
+			respError:    "failed to create webhook",
+			expectCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			webhookServiceMock := mocks.NewWebhookService(t)
+
+			if tc.expectEvents != nil {
+				match := mock.MatchedBy(func(webhook models.Webhook) bool {
+					if tc.expectSecret != "" && webhook.Secret != tc.expectSecret || len(webhook.Secret) < 16 {
+						return false
+					}
+					return webhook.URL == "https://ci.example.com/hooks" && slices.Equal(webhook.Events, tc.expectEvents)
+				})
+
+				var created *models.Webhook
+				if tc.mockError == nil {
+					created = &models.Webhook{ID: 3, URL: "https://ci.example.com/hooks", Secret: "0123456789abcdef", Events: tc.expectEvents}
+				}
+				webhookServiceMock.On("CreateWebhook", mock.Anything, match).
+					Return(created, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.CreateWebhook(logger, webhookServiceMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			var resp struct {
+				Error string         `json:"error"`
+				Data  models.Webhook `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.expectCode == http.StatusCreated {
+				require.Equal(t, "/webhooks/3", rr.Header().Get("Location"))
+				require.Equal(t, "0123456789abcdef", resp.Data.Secret)
+			}
+		})
+	}
+}
+
+func TestGetWebhookHandler(t *testing.T) {
+	webhookServiceMock := mocks.NewWebhookService(t)
+	webhookServiceMock.On("GetWebhook", mock.Anything, uint(3)).
+		Return(nil, storage.ErrWebhookNotFound).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.GetWebhook(logger, webhookServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodGet, "/webhooks/3", nil), "3"))
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Contains(t, rr.Body.String(), "webhook not found")
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	webhookServiceMock := mocks.NewWebhookService(t)
+	webhookServiceMock.On("DeleteWebhook", mock.Anything, uint(3)).
+		Return(nil).
+		Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := handlers.DeleteWebhook(logger, webhookServiceMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, withID(httptest.NewRequest(http.MethodDelete, "/webhooks/3", nil), "3"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestListDeliveriesHandler(t *testing.T) {
+	failed := models.DeliveryFailed
+
+	cases := []struct {
+		name         string
+		query        string
+		expectFilter *models.DeliveryFilter
+		mockError    error
+		respError    string
+		expectCode   int
+	}{
+		{
+			name:         "Defaults",
+			expectFilter: &models.DeliveryFilter{Page: 1, Limit: 10},
+			expectCode:   http.StatusOK,
+		},
+		{
+			name:         "Failed page",
+			query:        "?status=failed&page=2&limit=5",
+			expectFilter: &models.DeliveryFilter{Page: 2, Limit: 5, Status: &failed},
+			expectCode:   http.StatusOK,
+		},
+		{
+			name:       "Invalid status",
+			query:      "?status=lost",
+			respError:  "invalid status parameter, use pending, delivered or failed",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Webhook not found",
+			expectFilter: &models.DeliveryFilter{Page: 1, Limit: 10},
+			mockError:    storage.ErrWebhookNotFound,
+			respError:    "webhook not found",
+			expectCode:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			webhookServiceMock := mocks.NewWebhookService(t)
+
+			if tc.expectFilter != nil {
+				var list *models.DeliveryList
+				if tc.mockError == nil {
+					list = &models.DeliveryList{
+						Data:  []models.WebhookDelivery{{ID: 9, WebhookID: 3, Event: models.EventTaskCreated, Status: models.DeliveryFailed}},
+						Total: 1,
+						Page:  tc.expectFilter.Page,
+						Limit: tc.expectFilter.Limit,
+					}
+				}
+				webhookServiceMock.On("ListDeliveries", mock.Anything, uint(3), *tc.expectFilter).
+					Return(list, tc.mockError).
+					Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := handlers.ListDeliveries(logger, webhookServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/webhooks/3/deliveries"+tc.query, nil)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, withID(req, "3"))
+
+			require.Equal(t, tc.expectCode, rr.Code)
+
+			if tc.expectCode != http.StatusOK {
+				var resp handlers.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+				return
+			}
+
+			var list models.DeliveryList
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			require.EqualValues(t, 1, list.Total)
+			require.Equal(t, int64(9), list.Data[0].ID)
+		})
+	}
+}
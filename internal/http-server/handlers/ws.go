@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/go-chi/chi/middleware"
+
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Типы сообщений протокола /ws. Клиент отправляет subscribe, create,
+// update и delete; сервер отвечает ack или error с тем же id и присылает
+// события подписки как event, а reset — если журнал уже вытеснил события
+// после last_event_id.
+const (
+	wsSubscribe = "subscribe"
+	wsCreate    = "create"
+	wsUpdate    = "update"
+	wsDelete    = "delete"
+	wsAck       = "ack"
+	wsError     = "error"
+	wsEvent     = "event"
+	wsReset     = "reset"
+)
+
+// WebSocketOptions настраивает соединения /ws.
+type WebSocketOptions struct {
+	// PingInterval — период отправки ping клиенту.
+	PingInterval time.Duration
+	// PongTimeout — сколько ждать pong, прежде чем закрыть соединение.
+	PongTimeout time.Duration
+	// WriteTimeout ограничивает запись одного сообщения.
+	WriteTimeout time.Duration
+	// SendBuffer — сколько сообщений может ждать отправки; клиент, который
+	// не успевает их читать, отключается.
+	SendBuffer int
+	// MaxMessageSize — наибольший размер сообщения клиента в байтах.
+	MaxMessageSize int64
+	// OriginPatterns — хосты других источников, которым разрешено
+	// подключение из браузера.
+	OriginPatterns []string
+	// RequireVersion запрещает update и delete без версии задачи, как
+	// http_server.require_if_match для REST.
+	RequireVersion bool
+	// Done закрывается при остановке сервера; соединения закрываются с
+	// кодом 1001.
+	Done <-chan struct{}
+}
+
+// wsRequest — сообщение клиента.
+type wsRequest struct {
+	// ID возвращается в ответе и позволяет сопоставить его с запросом.
+	ID     string       `json:"id,omitempty"`
+	Type   string       `json:"type"`
+	TaskID int64        `json:"task_id,omitempty"`
+	Task   *models.Task `json:"task,omitempty"`
+	// Version — ожидаемая версия задачи для update и delete; 0 — без
+	// проверки.
+	Version     int64    `json:"version,omitempty"`
+	Children    string   `json:"children,omitempty"`
+	Filter      wsFilter `json:"filter"`
+	LastEventID int64    `json:"last_event_id,omitempty"`
+}
+
+// wsFilter — фильтр подписки, те же параметры, что у GET /tasks/events.
+type wsFilter struct {
+	ProjectID *int64   `json:"project_id,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Statuses  []string `json:"statuses,omitempty"`
+}
+
+// wsResponse — сообщение сервера. Code повторяет HTTP-код, который вернул
+// бы соответствующий REST-запрос.
+type wsResponse struct {
+	Type  string            `json:"type"`
+	ID    string            `json:"id,omitempty"`
+	Task  *models.Task      `json:"task,omitempty"`
+	Event *models.TaskEvent `json:"event,omitempty"`
+	Code  int               `json:"code,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+// WebSocket godoc
+// @Summary Синхронизация задач по WebSocket
+// @Description Двунаправленный канал с JSON-сообщениями. Клиент отправляет {"type": "create", "task": {...}}, {"type": "update", "task_id": 1, "version": 3, "task": {...}, "children": "complete"}, {"type": "delete", "task_id": 1, "version": 3} и {"type": "subscribe", "filter": {"project_id": 1, "tags": [...], "statuses": [...]}, "last_event_id": 10}; поле id запроса возвращается в ответе. Сервер отвечает {"type": "ack", "task": {...}} или {"type": "error", "code": 412, "error": "..."}, где code — HTTP-код соответствующего REST-запроса, а события подписки присылает как {"type": "event", "event": {...}}. Новая подписка заменяет прежнюю; reset означает, что события после last_event_id уже вытеснены из журнала. Задачи проверяются по тем же правилам, что в POST /newtask и PUT /tasks/{id}.
+// @Tags tasks
+// @Success 101 "Соединение переключено на WebSocket"
+// @Failure 400 {string} string "Не запрос WebSocket"
+// @Failure 403 {string} string "Источник не разрешён"
+// @Router /ws [get]
+func WebSocket(log *slog.Logger, taskService TaskService, eventService TaskEventService, opts WebSocketOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.WebSocket"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.Trace(r.Context()),
+		)
+
+		// Соединение живёт дольше таймаутов сервера.
+		rc := http.NewResponseController(w)
+		if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Warn("failed to clear read deadline", sl.Err(err))
+		}
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Warn("failed to clear write deadline", sl.Err(err))
+		}
+
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: opts.OriginPatterns})
+		if err != nil {
+			// Accept уже ответил клиенту.
+			log.Error("failed to accept websocket", sl.Err(err))
+			return
+		}
+		conn.SetReadLimit(opts.MaxMessageSize)
+
+		c := &wsConn{
+			log:      log,
+			conn:     conn,
+			tasks:    taskService,
+			events:   eventService,
+			opts:     opts,
+			outgoing: make(chan wsResponse, opts.SendBuffer),
+		}
+
+		log.Info("websocket opened")
+		c.serve(context.WithoutCancel(r.Context()))
+		log.Info("websocket closed")
+	}
+}
+
+// wsConn обслуживает одно соединение /ws. Запросы клиента выполняются по
+// очереди в читающей горутине, так что следующий запрос не читается, пока
+// не выполнен предыдущий. Ответы и события пишет отдельная горутина из
+// очереди outgoing.
+type wsConn struct {
+	log      *slog.Logger
+	conn     *websocket.Conn
+	tasks    TaskService
+	events   TaskEventService
+	opts     WebSocketOptions
+	outgoing chan wsResponse
+
+	closeOnce sync.Once
+
+	// Подписка меняется только в читающей горутине.
+	unsubscribe func()
+}
+
+func (c *wsConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		c.writeLoop(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.pingLoop(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+		case <-c.opts.Done:
+			c.close(websocket.StatusGoingAway, "server is shutting down")
+		}
+	}()
+
+	c.readLoop(ctx)
+
+	c.stopSubscription()
+	cancel()
+	wg.Wait()
+	c.closeOnce.Do(func() {
+		c.conn.Close(websocket.StatusNormalClosure, "")
+	})
+}
+
+func (c *wsConn) readLoop(ctx context.Context) {
+	for {
+		typ, data, err := c.conn.Read(ctx)
+		if err != nil {
+			if status := websocket.CloseStatus(err); status == -1 {
+				c.log.Info("websocket read failed", sl.Err(err))
+			}
+			return
+		}
+
+		if typ != websocket.MessageText {
+			c.reply(wsResponse{Type: wsError, Code: http.StatusBadRequest, Error: "messages must be text"})
+			continue
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.log.Error("failed to decode message", sl.Err(err))
+			c.reply(wsResponse{Type: wsError, Code: http.StatusBadRequest, Error: "failed to decode message"})
+			continue
+		}
+
+		c.handle(ctx, req)
+	}
+}
+
+func (c *wsConn) handle(ctx context.Context, req wsRequest) {
+	log := c.log.With(slog.String("type", req.Type), slog.String("message_id", req.ID))
+
+	switch req.Type {
+	case wsSubscribe:
+		c.subscribe(ctx, log, req)
+	case wsCreate:
+		c.create(ctx, log, req)
+	case wsUpdate:
+		c.update(ctx, log, req)
+	case wsDelete:
+		c.delete(ctx, log, req)
+	default:
+		log.Info("unknown message type")
+		c.fail(req.ID, http.StatusBadRequest, "unknown message type")
+	}
+}
+
+func (c *wsConn) create(ctx context.Context, log *slog.Logger, req wsRequest) {
+	if req.Task == nil {
+		c.fail(req.ID, http.StatusBadRequest, "task is required")
+		return
+	}
+
+	task := *req.Task
+	if err := validateTask(&task); err != nil {
+		log.Info("invalid task", sl.Err(err))
+		c.fail(req.ID, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := c.tasks.CreateTask(ctx, task)
+	if err != nil {
+		c.failTask(log, req, err, "failed to create task")
+		return
+	}
+
+	log.Info("task created", slog.Int64("id", created.ID))
+	c.reply(wsResponse{Type: wsAck, ID: req.ID, Task: created})
+}
+
+func (c *wsConn) update(ctx context.Context, log *slog.Logger, req wsRequest) {
+	if req.TaskID < 1 {
+		c.fail(req.ID, http.StatusBadRequest, "invalid task_id")
+		return
+	}
+	if req.Task == nil {
+		c.fail(req.ID, http.StatusBadRequest, "task is required")
+		return
+	}
+	if req.Version == 0 && c.opts.RequireVersion {
+		c.fail(req.ID, http.StatusPreconditionRequired, "version is required")
+		return
+	}
+
+	policy, err := parseSubtaskPolicy(req.Children)
+	if err != nil {
+		c.fail(req.ID, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task := *req.Task
+	task.ID = req.TaskID
+	task.Version = req.Version
+	if err := validateTask(&task); err != nil {
+		log.Info("invalid task", sl.Err(err))
+		c.fail(req.ID, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Без версии задача перезаписывается, как PUT без If-Match.
+	if task.Version == 0 {
+		current, err := c.tasks.GetByID(ctx, uint(task.ID))
+		if err != nil {
+			c.failTask(log, req, err, "failed to update task")
+			return
+		}
+		task.Version = current.Version
+	}
+
+	if err := c.tasks.UpdateTask(ctx, &task, policy); err != nil {
+		c.failTask(log, req, err, "failed to update task")
+		return
+	}
+
+	log.Info("task updated", slog.Int64("id", task.ID))
+	c.reply(wsResponse{Type: wsAck, ID: req.ID, Task: &task})
+}
+
+func (c *wsConn) delete(ctx context.Context, log *slog.Logger, req wsRequest) {
+	if req.TaskID < 1 {
+		c.fail(req.ID, http.StatusBadRequest, "invalid task_id")
+		return
+	}
+	if req.Version == 0 && c.opts.RequireVersion {
+		c.fail(req.ID, http.StatusPreconditionRequired, "version is required")
+		return
+	}
+
+	if err := c.tasks.DeleteTask(ctx, uint(req.TaskID), req.Version); err != nil {
+		c.failTask(log, req, err, "failed to delete task")
+		return
+	}
+
+	log.Info("task deleted", slog.Int64("id", req.TaskID))
+	c.reply(wsResponse{Type: wsAck, ID: req.ID})
+}
+
+// subscribe заменяет подписку соединения. Как и GET /tasks/events, она
+// оформляется до чтения журнала, а повторы отбрасываются по ID.
+func (c *wsConn) subscribe(ctx context.Context, log *slog.Logger, req wsRequest) {
+	if req.LastEventID < 0 {
+		c.fail(req.ID, http.StatusBadRequest, "invalid last event id")
+		return
+	}
+
+	filter := models.TaskEventFilter{ProjectID: req.Filter.ProjectID, Tags: tagFilter(req.Filter.Tags)}
+	if filter.ProjectID != nil && *filter.ProjectID < 1 {
+		c.fail(req.ID, http.StatusBadRequest, "invalid project_id")
+		return
+	}
+	for _, value := range req.Filter.Statuses {
+		status := models.TaskStatus(value)
+		if !status.Valid() {
+			c.fail(req.ID, http.StatusBadRequest, "invalid status")
+			return
+		}
+		if !slices.Contains(filter.Statuses, status) {
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	c.stopSubscription()
+
+	events, unsubscribe := c.events.Subscribe(filter)
+
+	var backlog []models.TaskEvent
+	replayed := req.LastEventID
+	expired := false
+	if req.LastEventID > 0 {
+		var err error
+		backlog, replayed, err = replayTaskEvents(ctx, c.events, req.LastEventID, filter)
+		switch {
+		case errors.Is(err, storage.ErrEventsExpired):
+			expired = true
+		case err != nil:
+			unsubscribe()
+			c.failTask(log, req, err, "failed to read task events")
+			return
+		}
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	c.unsubscribe = func() {
+		cancel()
+		unsubscribe()
+		<-done
+	}
+
+	c.reply(wsResponse{Type: wsAck, ID: req.ID})
+	if expired {
+		log.Info("task events expired, client must reload", slog.Int64("last_event_id", req.LastEventID))
+		c.reply(wsResponse{Type: wsReset})
+	}
+	for _, event := range backlog {
+		c.reply(wsResponse{Type: wsEvent, Event: &event})
+	}
+
+	log.Info("subscribed to task events", slog.Int64("last_event_id", req.LastEventID), slog.Int("replayed", len(backlog)))
+
+	go func() {
+		defer close(done)
+		c.forward(subCtx, events, replayed)
+	}()
+}
+
+// forward передаёт клиенту события подписки новее replayed.
+func (c *wsConn) forward(ctx context.Context, events <-chan models.TaskEvent, replayed int64) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil || isClosed(c.opts.Done) {
+					return
+				}
+				// Feed закрывает подписку, если клиент отстал или сервер
+				// останавливается; клиент переподключится с last_event_id.
+				c.close(websocket.StatusTryAgainLater, "task events subscription closed, resubscribe with last_event_id")
+				return
+			}
+			if event.ID <= replayed {
+				continue
+			}
+			c.reply(wsResponse{Type: wsEvent, Event: &event})
+		}
+	}
+}
+
+func (c *wsConn) stopSubscription() {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+		c.unsubscribe = nil
+	}
+}
+
+// failTask отвечает ошибкой операции над задачей с тем же кодом, что
+// вернул бы REST-запрос.
+func (c *wsConn) failTask(log *slog.Logger, req wsRequest, err error, msg string) {
+	if status, text := taskErrorResponse(err); status != 0 {
+		log.Info(text, slog.Int64("id", req.TaskID), sl.Err(err))
+		c.fail(req.ID, status, text)
+		return
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Error("storage request timed out", sl.Err(err))
+		c.fail(req.ID, http.StatusGatewayTimeout, "request timed out")
+	case errors.Is(err, context.Canceled):
+		log.Info("websocket closed during request", sl.Err(err))
+	default:
+		log.Error(msg, sl.Err(err))
+		c.fail(req.ID, http.StatusInternalServerError, msg)
+	}
+}
+
+func (c *wsConn) fail(id string, code int, text string) {
+	c.reply(wsResponse{Type: wsError, ID: id, Code: code, Error: text})
+}
+
+// reply ставит сообщение в очередь отправки. Если очередь заполнена,
+// клиент не успевает читать, и соединение закрывается.
+func (c *wsConn) reply(msg wsResponse) {
+	select {
+	case c.outgoing <- msg:
+	default:
+		c.log.Warn("websocket send buffer is full, closing", slog.Int("buffer", c.opts.SendBuffer))
+		c.close(websocket.StatusTryAgainLater, "client is too slow")
+	}
+}
+
+func (c *wsConn) writeLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-c.outgoing:
+			if err := c.write(ctx, msg); err != nil {
+				c.log.Info("websocket write failed", sl.Err(err))
+				c.close(websocket.StatusInternalError, "write failed")
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConn) write(ctx context.Context, msg wsResponse) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.WriteTimeout)
+	defer cancel()
+
+	return c.conn.Write(ctx, websocket.MessageText, data)
+}
+
+// pingLoop проверяет, что клиент на связи. Pong обрабатывает читающая
+// горутина.
+func (c *wsConn) pingLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.opts.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, c.opts.PongTimeout)
+			err := c.conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					c.log.Info("websocket ping failed", sl.Err(err))
+					c.close(websocket.StatusPolicyViolation, "pong timeout")
+				}
+				return
+			}
+		}
+	}
+}
+
+// isClosed сообщает, что канал done закрыт.
+func isClosed(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close закрывает соединение, не дожидаясь ответа клиента; читающая горутина получит ошибку и завершит
+// обслуживание. Повторные вызовы ничего не делают.
+func (c *wsConn) close(code websocket.StatusCode, reason string) {
+	c.closeOnce.Do(func() {
+		go c.conn.Close(code, reason)
+	})
+}
@@ -0,0 +1,267 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/http-server/handlers/mocks"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// wsMessage — сообщение сервера /ws в том виде, в каком его видит клиент.
+type wsMessage struct {
+	Type  string            `json:"type"`
+	ID    string            `json:"id"`
+	Task  *models.Task      `json:"task"`
+	Event *models.TaskEvent `json:"event"`
+	Code  int               `json:"code"`
+	Error string            `json:"error"`
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		message        string
+		requireVersion bool
+		setup          func(taskService *mocks.TaskService)
+		expectType     string
+		expectCode     int
+		expectError    string
+		expectTaskID   int64
+	}{
+		{
+			name:    "Create",
+			message: `{"id":"1","type":"create","task":{"title":"ws task","due_date":"2025-04-20T15:00:00Z"}}`,
+			setup: func(taskService *mocks.TaskService) {
+				taskService.On("CreateTask", mock.Anything, mock.MatchedBy(func(task models.Task) bool {
+					return task.Title == "ws task"
+				})).Return(&models.Task{ID: 3, Title: "ws task", Version: 1}, nil).Once()
+			},
+			expectType:   "ack",
+			expectTaskID: 3,
+		},
+		{
+			name:        "Create without title",
+			message:     `{"id":"1","type":"create","task":{"due_date":"2025-04-20T15:00:00Z"}}`,
+			expectType:  "error",
+			expectCode:  http.StatusBadRequest,
+			expectError: "field title is a required field",
+		},
+		{
+			name:        "Create without task",
+			message:     `{"id":"1","type":"create"}`,
+			expectType:  "error",
+			expectCode:  http.StatusBadRequest,
+			expectError: "task is required",
+		},
+		{
+			name:    "Update",
+			message: `{"id":"1","type":"update","task_id":5,"version":2,"task":{"title":"renamed","due_date":"2025-04-20T15:00:00Z"}}`,
+			setup: func(taskService *mocks.TaskService) {
+				taskService.On("UpdateTask", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					return task.ID == 5 && task.Version == 2 && task.Title == "renamed"
+				}), models.SubtasksBlock).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.Task).Version = 3
+				}).Return(nil).Once()
+			},
+			expectType:   "ack",
+			expectTaskID: 5,
+		},
+		{
+			name:    "Update version mismatch",
+			message: `{"id":"1","type":"update","task_id":5,"version":1,"task":{"title":"renamed","due_date":"2025-04-20T15:00:00Z"}}`,
+			setup: func(taskService *mocks.TaskService) {
+				taskService.On("UpdateTask", mock.Anything, mock.Anything, models.SubtasksBlock).
+					Return(storage.ErrVersionMismatch).Once()
+			},
+			expectType:  "error",
+			expectCode:  http.StatusPreconditionFailed,
+			expectError: "task version mismatch",
+		},
+		{
+			// Подзадачи выполняет само хранилище вместе с задачей, поэтому
+			// отклонённое изменение их не трогает.
+			name:    "Update completing subtasks version mismatch",
+			message: `{"id":"1","type":"update","task_id":5,"version":1,"children":"complete","task":{"title":"renamed","due_date":"2025-04-20T15:00:00Z","status":"done"}}`,
+			setup: func(taskService *mocks.TaskService) {
+				taskService.On("UpdateTask", mock.Anything, mock.Anything, models.SubtasksComplete).
+					Return(storage.ErrVersionMismatch).Once()
+			},
+			expectType:  "error",
+			expectCode:  http.StatusPreconditionFailed,
+			expectError: "task version mismatch",
+		},
+		{
+			name:           "Update without version",
+			message:        `{"id":"1","type":"update","task_id":5,"task":{"title":"renamed","due_date":"2025-04-20T15:00:00Z"}}`,
+			requireVersion: true,
+			expectType:     "error",
+			expectCode:     http.StatusPreconditionRequired,
+			expectError:    "version is required",
+		},
+		{
+			name:    "Delete",
+			message: `{"id":"1","type":"delete","task_id":5}`,
+			setup: func(taskService *mocks.TaskService) {
+				taskService.On("DeleteTask", mock.Anything, uint(5), int64(0)).Return(nil).Once()
+			},
+			expectType: "ack",
+		},
+		{
+			name:    "Delete missing task",
+			message: `{"id":"1","type":"delete","task_id":5,"version":2}`,
+			setup: func(taskService *mocks.TaskService) {
+				taskService.On("DeleteTask", mock.Anything, uint(5), int64(2)).
+					Return(storage.ErrTaskNotFound).Once()
+			},
+			expectType:  "error",
+			expectCode:  http.StatusNotFound,
+			expectError: "task not found",
+		},
+		{
+			name:        "Unknown type",
+			message:     `{"id":"1","type":"archive"}`,
+			expectType:  "error",
+			expectCode:  http.StatusBadRequest,
+			expectError: "unknown message type",
+		},
+		{
+			name:        "Invalid JSON",
+			message:     `{"id":`,
+			expectType:  "error",
+			expectCode:  http.StatusBadRequest,
+			expectError: "failed to decode message",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskService := mocks.NewTaskService(t)
+			if tc.setup != nil {
+				tc.setup(taskService)
+			}
+
+			conn := dialWebSocket(t, taskService, mocks.NewTaskEventService(t), tc.requireVersion)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(tc.message)))
+
+			msg := readWebSocket(ctx, t, conn)
+			require.Equal(t, tc.expectType, msg.Type)
+			require.Equal(t, tc.expectCode, msg.Code)
+			require.Equal(t, tc.expectError, msg.Error)
+			if tc.expectError != "failed to decode message" {
+				require.Equal(t, "1", msg.ID)
+			}
+			if tc.expectTaskID != 0 {
+				require.NotNil(t, msg.Task)
+				require.Equal(t, tc.expectTaskID, msg.Task.ID)
+			}
+
+			// После ошибки соединение продолжает обслуживать запросы.
+			require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"id":"2","type":"archive"}`)))
+			require.Equal(t, "2", readWebSocket(ctx, t, conn).ID)
+		})
+	}
+}
+
+func TestWebSocketSubscribe(t *testing.T) {
+	event := func(id int64, status models.TaskStatus) models.TaskEvent {
+		return models.TaskEvent{
+			ID: id,
+			WebhookEvent: models.WebhookEvent{
+				Type: models.EventTaskUpdated,
+				Task: models.Task{ID: 7, Title: "dashboard", Status: status},
+			},
+		}
+	}
+
+	live := make(chan models.TaskEvent, 2)
+	eventService := mocks.NewTaskEventService(t)
+	eventService.On("Subscribe", models.TaskEventFilter{Statuses: []models.TaskStatus{models.StatusDone}}).
+		Return((<-chan models.TaskEvent)(live), func() {}).Once()
+	eventService.On("ListTaskEvents", mock.Anything, int64(3), mock.Anything).
+		Return([]models.TaskEvent{event(4, models.StatusDone), event(5, models.StatusTodo)}, nil).Once()
+
+	conn := dialWebSocket(t, mocks.NewTaskService(t), eventService, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText,
+		[]byte(`{"id":"s","type":"subscribe","filter":{"statuses":["done"]},"last_event_id":3}`)))
+
+	msg := readWebSocket(ctx, t, conn)
+	require.Equal(t, "ack", msg.Type)
+	require.Equal(t, "s", msg.ID)
+
+	msg = readWebSocket(ctx, t, conn)
+	require.Equal(t, "event", msg.Type)
+	require.Equal(t, int64(4), msg.Event.ID)
+
+	// Событие 5 прочитано из журнала, повтор из подписки отбрасывается.
+	live <- event(5, models.StatusDone)
+	live <- event(6, models.StatusDone)
+
+	msg = readWebSocket(ctx, t, conn)
+	require.Equal(t, "event", msg.Type)
+	require.Equal(t, int64(6), msg.Event.ID)
+	require.Equal(t, models.StatusDone, msg.Event.Task.Status)
+
+	// Закрытая подписка закрывает соединение: клиент отстал.
+	close(live)
+	_, _, err := conn.Read(ctx)
+	require.Equal(t, websocket.StatusTryAgainLater, websocket.CloseStatus(err))
+}
+
+func dialWebSocket(t *testing.T, taskService handlers.TaskService, eventService handlers.TaskEventService, requireVersion bool) *websocket.Conn {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(handlers.WebSocket(log, taskService, eventService, handlers.WebSocketOptions{
+		PingInterval:   time.Minute,
+		PongTimeout:    time.Second,
+		WriteTimeout:   time.Second,
+		SendBuffer:     16,
+		MaxMessageSize: 1 << 16,
+		RequireVersion: requireVersion,
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.CloseNow() })
+
+	return conn
+}
+
+func readWebSocket(ctx context.Context, t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+
+	typ, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+	require.Equal(t, websocket.MessageText, typ)
+
+	var msg wsMessage
+	require.NoError(t, json.Unmarshal(data, &msg))
+
+	return msg
+}
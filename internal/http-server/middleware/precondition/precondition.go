@@ -0,0 +1,38 @@
+package precondition
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "todo/internal/lib/api/response"
+)
+
+// RequireIfMatch отклоняет изменяющие запросы без заголовка If-Match
+// с кодом 428 Precondition Required, чтобы клиенты не перезаписывали
+// чужие изменения вслепую.
+func RequireIfMatch(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				if r.Header.Get("If-Match") == "" {
+					log.Info("if-match header is missing",
+						slog.String("method", r.Method),
+						slog.String("path", r.URL.Path),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+					w.WriteHeader(http.StatusPreconditionRequired)
+					render.JSON(w, r, resp.Error("If-Match header is required"))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
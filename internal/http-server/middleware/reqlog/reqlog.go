@@ -0,0 +1,59 @@
+package reqlog
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// Redacted заменяет в журнале значения скрытых параметров запроса.
+const Redacted = "REDACTED"
+
+// Logger пишет журнал запросов так же, как middleware.Logger, но скрывает
+// значения параметров запроса params: секреты из адреса, например токен
+// календаря, не должны попадать в журнал.
+func Logger(params ...string) func(next http.Handler) http.Handler {
+	return middleware.RequestLogger(NewFormatter(&middleware.DefaultLogFormatter{
+		Logger: log.New(os.Stdout, "", log.LstdFlags),
+	}, params...))
+}
+
+// NewFormatter оборачивает formatter так, что он получает адрес запроса
+// со скрытыми значениями параметров params. Сам запрос не меняется.
+func NewFormatter(formatter middleware.LogFormatter, params ...string) middleware.LogFormatter {
+	return &redactFormatter{
+		formatter: formatter,
+		params:    params,
+	}
+}
+
+type redactFormatter struct {
+	formatter middleware.LogFormatter
+	params    []string
+}
+
+func (f *redactFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	query := r.URL.Query()
+
+	redacted := false
+	for _, param := range f.params {
+		if query.Has(param) {
+			query.Set(param, Redacted)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return f.formatter.NewLogEntry(r)
+	}
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	clone := r.WithContext(r.Context())
+	clone.URL = &u
+	clone.RequestURI = u.RequestURI()
+
+	return f.formatter.NewLogEntry(clone)
+}
@@ -0,0 +1,66 @@
+package reqlog_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/http-server/middleware/reqlog"
+)
+
+func TestLogger(t *testing.T) {
+	cases := []struct {
+		name      string
+		target    string
+		expectLog string
+		absentLog string
+		expectURI string
+	}{
+		{
+			name:      "Token",
+			target:    "/tasks.ics?token=9b2f&completed=false",
+			expectLog: "/tasks.ics?completed=false&token=REDACTED",
+			absentLog: "9b2f",
+			expectURI: "/tasks.ics?token=9b2f&completed=false",
+		},
+		{
+			name:      "No token",
+			target:    "/tasks?completed=false",
+			expectLog: "/tasks?completed=false",
+			expectURI: "/tasks?completed=false",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := reqlog.NewFormatter(&middleware.DefaultLogFormatter{
+				Logger:  log.New(&buf, "", 0),
+				NoColor: true,
+			}, "token")
+
+			var gotURI, gotToken string
+			handler := middleware.RequestLogger(formatter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotURI = r.RequestURI
+				gotToken = r.URL.Query().Get("token")
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Contains(t, buf.String(), tc.expectLog)
+			if tc.absentLog != "" {
+				require.NotContains(t, buf.String(), tc.absentLog)
+				require.Equal(t, tc.absentLog, gotToken)
+			}
+			require.Equal(t, tc.expectURI, gotURI)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+)
+
+// Unmatched подставляется вместо шаблона маршрута для запросов, которые не
+// совпали ни с одним маршрутом, чтобы произвольные пути не попадали в метки
+// метрик и имена спанов.
+const Unmatched = "unmatched"
+
+// Pattern возвращает шаблон маршрута chi, обработавшего запрос, например
+// /tasks/{id}. Вызывать нужно после того, как роутер обработал запрос.
+func Pattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return Unmatched
+	}
+
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return Unmatched
+	}
+
+	// Маршруты, смонтированные через Route, дают шаблон с завершающим "/".
+	if len(pattern) > 1 {
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	return pattern
+}
+
+// ByFormat вызывает обработчик из formats по расширению пути, которое
+// выделил middleware.URLFormat (/tasks.ics → ics). Если расширения нет
+// или для него нет обработчика, вызывается next. URLFormat убирает
+// расширение из пути маршрутизации, поэтому /tasks.ics нельзя
+// зарегистрировать отдельным маршрутом.
+func ByFormat(next http.HandlerFunc, formats map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string)
+		if handler, ok := formats[format]; ok {
+			handler(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,375 @@
+// Package ical записывает и разбирает календари iCalendar (RFC 5545):
+// строки свойств с экранированием текста и переносом длинных строк.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets — наибольшая длина строки без CRLF (RFC 5545, 3.1).
+const maxLineOctets = 75
+
+// timeFormat — дата и время в UTC (RFC 5545, 3.3.5, форма 2).
+const timeFormat = "20060102T150405Z"
+
+// Writer записывает компоненты и свойства календаря. Ошибка записи
+// запоминается, последующие вызовы ничего не делают, а Flush её
+// возвращает.
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter возвращает Writer поверх w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Begin открывает компонент: BEGIN:VTODO.
+func (w *Writer) Begin(component string) {
+	w.line("BEGIN:" + component)
+}
+
+// End закрывает компонент: END:VTODO.
+func (w *Writer) End(component string) {
+	w.line("END:" + component)
+}
+
+// Raw записывает свойство со значением без экранирования: для значений
+// из фиксированного набора, например STATUS, и для параметров вроде
+// RELATED-TO;RELTYPE=PARENT.
+func (w *Writer) Raw(name, value string) {
+	w.line(name + ":" + value)
+}
+
+// Text записывает текстовое свойство, экранируя значение.
+func (w *Writer) Text(name, value string) {
+	w.line(name + ":" + EscapeText(value))
+}
+
+// TextList записывает текстовое свойство со списком значений, например
+// CATEGORIES.
+func (w *Writer) TextList(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = EscapeText(value)
+	}
+	w.line(name + ":" + strings.Join(escaped, ","))
+}
+
+// Time записывает время в UTC.
+func (w *Writer) Time(name string, t time.Time) {
+	w.line(name + ":" + FormatTime(t))
+}
+
+// Flush дописывает буфер и возвращает первую ошибку записи.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.w.Flush()
+	return w.err
+}
+
+// line записывает строку, перенося её по maxLineOctets байт: каждая
+// следующая часть начинается с пробела. Многобайтовые символы UTF-8 не
+// разрываются.
+func (w *Writer) line(s string) {
+	if w.err != nil {
+		return
+	}
+
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.write(s[:cut])
+		w.write("\r\n ")
+		s = s[cut:]
+		// Пробел в начале продолжения занимает один байт.
+		limit = maxLineOctets - 1
+	}
+	w.write(s)
+	w.write("\r\n")
+}
+
+func (w *Writer) write(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.w.WriteString(s)
+}
+
+// textEscaper экранирует значения типа TEXT (RFC 5545, 3.3.11).
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// EscapeText экранирует обратную косую черту, точку с запятой, запятую
+// и переводы строк.
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// FormatTime возвращает время в UTC: 20250420T150000Z.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// Property — свойство компонента: DUE;TZID=Europe/Moscow:20250420T180000.
+// Value хранится без разбора и экранирования.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Component — компонент календаря с вложенными компонентами:
+// VCALENDAR, VTODO, VALARM.
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []*Component
+}
+
+// Prop возвращает первое свойство с именем name или nil.
+func (c *Component) Prop(name string) *Property {
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+// Component возвращает первый вложенный компонент с именем name или nil.
+func (c *Component) Component(name string) *Component {
+	for _, child := range c.Components {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Text возвращает значение свойства типа TEXT без экранирования.
+func (p *Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// TextList возвращает значения свойства со списком TEXT, например
+// CATEGORIES.
+func (p *Property) TextList() []string {
+	var (
+		values  []string
+		current strings.Builder
+		escaped bool
+	)
+	for _, r := range p.Value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			values = append(values, UnescapeText(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	values = append(values, UnescapeText(current.String()))
+
+	return values
+}
+
+// Time возвращает значение свойства типа DATE-TIME или DATE. Время в UTC
+// (20250420T150000Z) и с параметром TZID переводится в абсолютное;
+// «плавающее» время без пояса и дата без времени считаются в location.
+func (p *Property) Time(location *time.Location) (time.Time, error) {
+	if tzid := p.Params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+
+	switch {
+	case p.Params["VALUE"] == "DATE" || len(p.Value) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", p.Value, location)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: invalid date %q", p.Name, p.Value)
+		}
+		return t, nil
+	case strings.HasSuffix(p.Value, "Z"):
+		t, err := time.Parse(timeFormat, p.Value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: invalid date-time %q", p.Name, p.Value)
+		}
+		return t, nil
+	default:
+		t, err := time.ParseInLocation("20060102T150405", p.Value, location)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: invalid date-time %q", p.Name, p.Value)
+		}
+		return t, nil
+	}
+}
+
+// textUnescaper снимает экранирование значений типа TEXT.
+var textUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	`\;`, ";",
+	`\,`, ",",
+	`\n`, "\n",
+	`\N`, "\n",
+)
+
+// UnescapeText снимает экранирование, добавленное EscapeText.
+func UnescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// Parse читает календарь: первый компонент верхнего уровня, обычно
+// VCALENDAR. Перенесённые строки склеиваются, имена свойств и параметров
+// приводятся к верхнему регистру.
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		root  *Component
+		stack []*Component
+	)
+	for _, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root == nil {
+				root = component
+			} else {
+				return nil, errors.New("more than one top-level component")
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("unexpected END:%s", prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("property %s outside of a component", prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Props = append(current.Props, prop)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("no components")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("component %s is not closed", stack[len(stack)-1].Name)
+	}
+
+	return root, nil
+}
+
+// unfold читает строки, склеивая продолжения, которые начинаются с
+// пробела или табуляции. Пустые строки пропускаются.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// parseLine разбирает строку свойства: NAME;PARAM=VALUE;PARAM="V:V":VALUE.
+func parseLine(line string) (Property, error) {
+	prop := Property{Params: map[string]string{}}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("invalid line %q", line)
+	}
+	prop.Name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("invalid parameter in line %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return prop, fmt.Errorf("unterminated quoted parameter in line %q", line)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("invalid line %q", line)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		prop.Params[name] = value
+
+		i = len(line) - len(rest)
+		if i >= len(line) {
+			return prop, fmt.Errorf("invalid line %q", line)
+		}
+	}
+
+	if line[i] != ':' {
+		return prop, fmt.Errorf("invalid line %q", line)
+	}
+	prop.Value = line[i+1:]
+
+	return prop, nil
+}
@@ -0,0 +1,127 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/lib/ical"
+)
+
+func TestEscapeText(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  string
+		expect string
+	}{
+		{name: "Plain", value: "Купить молоко", expect: "Купить молоко"},
+		{name: "Separators", value: "a;b,c", expect: `a\;b\,c`},
+		{name: "Backslash", value: `C:\temp`, expect: `C:\\temp`},
+		{name: "Newlines", value: "line 1\r\nline 2\nline 3", expect: `line 1\nline 2\nline 3`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expect, ical.EscapeText(tc.value))
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := ical.NewWriter(&buf)
+
+	w.Begin("VTODO")
+	w.Time("DUE", time.Date(2025, 4, 20, 18, 0, 0, 0, time.FixedZone("MSK", 3*60*60)))
+	w.TextList("CATEGORIES", []string{"дом", "a,b"})
+	w.Text("SUMMARY", strings.Repeat("я", 40))
+	w.End("VTODO")
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	require.Equal(t, "BEGIN:VTODO", lines[0])
+	require.Equal(t, "DUE:20250420T150000Z", lines[1])
+	require.Equal(t, `CATEGORIES:дом,a\,b`, lines[2])
+	require.Equal(t, "END:VTODO", lines[len(lines)-1])
+
+	// SUMMARY из 80 байт кириллицы переносится, не разрывая символы.
+	summary := lines[3 : len(lines)-1]
+	require.Len(t, summary, 2)
+	for i, line := range summary {
+		require.LessOrEqual(t, len(line), 75)
+		if i > 0 {
+			require.True(t, strings.HasPrefix(line, " "))
+		}
+	}
+	unfolded := summary[0] + strings.TrimPrefix(summary[1], " ")
+	require.Equal(t, "SUMMARY:"+strings.Repeat("я", 40), unfolded)
+}
+
+func TestParse(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:abc@client\r\n" +
+		`SUMMARY:Купить молоко\; кеф` + "\r\n" +
+		" ир\r\n" +
+		"DUE;TZID=Europe/Moscow:20250420T180000\r\n" +
+		"DTSTART;VALUE=DATE:20250419\r\n" +
+		"COMPLETED:20250419T080000Z\r\n" +
+		"CATEGORIES:дом,a\\,b\r\n" +
+		"X-LABEL;X-NOTE=\"a:b;c\":value\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	calendar, err := ical.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, "VCALENDAR", calendar.Name)
+
+	todo := calendar.Component("VTODO")
+	require.NotNil(t, todo)
+	require.NotNil(t, todo.Component("VALARM"))
+	require.Nil(t, todo.Prop("DESCRIPTION"))
+
+	require.Equal(t, "abc@client", todo.Prop("UID").Text())
+	require.Equal(t, "Купить молоко; кефир", todo.Prop("SUMMARY").Text())
+	require.Equal(t, []string{"дом", "a,b"}, todo.Prop("CATEGORIES").TextList())
+	require.Equal(t, "a:b;c", todo.Prop("X-LABEL").Params["X-NOTE"])
+	require.Equal(t, "value", todo.Prop("X-LABEL").Value)
+
+	due, err := todo.Prop("DUE").Time(time.UTC)
+	require.NoError(t, err)
+	require.True(t, due.Equal(time.Date(2025, 4, 20, 15, 0, 0, 0, time.UTC)))
+
+	start, err := todo.Prop("DTSTART").Time(time.UTC)
+	require.NoError(t, err)
+	require.True(t, start.Equal(time.Date(2025, 4, 19, 0, 0, 0, 0, time.UTC)))
+
+	completed, err := todo.Prop("COMPLETED").Time(time.UTC)
+	require.NoError(t, err)
+	require.True(t, completed.Equal(time.Date(2025, 4, 19, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "Empty", input: ""},
+		{name: "Not closed", input: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VTODO\r\n"},
+		{name: "Mismatched end", input: "BEGIN:VCALENDAR\r\nEND:VTODO\r\n"},
+		{name: "No colon", input: "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR\r\n"},
+		{name: "Property outside", input: "SUMMARY:x\r\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ical.Parse(strings.NewReader(tc.input))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/lib/jsonpatch"
+)
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		name   string
+		doc    string
+		patch  string
+		expect string
+	}{
+		{name: "Replace value", doc: `{"a":"b"}`, patch: `{"a":"c"}`, expect: `{"a":"c"}`},
+		{name: "Add value", doc: `{"a":"b"}`, patch: `{"b":"c"}`, expect: `{"a":"b","b":"c"}`},
+		{name: "Remove value", doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, expect: `{"b":"c"}`},
+		{name: "Replace array", doc: `{"a":["b"]}`, patch: `{"a":["c"]}`, expect: `{"a":["c"]}`},
+		{name: "Nested objects", doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, expect: `{"a":{"b":"d"}}`},
+		{name: "Non-object patch", doc: `{"a":"b"}`, patch: `["c"]`, expect: `["c"]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := jsonpatch.MergePatch([]byte(tc.doc), []byte(tc.patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expect, string(res))
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		name      string
+		doc       string
+		patch     string
+		expect    string
+		expectErr error
+	}{
+		{
+			name:   "Add and replace",
+			doc:    `{"a":"b"}`,
+			patch:  `[{"op":"add","path":"/c","value":1},{"op":"replace","path":"/a","value":"d"}]`,
+			expect: `{"a":"d","c":1}`,
+		},
+		{
+			name:   "Array operations",
+			doc:    `{"a":[1,2,3]}`,
+			patch:  `[{"op":"add","path":"/a/1","value":9},{"op":"remove","path":"/a/0"},{"op":"add","path":"/a/-","value":4}]`,
+			expect: `{"a":[9,2,3,4]}`,
+		},
+		{
+			name:   "Move and copy",
+			doc:    `{"a":{"b":"c"},"d":"e"}`,
+			patch:  `[{"op":"move","from":"/a/b","path":"/f"},{"op":"copy","from":"/d","path":"/a/g"}]`,
+			expect: `{"a":{"g":"e"},"d":"e","f":"c"}`,
+		},
+		{
+			name:   "Escaped pointer",
+			doc:    `{"a/b":1,"c~d":2}`,
+			patch:  `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/c~0d","value":3}]`,
+			expect: `{"c~d":3}`,
+		},
+		{
+			name:   "Test passes",
+			doc:    `{"a":{"b":[1,"x"]}}`,
+			patch:  `[{"op":"test","path":"/a/b","value":[1,"x"]}]`,
+			expect: `{"a":{"b":[1,"x"]}}`,
+		},
+		{
+			name:      "Test fails",
+			doc:       `{"a":"b"}`,
+			patch:     `[{"op":"test","path":"/a","value":"c"}]`,
+			expectErr: jsonpatch.ErrTestFailed,
+		},
+		{
+			name:      "Remove missing path",
+			doc:       `{"a":"b"}`,
+			patch:     `[{"op":"remove","path":"/c"}]`,
+			expectErr: jsonpatch.ErrInvalidPatch,
+		},
+		{
+			name:      "Unknown op",
+			doc:       `{"a":"b"}`,
+			patch:     `[{"op":"merge","path":"/a","value":1}]`,
+			expectErr: jsonpatch.ErrInvalidPatch,
+		},
+		{
+			name:      "Array index out of range",
+			doc:       `{"a":[1]}`,
+			patch:     `[{"op":"add","path":"/a/3","value":1}]`,
+			expectErr: jsonpatch.ErrInvalidPatch,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := jsonpatch.JSONPatch([]byte(tc.doc), []byte(tc.patch))
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expect, string(res))
+		})
+	}
+}
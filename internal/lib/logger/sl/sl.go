@@ -1,6 +1,11 @@
 package sl
 
-import "log/slog"
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
 
 func Err(err error) slog.Attr {
 	return slog.Attr{
@@ -8,3 +13,17 @@ func Err(err error) slog.Attr {
 		Value: slog.StringValue(err.Error()),
 	}
 }
+
+// Trace возвращает идентификаторы трассы и спана из ctx. Если запрос не
+// трассируется, возвращается пустой атрибут, который slog пропускает.
+func Trace(ctx context.Context) slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return slog.Attr{}
+	}
+
+	return slog.Group("",
+		slog.String("trace_id", sc.TraceID().String()),
+		slog.String("span_id", sc.SpanID().String()),
+	)
+}
@@ -0,0 +1,451 @@
+// Package rrule разбирает правила повторения RRULE (RFC 5545) и вычисляет
+// даты повторений. Поддерживается подмножество правил: FREQ (DAILY, WEEKLY,
+// MONTHLY, YEARLY), INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL и WKST.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRule означает, что правило не удалось разобрать или оно
+// использует неподдерживаемые части.
+var ErrInvalidRule = errors.New("invalid rrule")
+
+// Frequency — базовый период правила.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods ограничивает перебор периодов для правил, которые больше
+// никогда не срабатывают, например BYMONTHDAY=30 у февральской серии
+// с INTERVAL=12.
+const maxPeriods = 10000
+
+// WeekdayNum — день недели из BYDAY с необязательным порядковым номером
+// в месяце: 1MO — первый понедельник, -1FR — последняя пятница. N == 0
+// означает каждый такой день периода.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// untilKind — форма значения UNTIL.
+type untilKind int
+
+const (
+	// untilUTC — дата и время в UTC: 20250601T090000Z.
+	untilUTC untilKind = iota
+	// untilFloating — дата и время в часовом поясе серии: 20250601T090000.
+	untilFloating
+	// untilDate — дата, включительно: 20250601.
+	untilDate
+)
+
+// Rule — разобранное правило повторения.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	// Count — число повторений вместе с первым; 0 — без ограничения.
+	Count int
+	// Until — последний допустимый момент повторения; нулевое значение —
+	// без ограничения.
+	Until     time.Time
+	WeekStart time.Weekday
+
+	untilKind untilKind
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse разбирает правило вида FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10. Префикс
+// RRULE: допускается, регистр не важен.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate %s", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq, err = parseFrequency(val)
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(val)
+		case "COUNT":
+			rule.Count, err = parsePositive(val)
+		case "UNTIL":
+			rule.Until, rule.untilKind, err = parseUntil(val)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(val)
+		case "WKST":
+			day, ok := weekdays[val]
+			if !ok {
+				err = fmt.Errorf("unknown weekday %q", val)
+			}
+			rule.WeekStart = day
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidRule, name, err)
+		}
+	}
+
+	if err := rule.check(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRule, err)
+	}
+
+	return rule, nil
+}
+
+// check проверяет сочетания частей правила.
+func (r *Rule) check() error {
+	if r.Freq == "" {
+		return errors.New("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return errors.New("COUNT and UNTIL are mutually exclusive")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	}
+	if r.Freq == Yearly && len(r.ByDay) > 0 {
+		return errors.New("BYDAY is not supported with FREQ=YEARLY")
+	}
+	if r.Freq != Monthly {
+		for _, day := range r.ByDay {
+			if day.N != 0 {
+				return errors.New("numbered BYDAY is only supported with FREQ=MONTHLY")
+			}
+		}
+	}
+	return nil
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	switch freq := Frequency(value); freq {
+	case Daily, Weekly, Monthly, Yearly:
+		return freq, nil
+	default:
+		return "", fmt.Errorf("unsupported frequency %q", value)
+	}
+}
+
+func parsePositive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("must be a positive integer, got %q", value)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, untilKind, error) {
+	layouts := []struct {
+		layout string
+		kind   untilKind
+	}{
+		{"20060102T150405Z", untilUTC},
+		{"20060102T150405", untilFloating},
+		{"20060102", untilDate},
+	}
+
+	for _, l := range layouts {
+		if len(value) != len(l.layout) {
+			continue
+		}
+		t, err := time.Parse(l.layout, value)
+		if err != nil {
+			break
+		}
+		return t, l.kind, nil
+	}
+
+	return time.Time{}, 0, fmt.Errorf("invalid date %q, use YYYYMMDD or YYYYMMDDTHHMMSSZ", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid weekday %q", item)
+			}
+		}
+
+		days = append(days, WeekdayNum{N: n, Day: day})
+	}
+
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+
+	for _, item := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(item)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("invalid month day %q", item)
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// String возвращает правило в каноническом виде: части в фиксированном
+// порядке, INTERVAL=1 и WKST=MO опускаются.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = weekdayNames[day.Day]
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		layout := "20060102T150405Z"
+		switch r.untilKind {
+		case untilFloating:
+			layout = "20060102T150405"
+		case untilDate:
+			layout = "20060102"
+		}
+		parts = append(parts, "UNTIL="+r.Until.Format(layout))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Occurrences возвращает до n повторений, следующих за start. start —
+// первое повторение серии (DTSTART): оно входит в COUNT, но в результат не
+// попадает. Время суток и часовой пояс повторений берутся из start, так что
+// при переходе на летнее время повторения сохраняют местное время.
+func (r *Rule) Occurrences(start time.Time, n int) []time.Time {
+	var result []time.Time
+
+	loc := start.Location()
+	hour, minute, sec := start.Clock()
+	emitted := 1
+
+	for period := 0; period < maxPeriods && len(result) < n; period++ {
+		for _, day := range r.candidates(start, period) {
+			t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, sec, start.Nanosecond(), loc)
+			if !t.After(start) {
+				continue
+			}
+			if r.afterUntil(t) || (r.Count > 0 && emitted >= r.Count) {
+				return result
+			}
+
+			emitted++
+			result = append(result, t)
+			if len(result) == n {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// afterUntil сообщает, что повторение t выходит за UNTIL.
+func (r *Rule) afterUntil(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+
+	switch r.untilKind {
+	case untilFloating:
+		return t.After(inLocation(r.Until, t.Location()))
+	case untilDate:
+		return date(t).After(r.Until)
+	default:
+		return t.After(r.Until)
+	}
+}
+
+// candidates возвращает упорядоченные дни периода с номером period
+// (считая от периода start с шагом Interval), подходящие под правило.
+// Дни представлены полночью UTC.
+func (r *Rule) candidates(start time.Time, period int) []time.Time {
+	base := date(start)
+	step := period * r.Interval
+
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		days = []time.Time{base.AddDate(0, 0, step)}
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			days = []time.Time{base.AddDate(0, 0, step*7)}
+			break
+		}
+		offset := (int(base.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := base.AddDate(0, 0, step*7-offset)
+		for i := 0; i < 7; i++ {
+			days = append(days, weekStart.AddDate(0, 0, i))
+		}
+	case Monthly:
+		first := time.Date(base.Year(), base.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		days = r.monthDays(first, base.Day())
+	case Yearly:
+		year := base.Year() + step
+		if len(r.ByMonthDay) == 0 {
+			// 29 февраля повторяется только в високосные годы.
+			days = r.monthDays(time.Date(year, base.Month(), 1, 0, 0, 0, 0, time.UTC), base.Day())
+			break
+		}
+		for month := time.January; month <= time.December; month++ {
+			days = append(days, r.monthDays(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), base.Day())...)
+		}
+	}
+
+	result := days[:0]
+	for _, day := range days {
+		if r.matches(day) && !slices.ContainsFunc(result, day.Equal) {
+			result = append(result, day)
+		}
+	}
+	slices.SortFunc(result, func(a, b time.Time) int { return a.Compare(b) })
+
+	return result
+}
+
+// monthDays раскрывает BYMONTHDAY и BYDAY внутри месяца, который начинается
+// с first. Без них повторение приходится на день startDay; если такого дня
+// в месяце нет, месяц пропускается.
+func (r *Rule) monthDays(first time.Time, startDay int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + 1 + day
+			}
+			if day >= 1 && day <= last {
+				days = append(days, first.AddDate(0, 0, day-1))
+			}
+		}
+	case len(r.ByDay) > 0:
+		for day := 1; day <= last; day++ {
+			days = append(days, first.AddDate(0, 0, day-1))
+		}
+	case startDay <= last:
+		days = append(days, first.AddDate(0, 0, startDay-1))
+	}
+
+	return days
+}
+
+// matches проверяет день по BYDAY и BYMONTHDAY. Для месячных правил
+// порядковые номера BYDAY считаются внутри месяца.
+func (r *Rule) matches(day time.Time) bool {
+	if len(r.ByDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		fromStart := (day.Day()-1)/7 + 1
+		fromEnd := -((last-day.Day())/7 + 1)
+
+		if !slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool {
+			return w.Day == day.Weekday() && (w.N == 0 || w.N == fromStart || w.N == fromEnd)
+		}) {
+			return false
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+		if !slices.ContainsFunc(r.ByMonthDay, func(d int) bool {
+			return d == day.Day() || last+1+d == day.Day()
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// date возвращает календарный день t в его часовом поясе как полночь UTC.
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// inLocation переносит дату и время t без изменения показаний часов
+// в часовой пояс loc.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
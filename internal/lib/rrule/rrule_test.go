@@ -0,0 +1,177 @@
+package rrule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/lib/rrule"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name      string
+		rule      string
+		canonical string
+		err       string
+	}{
+		{name: "Weekly", rule: "FREQ=WEEKLY;BYDAY=MO,WE", canonical: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{name: "Prefix and case", rule: "RRULE:freq=daily;interval=1;count=3", canonical: "FREQ=DAILY;COUNT=3"},
+		{name: "Monthly last friday", rule: "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20251231", canonical: "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20251231"},
+		{name: "Until UTC", rule: "FREQ=DAILY;UNTIL=20250601T090000Z;WKST=SU", canonical: "FREQ=DAILY;UNTIL=20250601T090000Z;WKST=SU"},
+		{name: "Empty", rule: "", err: "invalid rrule: empty rule"},
+		{name: "Missing freq", rule: "COUNT=3", err: "invalid rrule: FREQ is required"},
+		{name: "Unsupported freq", rule: "FREQ=HOURLY", err: `invalid rrule: FREQ: unsupported frequency "HOURLY"`},
+		{name: "Unsupported part", rule: "FREQ=DAILY;BYHOUR=9", err: "invalid rrule: unsupported part BYHOUR"},
+		{name: "Duplicate part", rule: "FREQ=DAILY;FREQ=WEEKLY", err: "invalid rrule: duplicate FREQ"},
+		{name: "Zero interval", rule: "FREQ=DAILY;INTERVAL=0", err: `invalid rrule: INTERVAL: must be a positive integer, got "0"`},
+		{name: "Count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20250101", err: "invalid rrule: COUNT and UNTIL are mutually exclusive"},
+		{name: "Invalid weekday", rule: "FREQ=WEEKLY;BYDAY=XX", err: `invalid rrule: BYDAY: invalid weekday "XX"`},
+		{name: "Numbered weekly", rule: "FREQ=WEEKLY;BYDAY=1MO", err: "invalid rrule: numbered BYDAY is only supported with FREQ=MONTHLY"},
+		{name: "Weekly month day", rule: "FREQ=WEEKLY;BYMONTHDAY=1", err: "invalid rrule: BYMONTHDAY is not allowed with FREQ=WEEKLY"},
+		{name: "Invalid month day", rule: "FREQ=MONTHLY;BYMONTHDAY=32", err: `invalid rrule: BYMONTHDAY: invalid month day "32"`},
+		{name: "Invalid until", rule: "FREQ=DAILY;UNTIL=2025-01-01", err: `invalid rrule: UNTIL: invalid date "2025-01-01", use YYYYMMDD or YYYYMMDDTHHMMSSZ`},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := rrule.Parse(tc.rule)
+			if tc.err != "" {
+				require.ErrorIs(t, err, rrule.ErrInvalidRule)
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.canonical, rule.String())
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		rule   string
+		start  time.Time
+		n      int
+		expect []string
+	}{
+		{
+			name:   "Daily with interval",
+			rule:   "FREQ=DAILY;INTERVAL=2",
+			start:  time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC),
+			n:      3,
+			expect: []string{"2025-04-19T09:00:00Z", "2025-04-21T09:00:00Z", "2025-04-23T09:00:00Z"},
+		},
+		{
+			name:   "Count includes start",
+			rule:   "FREQ=DAILY;COUNT=3",
+			start:  time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC),
+			n:      5,
+			expect: []string{"2025-04-18T09:00:00Z", "2025-04-19T09:00:00Z"},
+		},
+		{
+			name:   "Weekly by day",
+			rule:   "FREQ=WEEKLY;BYDAY=MO,TH",
+			start:  time.Date(2025, 4, 17, 18, 30, 0, 0, moscow), // четверг
+			n:      3,
+			expect: []string{"2025-04-21T18:30:00+03:00", "2025-04-24T18:30:00+03:00", "2025-04-28T18:30:00+03:00"},
+		},
+		{
+			name:   "Biweekly",
+			rule:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			start:  time.Date(2025, 4, 14, 8, 0, 0, 0, time.UTC), // понедельник
+			n:      3,
+			expect: []string{"2025-04-18T08:00:00Z", "2025-04-28T08:00:00Z", "2025-05-02T08:00:00Z"},
+		},
+		{
+			name:   "Weekly keeps local time across DST",
+			rule:   "FREQ=WEEKLY",
+			start:  time.Date(2025, 3, 24, 9, 0, 0, 0, berlin),
+			n:      1,
+			expect: []string{"2025-03-31T09:00:00+02:00"},
+		},
+		{
+			name:   "Monthly skips short months",
+			rule:   "FREQ=MONTHLY",
+			start:  time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			n:      3,
+			expect: []string{"2025-03-31T10:00:00Z", "2025-05-31T10:00:00Z", "2025-07-31T10:00:00Z"},
+		},
+		{
+			name:   "Monthly last day",
+			rule:   "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start:  time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			n:      2,
+			expect: []string{"2025-02-28T10:00:00Z", "2025-03-31T10:00:00Z"},
+		},
+		{
+			name:   "Monthly last friday",
+			rule:   "FREQ=MONTHLY;BYDAY=-1FR",
+			start:  time.Date(2025, 4, 25, 17, 0, 0, 0, time.UTC),
+			n:      2,
+			expect: []string{"2025-05-30T17:00:00Z", "2025-06-27T17:00:00Z"},
+		},
+		{
+			name:   "Friday the 13th",
+			rule:   "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			start:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			n:      2,
+			expect: []string{"2025-06-13T00:00:00Z", "2026-02-13T00:00:00Z"},
+		},
+		{
+			name:   "Yearly leap day",
+			rule:   "FREQ=YEARLY",
+			start:  time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+			n:      1,
+			expect: []string{"2028-02-29T12:00:00Z"},
+		},
+		{
+			name:   "Until date is inclusive",
+			rule:   "FREQ=DAILY;UNTIL=20250419",
+			start:  time.Date(2025, 4, 17, 23, 0, 0, 0, time.UTC),
+			n:      5,
+			expect: []string{"2025-04-18T23:00:00Z", "2025-04-19T23:00:00Z"},
+		},
+		{
+			name:   "Until UTC",
+			rule:   "FREQ=DAILY;UNTIL=20250419T060000Z",
+			start:  time.Date(2025, 4, 17, 9, 0, 0, 0, moscow),
+			n:      5,
+			expect: []string{"2025-04-18T09:00:00+03:00", "2025-04-19T09:00:00+03:00"},
+		},
+		{
+			name:  "Until before next occurrence",
+			rule:  "FREQ=YEARLY;BYMONTHDAY=31;UNTIL=20260101",
+			start: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			n:     1,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := rrule.Parse(tc.rule)
+			require.NoError(t, err)
+
+			var got []string
+			for _, occurrence := range rule.Occurrences(tc.start, tc.n) {
+				got = append(got, occurrence.Format(time.RFC3339))
+			}
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
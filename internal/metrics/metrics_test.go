@@ -0,0 +1,112 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/metrics"
+	"todo/internal/models"
+	"todo/internal/storage/memory"
+)
+
+func TestMiddlewareUsesRoutePattern(t *testing.T) {
+	m := metrics.New()
+
+	router := chi.NewRouter()
+	router.Use(m.Middleware)
+	router.Get("/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/tasks/1", "/tasks/2", "/other"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	require.Contains(t, body, `todo_http_requests_total{method="GET",route="/tasks/{id}",status="404"} 2`)
+	require.Contains(t, body, `todo_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.NotContains(t, body, `route="/tasks/1"`)
+}
+
+func TestWrapTaskServiceCountsEvents(t *testing.T) {
+	ctx := context.Background()
+	m := metrics.New()
+	store := memory.New()
+	service := m.WrapTaskService(store)
+
+	created, err := service.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+	require.NoError(t, err)
+	_, err = service.CreateTask(ctx, models.Task{Title: "subtask", DueDate: time.Now(), ParentID: &created.ID, RRule: "FREQ=DAILY"})
+	require.NoError(t, err)
+
+	// Задача выполняется вместе с повторяющейся подзадачей, которая создаёт
+	// следующее повторение.
+	created.Status = models.StatusDone
+	require.NoError(t, service.UpdateTask(ctx, created, models.SubtasksComplete))
+	// Повторное сохранение выполненной задачи не считается новым выполнением.
+	require.NoError(t, service.UpdateTask(ctx, created, models.SubtasksBlock))
+
+	// Вместе с задачей удаляются подзадача и её повторение.
+	require.NoError(t, service.DeleteTask(ctx, uint(created.ID), 0))
+
+	// Выполнение повторяющейся задачи создаёт следующее повторение.
+	recurring, err := service.CreateTask(ctx, models.Task{Title: "daily", DueDate: time.Now(), RRule: "FREQ=DAILY"})
+	require.NoError(t, err)
+	done := models.StatusDone
+	_, err = service.PatchTask(ctx, uint(recurring.ID), models.TaskPatch{Status: &done})
+	require.NoError(t, err)
+
+	// Задачи удаляются вместе с проектом.
+	project, err := store.CreateProject(ctx, models.Project{Name: "home"})
+	require.NoError(t, err)
+	_, err = service.CreateTask(ctx, models.Task{Title: "paint", DueDate: time.Now(), ProjectID: &project.ID})
+	require.NoError(t, err)
+	_, err = service.DeleteProject(ctx, uint(project.ID), models.ProjectDeleteCascade)
+	require.NoError(t, err)
+
+	body := scrape(t, m)
+	require.Contains(t, body, "todo_tasks_created_total 6")
+	require.Contains(t, body, "todo_tasks_completed_total 3")
+	require.Contains(t, body, "todo_tasks_deleted_total 4")
+}
+
+func TestWrapTaskServiceCountsDeletedSubtasks(t *testing.T) {
+	ctx := context.Background()
+	m := metrics.New()
+	service := m.WrapTaskService(memory.New())
+
+	root, err := service.CreateTask(ctx, models.Task{Title: "root", DueDate: time.Now()})
+	require.NoError(t, err)
+	child, err := service.CreateTask(ctx, models.Task{Title: "child", DueDate: time.Now(), ParentID: &root.ID})
+	require.NoError(t, err)
+	_, err = service.CreateTask(ctx, models.Task{Title: "grandchild", DueDate: time.Now(), ParentID: &child.ID})
+	require.NoError(t, err)
+
+	// Подзадача удаляется вместе со своей подзадачей.
+	require.NoError(t, service.DeleteTask(ctx, uint(child.ID), 0))
+	require.Contains(t, scrape(t, m), "todo_tasks_deleted_total 2")
+
+	require.NoError(t, service.DeleteTask(ctx, uint(root.ID), 0))
+	require.Contains(t, scrape(t, m), "todo_tasks_deleted_total 3")
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	body, err := io.ReadAll(rr.Body)
+	require.NoError(t, err)
+
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"context"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/models"
+)
+
+// taskService считает бизнес-события поверх хранилища задач. Методы, которые
+// не влияют на счётчики, передаются встроенному TaskService без изменений.
+type taskService struct {
+	handlers.TaskService
+	metrics *Metrics
+}
+
+// WrapTaskService возвращает TaskService, который увеличивает счётчики
+// созданных, выполненных и удалённых задач.
+func (m *Metrics) WrapTaskService(service handlers.TaskService) handlers.TaskService {
+	return &taskService{
+		TaskService: service,
+		metrics:     m,
+	}
+}
+
+func (s *taskService) CreateTask(ctx context.Context, task models.Task) (*models.Task, error) {
+	created, err := s.TaskService.CreateTask(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.tasksCreated.Inc()
+	if created.Status == models.StatusDone {
+		s.metrics.tasksCompleted.Inc()
+	}
+	if created.NextID != nil {
+		s.metrics.tasksCreated.Inc()
+	}
+
+	return created, nil
+}
+
+func (s *taskService) UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	// PUT передаёт задачу целиком, поэтому, чтобы посчитать только переход
+	// в выполненные и созданное повторение, нужно знать прежнее состояние.
+	wasCompleted := false
+	var nextID *int64
+	if task.Status.Closed() {
+		if current, err := s.TaskService.GetByID(ctx, uint(task.ID)); err == nil {
+			wasCompleted = current.Status == models.StatusDone
+			nextID = current.NextID
+		}
+	}
+
+	var subtasks, total int
+	if task.Status == models.StatusDone && children == models.SubtasksComplete {
+		subtasks, total = s.openSubtasks(ctx, task.ID)
+	}
+
+	if err := s.TaskService.UpdateTask(ctx, task, children); err != nil {
+		return err
+	}
+
+	if task.Status == models.StatusDone && !wasCompleted {
+		s.metrics.tasksCompleted.Inc()
+	}
+	s.metrics.tasksCompleted.Add(float64(subtasks))
+	if spawned(nextID, task.NextID) {
+		s.metrics.tasksCreated.Inc()
+	}
+	if subtasks > 0 {
+		s.metrics.tasksCreated.Add(float64(s.spawnedSubtasks(ctx, task.ID, total)))
+	}
+
+	return nil
+}
+
+func (s *taskService) PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	// Следующее повторение создаётся при закрытии задачи или при смене
+	// правила у закрытой задачи.
+	var nextID *int64
+	if patch.Status != nil && patch.Status.Closed() || patch.RRule != nil && *patch.RRule != "" {
+		if current, err := s.TaskService.GetByID(ctx, id); err == nil {
+			nextID = current.NextID
+		}
+	}
+
+	var subtasks, total int
+	if patch.Status != nil && *patch.Status == models.StatusDone && patch.Children == models.SubtasksComplete {
+		subtasks, total = s.openSubtasks(ctx, int64(id))
+	}
+
+	task, err := s.TaskService.PatchTask(ctx, id, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Патч содержит только изменившиеся поля, так что Status == done
+	// означает переход в выполненные.
+	if patch.Status != nil && *patch.Status == models.StatusDone {
+		s.metrics.tasksCompleted.Inc()
+	}
+	s.metrics.tasksCompleted.Add(float64(subtasks))
+	if spawned(nextID, task.NextID) {
+		s.metrics.tasksCreated.Inc()
+	}
+	if subtasks > 0 {
+		s.metrics.tasksCreated.Add(float64(s.spawnedSubtasks(ctx, task.ID, total)))
+	}
+
+	return task, nil
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, id uint, version int64) error {
+	// Вместе с задачей каскадом удаляются её подзадачи, поэтому дерево
+	// нужно прочитать до удаления. Если это не удалось, считается только
+	// сама задача.
+	deleted := 1
+	if subtree, err := s.TaskService.Subtree(ctx, id); err == nil && len(subtree) > 0 {
+		deleted = len(subtree)
+	}
+
+	if err := s.TaskService.DeleteTask(ctx, id, version); err != nil {
+		return err
+	}
+
+	s.metrics.tasksDeleted.Add(float64(deleted))
+
+	return nil
+}
+
+func (s *taskService) DeleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error) {
+	tasks, err := s.TaskService.DeleteProject(ctx, id, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == models.ProjectDeleteCascade {
+		s.metrics.tasksDeleted.Add(float64(len(tasks)))
+	}
+
+	return tasks, nil
+}
+
+// openSubtasks считает открытые подзадачи задачи id: с SubtasksComplete
+// хранилище выполняет их вместе с задачей. Вторым значением возвращается
+// размер всего дерева задачи. Если подзадачи прочитать не удалось, они не
+// учитываются.
+func (s *taskService) openSubtasks(ctx context.Context, id int64) (open, total int) {
+	tasks, err := s.TaskService.Subtree(ctx, uint(id))
+	if err != nil {
+		return 0, 0
+	}
+
+	for _, task := range tasks {
+		if task.ID != id && !task.Status.Closed() {
+			open++
+		}
+	}
+
+	return open, len(tasks)
+}
+
+// spawnedSubtasks считает повторения, созданные при выполнении подзадач
+// задачи id: они остаются в её дереве, которое до изменения содержало
+// total задач.
+func (s *taskService) spawnedSubtasks(ctx context.Context, id int64, total int) int {
+	tasks, err := s.TaskService.Subtree(ctx, uint(id))
+	if err != nil || len(tasks) < total {
+		return 0
+	}
+
+	return len(tasks) - total
+}
+
+// spawned сообщает, что изменение задачи создало её следующее повторение:
+// ссылка next_id появилась или сменилась.
+func spawned(before, after *int64) bool {
+	return after != nil && (before == nil || *before != *after)
+}
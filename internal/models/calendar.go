@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// FeedToken — токен доступа к календарю задач GET /tasks.ics и CalDAV. Токены
+// выпускаются отдельно для каждого человека или приложения, чтобы любой
+// можно было отозвать, не трогая остальные. Хранилище держит только хеш
+// токена, сам токен возвращается один раз при создании.
+// @Description Токен календаря задач
+type FeedToken struct {
+	ID        int64     `json:"id" example:"1"`                                                             // Уникальный идентификатор токена
+	Name      string    `json:"name" validate:"required,max=100" example:"Календарь на телефоне"`           // Для кого или для какого приложения выпущен токен
+	Token     string    `json:"token,omitempty" example:"9b2f4c1d7e3a5b6c8d0e2f4a6b8c0d1e3f5a7b9c1d3e5f7a"` // Токен; возвращается только при создании
+	CreatedAt time.Time `json:"created_at" example:"2025-04-17T10:30:00Z"`                                  // Дата создания
+}
+
+// CalendarObject связывает задачу с ресурсом CalDAV, который создал
+// клиент: клиент сам выбирает имя файла .ics и UID задачи и ждёт, что
+// сервер вернёт их без изменений. Задачи, созданные через API, такой
+// связи не имеют и доступны под именем по умолчанию.
+type CalendarObject struct {
+	TaskID int64  // Задача
+	Name   string // Имя ресурса в коллекции: 0d2f6c9e.ics
+	UID    string // UID задачи в календаре клиента
+}
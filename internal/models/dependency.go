@@ -0,0 +1,100 @@
+package models
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// TaskDependency — связь «задачу TaskID нельзя начать, пока не выполнена
+// BlockerID».
+type TaskDependency struct {
+	TaskID    int64
+	BlockerID int64
+}
+
+// OrderByDependencies упорядочивает задачи так, чтобы каждая шла после
+// своих блокирующих задач, а среди доступных в данный момент первой шла
+// задача с ближайшим сроком (при равенстве — с меньшим id). Зависимости
+// от задач, которых нет в tasks, не учитываются. Задачи, оставшиеся в
+// цикле, добавляются в конец по сроку.
+func OrderByDependencies(tasks []Task, deps []TaskDependency) []Task {
+	index := make(map[int64]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+	}
+
+	waiting := make([]int, len(tasks))
+	dependents := make(map[int64][]int)
+	for _, dep := range deps {
+		task, ok := index[dep.TaskID]
+		if _, blocker := index[dep.BlockerID]; !ok || !blocker {
+			continue
+		}
+		waiting[task]++
+		dependents[dep.BlockerID] = append(dependents[dep.BlockerID], task)
+	}
+
+	ready := &taskQueue{tasks: tasks}
+	for i := range tasks {
+		if waiting[i] == 0 {
+			ready.items = append(ready.items, i)
+		}
+	}
+	heap.Init(ready)
+
+	ordered := make([]Task, 0, len(tasks))
+	placed := make([]bool, len(tasks))
+	for ready.Len() > 0 {
+		i := heap.Pop(ready).(int)
+		ordered = append(ordered, tasks[i])
+		placed[i] = true
+
+		for _, dependent := range dependents[tasks[i].ID] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				heap.Push(ready, dependent)
+			}
+		}
+	}
+
+	if len(ordered) < len(tasks) {
+		rest := &taskQueue{tasks: tasks}
+		for i := range tasks {
+			if !placed[i] {
+				rest.items = append(rest.items, i)
+			}
+		}
+		sort.Sort(rest)
+		for _, i := range rest.items {
+			ordered = append(ordered, tasks[i])
+		}
+	}
+
+	return ordered
+}
+
+// taskQueue — очередь индексов задач по сроку выполнения, затем по id.
+type taskQueue struct {
+	tasks []Task
+	items []int
+}
+
+func (q *taskQueue) Len() int { return len(q.items) }
+
+func (q *taskQueue) Less(i, j int) bool {
+	a, b := q.tasks[q.items[i]], q.tasks[q.items[j]]
+	if a.DueDate.Equal(b.DueDate) {
+		return a.ID < b.ID
+	}
+	return a.DueDate.Before(b.DueDate)
+}
+
+func (q *taskQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *taskQueue) Push(x any) { q.items = append(q.items, x.(int)) }
+
+func (q *taskQueue) Pop() any {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
@@ -0,0 +1,42 @@
+package models
+
+import "slices"
+
+// TaskEvent — запись журнала изменений задач, которую получают клиенты
+// потока GET /tasks/events. ID возрастает и служит Last-Event-ID.
+type TaskEvent struct {
+	ID int64 `json:"id"`
+	WebhookEvent
+}
+
+// TaskEventFilter отбирает события потока по задаче в том состоянии, в
+// котором она записана в событии. Пустые поля не ограничивают выборку.
+type TaskEventFilter struct {
+	// ProjectID оставляет только события задач указанного проекта.
+	ProjectID *int64
+	// Tags оставляет только события задач хотя бы с одной из меток.
+	Tags []string
+	// Statuses оставляет только события задач с одним из статусов.
+	Statuses []TaskStatus
+}
+
+// Match сообщает, что событие проходит фильтр.
+func (f TaskEventFilter) Match(event TaskEvent) bool {
+	task := event.Task
+
+	if f.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *f.ProjectID) {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, task.Status) {
+		return false
+	}
+
+	if len(f.Tags) > 0 && !slices.ContainsFunc(task.Tags, func(tag Tag) bool {
+		return slices.Contains(f.Tags, tag.Name)
+	}) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,36 @@
+package models
+
+// ImportRowStatus — чем закончилась обработка строки импорта.
+type ImportRowStatus string
+
+const (
+	// ImportCreated — задача создана; в пробном импорте — прошла проверку
+	// и была бы создана.
+	ImportCreated ImportRowStatus = "created"
+	// ImportSkipped — строка пропущена: она пустая или задача с её id уже
+	// есть.
+	ImportSkipped ImportRowStatus = "skipped"
+	// ImportFailed — строка не прошла проверку или задачу не удалось
+	// создать.
+	ImportFailed ImportRowStatus = "failed"
+)
+
+// TaskImportReport — итог импорта задач из CSV.
+// @Description Отчёт об импорте задач
+type TaskImportReport struct {
+	DryRun         bool            `json:"dry_run" example:"false"`              // Пробный импорт: строки проверены, но задачи не созданы
+	Created        int             `json:"created" example:"2"`                  // Сколько задач создано (в пробном импорте — было бы создано)
+	Skipped        int             `json:"skipped" example:"1"`                  // Сколько строк пропущено
+	Failed         int             `json:"failed" example:"1"`                   // Сколько строк с ошибками
+	IgnoredColumns []string        `json:"ignored_columns" example:"created_at"` // Столбцы, которые не сопоставлены ни с одним полем задачи
+	Rows           []TaskImportRow `json:"rows"`                                 // Результат по каждой строке
+}
+
+// TaskImportRow — результат импорта одной строки CSV.
+// @Description Результат импорта строки
+type TaskImportRow struct {
+	Line   int             `json:"line" example:"2"`                                              // Номер строки в файле; заголовок — строка 1
+	Status ImportRowStatus `json:"status" example:"failed" enums:"created,skipped,failed"`        // Результат
+	TaskID int64           `json:"task_id,omitempty" example:"7"`                                 // Созданная или уже существующая задача
+	Reason string          `json:"reason,omitempty" example:"field due_date is a required field"` // Причина пропуска или ошибки
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Project представляет проект — список, в который группируются задачи
+// @Description Проект с задачами
+type Project struct {
+	ID          int64     `json:"id" example:"1"`                                          // Уникальный идентификатор проекта
+	Name        string    `json:"name" validate:"required,max=128" example:"Ремонт"`       // Название проекта
+	Description string    `json:"description" example:"Всё, что нужно сделать в квартире"` // Описание проекта
+	Archived    bool      `json:"archived" example:"false"`                                // Проект в архиве
+	Position    int       `json:"position" example:"0"`                                    // Порядок в списке проектов, меньшие значения идут первыми
+	CreatedAt   time.Time `json:"created_at" example:"2025-04-17T10:30:00Z"`               // Дата создания
+	UpdatedAt   time.Time `json:"updated_at" example:"2025-04-17T10:30:00Z"`               // Дата обновления
+}
+
+// ProjectPatch описывает частичное обновление проекта: изменяются только
+// переданные поля.
+// @Description Изменение проекта
+type ProjectPatch struct {
+	Name        *string `json:"name,omitempty" validate:"omitnil,min=1,max=128" example:"Переезд"` // Новое название проекта
+	Description *string `json:"description,omitempty" example:"Коробки, грузчики, новый адрес"`    // Новое описание проекта
+	Archived    *bool   `json:"archived,omitempty" example:"true"`                                 // Перенести проект в архив или вернуть из него
+	Position    *int    `json:"position,omitempty" example:"2"`                                    // Новое место в списке проектов
+}
+
+// ProjectDeletePolicy задаёт, что происходит с задачами удаляемого проекта.
+type ProjectDeletePolicy string
+
+const (
+	// ProjectDeleteRefuse запрещает удалять проект, в котором есть задачи.
+	ProjectDeleteRefuse ProjectDeletePolicy = "refuse"
+	// ProjectDeleteInbox переносит задачи проекта во входящие.
+	ProjectDeleteInbox ProjectDeletePolicy = "inbox"
+	// ProjectDeleteCascade удаляет задачи вместе с проектом.
+	ProjectDeleteCascade ProjectDeletePolicy = "cascade"
+)
+
+// Valid сообщает, что p — одна из известных политик удаления.
+func (p ProjectDeletePolicy) Valid() bool {
+	switch p {
+	case ProjectDeleteRefuse, ProjectDeleteInbox, ProjectDeleteCascade:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"todo/internal/lib/rrule"
+)
+
+var (
+	// ErrNotRecurring означает, что у задачи нет правила повторения.
+	ErrNotRecurring = errors.New("task is not recurring")
+	// ErrSeriesEnded означает, что после срока задачи повторений больше нет.
+	ErrSeriesEnded = errors.New("recurrence has no more occurrences")
+)
+
+// Recurrence разбирает правило повторения задачи и её часовой пояс.
+// Пустой Timezone означает UTC.
+func (t Task) Recurrence() (*rrule.Rule, *time.Location, error) {
+	if t.RRule == "" {
+		return nil, nil, ErrNotRecurring
+	}
+
+	rule, err := rrule.Parse(t.RRule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rule, loc, nil
+}
+
+// Occurrences возвращает до n сроков повторений задачи после её DueDate.
+func (t Task) Occurrences(n int) ([]time.Time, error) {
+	rule, loc, err := t.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.Occurrences(t.DueDate.In(loc), n), nil
+}
+
+// NextOccurrence возвращает следующее повторение задачи: невыполненную копию
+// со сроком ближайшего повторения после DueDate. COUNT в правиле копии
+// уменьшается на единицу, так что он всегда означает число оставшихся
+// повторений вместе с текущим. ok == false, если серия закончилась.
+// Подзадачи и зависимости не копируются.
+func (t Task) NextOccurrence() (next Task, ok bool, err error) {
+	rule, loc, err := t.Recurrence()
+	if err != nil {
+		return Task{}, false, err
+	}
+
+	occurrences := rule.Occurrences(t.DueDate.In(loc), 1)
+	if len(occurrences) == 0 {
+		return Task{}, false, nil
+	}
+
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	next = Task{
+		Title:       t.Title,
+		Description: t.Description,
+		DueDate:     occurrences[0],
+		Priority:    t.Priority,
+		Tags:        t.Tags,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+		RRule:       rule.String(),
+		Timezone:    t.Timezone,
+	}
+
+	return next, true, nil
+}
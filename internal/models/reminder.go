@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Reminder — напоминание о задаче. Момент срабатывания задаётся либо
+// абсолютно (remind_at), либо смещением before до срока задачи; во втором
+// случае напоминание переносится вместе со сроком.
+// @Description Напоминание о задаче
+type Reminder struct {
+	ID        int64      `json:"id" example:"1"`                                                // Уникальный идентификатор напоминания
+	TaskID    int64      `json:"task_id" example:"1"`                                           // Задача, о которой напоминание
+	RemindAt  *time.Time `json:"remind_at,omitempty" example:"2025-04-20T09:00:00Z"`            // Момент напоминания
+	Before    *Duration  `json:"before,omitempty" swaggertype:"string" example:"15m"`           // Напомнить за это время до срока задачи
+	FireAt    time.Time  `json:"fire_at" example:"2025-04-20T14:45:00Z"`                        // Когда напоминание сработает
+	SentAt    *time.Time `json:"sent_at" example:"2025-04-20T14:45:02Z"`                        // Когда напоминание доставлено, null — ещё не доставлено
+	Attempts  int        `json:"attempts" example:"0"`                                          // Число неудачных попыток доставки
+	LastError string     `json:"last_error,omitempty" example:"webhook: unexpected status 502"` // Ошибка последней неудачной попытки
+	CreatedAt time.Time  `json:"created_at" example:"2025-04-17T10:30:00Z"`                     // Дата создания
+}
+
+// FireTime возвращает момент срабатывания напоминания для задачи со сроком
+// due.
+func (r Reminder) FireTime(due time.Time) time.Time {
+	if r.RemindAt != nil {
+		return *r.RemindAt
+	}
+	return due.Add(-time.Duration(*r.Before))
+}
+
+// Validate проверяет, что напоминание задано ровно одним способом, а
+// смещение не отрицательно и задано с точностью до секунды. Текст ошибки
+// предназначен для клиента.
+func (r Reminder) Validate() error {
+	if (r.RemindAt == nil) == (r.Before == nil) {
+		return errors.New("set either remind_at or before")
+	}
+	if r.Before != nil && *r.Before < 0 {
+		return errors.New("before must not be negative")
+	}
+	if r.Before != nil && time.Duration(*r.Before)%time.Second != 0 {
+		return errors.New("before must be a whole number of seconds")
+	}
+	return nil
+}
+
+// Duration — длительность, которая в JSON записывается строкой в формате
+// time.ParseDuration, например "1h30m".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+// ReminderClaim описывает выборку сработавших напоминаний для доставки.
+type ReminderClaim struct {
+	// Now — текущее время: выбираются напоминания с fire_at не позже него.
+	Now time.Time
+	// Lease — на сколько напоминание закрепляется за выбравшим его
+	// экземпляром. Если за это время оно не отмечено доставленным или
+	// неудачным, его выберет следующий опрос.
+	Lease time.Duration
+	// Limit ограничивает число выбранных напоминаний.
+	Limit int
+	// MaxAttempts — после стольких неудачных попыток напоминание больше
+	// не доставляется.
+	MaxAttempts int
+}
+
+// Notification — сработавшее напоминание вместе с задачей, о которой оно
+// напоминает. Так его получают уведомители.
+type Notification struct {
+	Reminder Reminder `json:"reminder"`
+	Task     Task     `json:"task"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// TaskStatus — этап работы над задачей. Хранилища сохраняют пустое
+// значение как StatusTodo.
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusBlocked    TaskStatus = "blocked"
+	StatusDone       TaskStatus = "done"
+	StatusCancelled  TaskStatus = "cancelled"
+)
+
+// Statuses перечисляет все статусы задачи.
+var Statuses = []TaskStatus{StatusTodo, StatusInProgress, StatusBlocked, StatusDone, StatusCancelled}
+
+// ErrInvalidTransition означает, что процесс не разрешает перевести задачу
+// в новый статус из текущего.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Valid сообщает, что s — один из известных статусов.
+func (s TaskStatus) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OrTodo возвращает StatusTodo вместо пустого статуса.
+func (s TaskStatus) OrTodo() TaskStatus {
+	if s == "" {
+		return StatusTodo
+	}
+	return s
+}
+
+// Closed сообщает, что работа над задачей закончена: она выполнена или
+// отменена. Закрытая задача не блокирует зависимые и не мешает выполнить
+// родителя.
+func (s TaskStatus) Closed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// UnmarshalJSON принимает и прежний булев статус: true означает done,
+// false — todo.
+func (s *TaskStatus) UnmarshalJSON(data []byte) error {
+	var completed bool
+	if err := json.Unmarshal(data, &completed); err == nil {
+		*s = StatusTodo
+		if completed {
+			*s = StatusDone
+		}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = TaskStatus(value)
+
+	return nil
+}
+
+// Workflow задаёт допустимые переходы между статусами: для каждого статуса —
+// статусы, в которые из него можно перейти.
+type Workflow map[TaskStatus][]TaskStatus
+
+// DefaultWorkflow разрешает начинать, откладывать, выполнять и отменять
+// открытые задачи и возвращать закрытые в работу.
+var DefaultWorkflow = Workflow{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusCancelled},
+	StatusBlocked:    {StatusTodo, StatusInProgress, StatusCancelled},
+	StatusDone:       {StatusTodo, StatusInProgress},
+	StatusCancelled:  {StatusTodo},
+}
+
+// NewWorkflow строит процесс из конфигурации. Пустая конфигурация означает
+// DefaultWorkflow.
+func NewWorkflow(transitions map[string][]string) (Workflow, error) {
+	if len(transitions) == 0 {
+		return DefaultWorkflow, nil
+	}
+
+	workflow := make(Workflow, len(transitions))
+	for from, targets := range transitions {
+		status := TaskStatus(from)
+		if !status.Valid() {
+			return nil, fmt.Errorf("unknown status %q", from)
+		}
+		for _, to := range targets {
+			target := TaskStatus(to)
+			if !target.Valid() {
+				return nil, fmt.Errorf("unknown status %q in transitions from %q", to, from)
+			}
+			workflow[status] = append(workflow[status], target)
+		}
+	}
+
+	return workflow, nil
+}
+
+// Check возвращает ErrInvalidTransition, если задачу нельзя перевести из
+// from в to. Сохранение статуса без изменения разрешено всегда.
+func (w Workflow) Check(from, to TaskStatus) error {
+	from, to = from.OrTodo(), to.OrTodo()
+	if from == to {
+		return nil
+	}
+
+	for _, allowed := range w[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, to)
+}
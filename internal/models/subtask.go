@@ -0,0 +1,83 @@
+package models
+
+// TaskProgress описывает выполнение подзадач задачи
+// @Description Прогресс по подзадачам
+type TaskProgress struct {
+	Children  int `json:"children" example:"2"`  // Количество прямых подзадач
+	Subtasks  int `json:"subtasks" example:"5"`  // Количество подзадач на всех уровнях вложенности
+	Completed int `json:"completed" example:"3"` // Количество выполненных подзадач на всех уровнях
+	Percent   int `json:"percent" example:"60"`  // Процент выполненных подзадач; у задачи без подзадач — 0 или 100 по её статусу
+}
+
+// NewTaskProgress считает процент выполнения. done — статус самой задачи,
+// он определяет процент, если подзадач нет.
+func NewTaskProgress(children, subtasks, completed int, done bool) TaskProgress {
+	progress := TaskProgress{
+		Children:  children,
+		Subtasks:  subtasks,
+		Completed: completed,
+	}
+
+	switch {
+	case subtasks > 0:
+		progress.Percent = completed * 100 / subtasks
+	case done:
+		progress.Percent = 100
+	}
+
+	return progress
+}
+
+// TaskTree — задача вместе со всеми подзадачами
+// @Description Задача с деревом подзадач
+type TaskTree struct {
+	Task
+	Children []TaskTree `json:"children"` // Прямые подзадачи со своими подзадачами
+}
+
+// BuildTaskTree собирает дерево с корнем rootID из плоского списка задач
+// поддерева. Порядок подзадач совпадает с порядком в tasks.
+func BuildTaskTree(rootID int64, tasks []Task) (*TaskTree, bool) {
+	children := make(map[int64][]Task, len(tasks))
+	var root *Task
+
+	for i := range tasks {
+		if tasks[i].ID == rootID {
+			root = &tasks[i]
+			continue
+		}
+		if tasks[i].ParentID != nil {
+			children[*tasks[i].ParentID] = append(children[*tasks[i].ParentID], tasks[i])
+		}
+	}
+
+	if root == nil {
+		return nil, false
+	}
+
+	var build func(task Task) TaskTree
+	build = func(task Task) TaskTree {
+		node := TaskTree{Task: task, Children: []TaskTree{}}
+		for _, child := range children[task.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := build(*root)
+
+	return &tree, true
+}
+
+// SubtaskPolicy задаёт, что происходит при выполнении задачи с
+// невыполненными подзадачами.
+type SubtaskPolicy string
+
+const (
+	// SubtasksBlock запрещает выполнять задачу, пока открыты подзадачи.
+	SubtasksBlock SubtaskPolicy = "block"
+	// SubtasksComplete выполняет все открытые подзадачи вместе с задачей
+	// в одной транзакции: если изменение задачи отклонено, подзадачи
+	// остаются открытыми.
+	SubtasksComplete SubtaskPolicy = "complete"
+)
@@ -0,0 +1,43 @@
+package models
+
+// Tag представляет метку задачи
+// @Description Метка для группировки задач
+type Tag struct {
+	ID    int64  `json:"id" example:"1"`                                        // Уникальный идентификатор метки
+	Name  string `json:"name" validate:"required,max=64" example:"backend"`     // Название метки, уникально
+	Color string `json:"color" validate:"omitempty,hexcolor" example:"#1e90ff"` // Цвет метки в формате #RRGGBB
+}
+
+// TagPatch описывает частичное обновление метки: изменяются только
+// переданные поля.
+// @Description Изменение метки
+type TagPatch struct {
+	Name  *string `json:"name,omitempty" validate:"omitnil,min=1,max=64" example:"frontend"`   // Новое название метки
+	Color *string `json:"color,omitempty" validate:"omitnil,hexcolor|len=0" example:"#ff4500"` // Новый цвет метки, пустая строка убирает цвет
+}
+
+// TagMatch задаёт, как фильтр по нескольким меткам сопоставляется с задачей.
+type TagMatch string
+
+const (
+	// TagMatchAny оставляет задачи, у которых есть хотя бы одна из меток.
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll оставляет задачи, у которых есть все метки.
+	TagMatchAll TagMatch = "all"
+)
+
+// TagNames возвращает названия меток без повторов в исходном порядке.
+func TagNames(tags []Tag) []string {
+	seen := make(map[string]bool, len(tags))
+	names := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		if seen[tag.Name] {
+			continue
+		}
+		seen[tag.Name] = true
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
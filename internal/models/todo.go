@@ -9,9 +9,21 @@ type Task struct {
 	Title       string    `json:"title" validate:"required" example:"Купить молоко"` // Заголовок задачи
 	Description string    `json:"description" example:"Купить 2 литра молока в магазине"` // Описание задачи
 	DueDate     time.Time `json:"due_date" validate:"required" example:"2025-04-20T15:00:00Z"` // Дата выполнения
-	Status      bool      `json:"status" example:"false"` // Статус выполнения (true - выполнена, false - не выполнена)
+	Status      TaskStatus `json:"status" validate:"omitempty,oneof=todo in_progress blocked done cancelled" example:"in_progress"` // Статус задачи (по умолчанию todo); для совместимости принимается и true/false — done/todo
+	Priority    Priority  `json:"priority" validate:"omitempty,oneof=none low medium high urgent" example:"high"` // Приоритет задачи (по умолчанию none)
 	CreatedAt   time.Time `json:"created_at" example:"2025-04-17T10:30:00Z"` // Дата создания
 	UpdatedAt   time.Time `json:"updated_at" example:"2025-04-17T10:30:00Z"` // Дата обновления
+	CompletedAt *time.Time `json:"completed_at" example:"2025-04-18T12:00:00Z"` // Когда задача перешла в done; null, если она не выполнена
+	CancelledAt *time.Time `json:"cancelled_at" example:"2025-04-18T12:00:00Z"` // Когда задача перешла в cancelled; null, если она не отменена
+	Version     int64     `json:"version" example:"1"` // Версия задачи, увеличивается при каждом изменении (ETag)
+	Tags        []Tag     `json:"tags" validate:"dive"` // Метки задачи; при создании и обновлении метки ищутся по названию, отсутствующие создаются
+	ProjectID   *int64    `json:"project_id" validate:"omitnil,min=1" example:"1"` // Проект задачи; null — задача во входящих
+	ParentID    *int64    `json:"parent_id" validate:"omitnil,min=1" example:"1"` // Родительская задача; null — задача верхнего уровня
+	RRule       string    `json:"rrule" validate:"max=255" example:"FREQ=WEEKLY;BYDAY=MO"` // Правило повторения RFC 5545 (FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL); пустое — задача не повторяется
+	Timezone    string    `json:"timezone" validate:"omitempty,timezone" example:"Europe/Moscow"` // Часовой пояс IANA, в котором вычисляются повторения; пустой — UTC
+	NextID      *int64    `json:"next_id" example:"7"` // Следующее повторение, созданное при выполнении задачи; при создании и обновлении игнорируется
+	Blocked     bool      `json:"blocked" example:"false"` // Задачу блокирует хотя бы одна невыполненная задача; вычисляется, при создании и обновлении игнорируется
+	Progress    *TaskProgress `json:"progress,omitempty"` // Прогресс по подзадачам, только для GET /tasks/{id}?include=progress
 }
 
 // TasksList представляет список задач с пагинацией
@@ -23,7 +35,110 @@ type TasksList struct {
 	Limit int    `json:"limit" example:"10"` // Количество элементов на странице
 }
 
+// Priority — важность задачи. Хранилища сохраняют пустое значение как
+// PriorityNone.
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// priorityRanks задаёт порядок приоритетов: чем больше, тем важнее.
+var priorityRanks = map[Priority]int{
+	PriorityNone:   0,
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+// Valid сообщает, что p — один из известных приоритетов.
+func (p Priority) Valid() bool {
+	_, ok := priorityRanks[p]
+	return ok
+}
+
+// OrNone возвращает PriorityNone вместо пустого приоритета.
+func (p Priority) OrNone() Priority {
+	if p == "" {
+		return PriorityNone
+	}
+	return p
+}
+
+// Rank возвращает вес приоритета для сортировки: от 0 у none до 4 у urgent.
+func (p Priority) Rank() int {
+	return priorityRanks[p]
+}
+
+// TaskSort задаёт порядок задач в списке.
+type TaskSort string
+
+const (
+	// SortByDueDate упорядочивает задачи по сроку выполнения.
+	SortByDueDate TaskSort = "due_date"
+	// SortByPriority упорядочивает задачи от самых важных к менее важным,
+	// а при равном приоритете — по сроку выполнения.
+	SortByPriority TaskSort = "priority"
+)
+
+// TaskFilter описывает страницу списка задач. Nil-поля не ограничивают
+// выборку, пустой Sort означает SortByDueDate.
+type TaskFilter struct {
+	Page  int
+	Limit int
+	// Completed оставляет только закрытые (done или cancelled) или только
+	// открытые задачи.
+	Completed *bool
+	// Statuses оставляет только задачи с одним из статусов.
+	Statuses []TaskStatus
+	Date      *time.Time
+	Priority  *Priority
+	// Tags содержит названия меток без повторов, TagMatch — режим их
+	// сопоставления (пустой означает TagMatchAny).
+	Tags     []string
+	TagMatch TagMatch
+	// ProjectID оставляет только задачи указанного проекта.
+	ProjectID *int64
+	// ParentID оставляет только прямые подзадачи указанной задачи.
+	ParentID *int64
+	// Blocked оставляет только заблокированные или только доступные задачи.
+	Blocked *bool
+	Sort      TaskSort
+}
+
 type Response struct {
 	Status string `json:"status"`
 	Error  string `json:"error,omitempty"`
 }
+
+// TaskPatch описывает частичное обновление задачи: изменяются только
+// поля с ненулевыми указателями. Ненулевой Version включает проверку
+// версии задачи перед изменением. ProjectID, указывающий на 0, переносит
+// задачу во входящие, ParentID, указывающий на 0, делает задачу задачей
+// верхнего уровня, пустой RRule завершает серию повторений. Children
+// задаёт, что делать с открытыми подзадачами, если патч выполняет задачу.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	DueDate     *time.Time
+	Status      *TaskStatus
+	Priority    *Priority
+	Tags        *[]Tag
+	ProjectID   *int64
+	ParentID    *int64
+	RRule       *string
+	Timezone    *string
+	Version     int64
+	Children    SubtaskPolicy
+}
+
+// IsEmpty сообщает, что патч не изменяет ни одного поля.
+func (p TaskPatch) IsEmpty() bool {
+	return p.Title == nil && p.Description == nil && p.DueDate == nil && p.Status == nil && p.Priority == nil &&
+		p.Tags == nil && p.ProjectID == nil && p.ParentID == nil && p.RRule == nil && p.Timezone == nil
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookEventType — событие жизненного цикла задачи, на которое можно
+// подписаться.
+type WebhookEventType string
+
+const (
+	EventTaskCreated WebhookEventType = "task.created"
+	EventTaskUpdated WebhookEventType = "task.updated"
+	// EventTaskCompleted отправляется, когда задача переходит в done,
+	// вместе с EventTaskUpdated.
+	EventTaskCompleted WebhookEventType = "task.completed"
+	EventTaskDeleted   WebhookEventType = "task.deleted"
+)
+
+// WebhookEventTypes — все события в порядке, в котором их перечисляет
+// документация. Подписка без фильтра получает их все.
+var WebhookEventTypes = []WebhookEventType{EventTaskCreated, EventTaskUpdated, EventTaskCompleted, EventTaskDeleted}
+
+// SortEvents возвращает события без повторов в порядке WebhookEventTypes.
+// Пустой список означает все события.
+func SortEvents(events []WebhookEventType) []WebhookEventType {
+	if len(events) == 0 {
+		return slices.Clone(WebhookEventTypes)
+	}
+
+	sorted := make([]WebhookEventType, 0, len(events))
+	for _, event := range WebhookEventTypes {
+		if slices.Contains(events, event) {
+			sorted = append(sorted, event)
+		}
+	}
+
+	return sorted
+}
+
+// Webhook — подписка на события задач. Тело каждого запроса подписывается
+// HMAC-SHA256 с ключом Secret.
+// @Description Подписка на события задач
+type Webhook struct {
+	ID        int64              `json:"id" example:"1"`                                                                                            // Уникальный идентификатор подписки
+	URL       string             `json:"url" validate:"required,http_url,max=2048" example:"https://ci.example.com/hooks/todo"`                     // Адрес, на который POST-запросом отправляются события
+	Secret    string             `json:"secret,omitempty" validate:"omitempty,min=16,max=256" example:"3f9c1b7e5d2a4c6e8b0d1f3a5c7e9b1d"`           // Ключ подписи; если не задан, генерируется. Возвращается только при создании
+	Events    []WebhookEventType `json:"events" validate:"dive,oneof=task.created task.updated task.completed task.deleted" example:"task.created"` // События, на которые подписка; пустой список — все события
+	CreatedAt time.Time          `json:"created_at" example:"2025-04-17T10:30:00Z"`                                                                 // Дата создания
+}
+
+// WebhookEvent — тело запроса, которое получает подписчик.
+type WebhookEvent struct {
+	Type       WebhookEventType `json:"event"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Task       Task             `json:"task"`
+}
+
+// DeliveryStatus — состояние доставки события подписчику.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending — доставка ждёт первой или повторной попытки.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered — подписчик ответил кодом 2xx.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed — попытки исчерпаны, доставка больше не повторяется.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Valid сообщает, что s — одно из известных состояний доставки.
+func (s DeliveryStatus) Valid() bool {
+	switch s {
+	case DeliveryPending, DeliveryDelivered, DeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery — запись журнала доставки: одно событие для одной
+// подписки вместе с результатом последней попытки.
+// @Description Доставка события подписчику
+type WebhookDelivery struct {
+	ID            int64            `json:"id" example:"1"`                                                // Уникальный идентификатор доставки, передаётся в заголовке X-Todo-Delivery
+	WebhookID     int64            `json:"webhook_id" example:"1"`                                        // Подписка
+	Event         WebhookEventType `json:"event" example:"task.completed"`                                // Событие
+	Payload       json.RawMessage  `json:"payload" swaggertype:"object"`                                  // Тело запроса
+	Status        DeliveryStatus   `json:"status" example:"pending"`                                      // Состояние: pending, delivered или failed
+	Attempts      int              `json:"attempts" example:"2"`                                          // Число сделанных попыток
+	NextAttemptAt *time.Time       `json:"next_attempt_at" example:"2025-04-17T10:31:00Z"`                // Когда будет следующая попытка; null, если доставка завершена
+	ResponseCode  int              `json:"response_code,omitempty" example:"502"`                         // Код ответа подписчика на последнюю попытку
+	LastError     string           `json:"last_error,omitempty" example:"webhook: unexpected status 502"` // Ошибка последней неудачной попытки
+	DeliveredAt   *time.Time       `json:"delivered_at" example:"2025-04-17T10:31:00Z"`                   // Когда подписчик принял событие
+	CreatedAt     time.Time        `json:"created_at" example:"2025-04-17T10:30:00Z"`                     // Когда событие поставлено в очередь
+}
+
+// DeliveryFilter описывает страницу журнала доставок подписки. Nil Status
+// не ограничивает выборку.
+type DeliveryFilter struct {
+	Page   int
+	Limit  int
+	Status *DeliveryStatus
+}
+
+// DeliveryList представляет журнал доставок с пагинацией
+// @Description Журнал доставок с пагинацией
+type DeliveryList struct {
+	Data  []WebhookDelivery `json:"data"`               // Доставки, новые первыми
+	Total int64             `json:"total" example:"42"` // Общее количество доставок
+	Page  int               `json:"page" example:"1"`   // Текущая страница
+	Limit int               `json:"limit" example:"10"` // Количество элементов на странице
+}
+
+// DeliveryClaim описывает выборку доставок, которым пора выполнить попытку.
+type DeliveryClaim struct {
+	// Now — текущее время: выбираются доставки с next_attempt_at не позже
+	// него.
+	Now time.Time
+	// Lease — на сколько доставка закрепляется за выбравшим её экземпляром.
+	// Если за это время попытка не записана, доставку выберет следующий
+	// опрос.
+	Lease time.Duration
+	// Limit ограничивает число выбранных доставок.
+	Limit int
+}
+
+// OutgoingDelivery — выбранная доставка вместе с адресом и ключом подписки.
+type OutgoingDelivery struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// DeliveryAttempt — результат попытки доставки. Пустой Error означает
+// успех. Неудачная попытка с RetryAt повторяется в этот момент, без
+// RetryAt доставка становится failed.
+type DeliveryAttempt struct {
+	At           time.Time
+	ResponseCode int
+	Error        string
+	RetryAt      *time.Time
+}
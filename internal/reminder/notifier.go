@@ -0,0 +1,84 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/models"
+)
+
+// Notifier доставляет сработавшее напоминание.
+type Notifier interface {
+	Notify(ctx context.Context, notification models.Notification) error
+}
+
+// NewNotifier собирает уведомитель из способов доставки, перечисленных в
+// cfg.Notifiers.
+func NewNotifier(log *slog.Logger, cfg config.Reminders) (Notifier, error) {
+	const op = "reminder.NewNotifier"
+
+	var notifiers Notifiers
+	for _, name := range cfg.Notifiers {
+		switch name {
+		case config.NotifierLog:
+			notifiers = append(notifiers, NewLogNotifier(log))
+		case config.NotifierWebhook:
+			notifiers = append(notifiers, NewWebhookNotifier(cfg.Webhook.URL, cfg.Webhook.Timeout))
+		case config.NotifierSMTP:
+			notifiers = append(notifiers, NewSMTPNotifier(cfg.SMTP))
+		default:
+			return nil, fmt.Errorf("%s: unknown notifier %q", op, name)
+		}
+	}
+
+	return notifiers, nil
+}
+
+// Notifiers доставляет напоминание всеми уведомителями по очереди.
+// Доставка считается неудачной, если не удалась хотя бы одна; при повторе
+// напоминание снова получат все уведомители.
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, notification models.Notification) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LogNotifier записывает напоминания в журнал приложения.
+type LogNotifier struct {
+	log *slog.Logger
+}
+
+func NewLogNotifier(log *slog.Logger) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification models.Notification) error {
+	n.log.Info("reminder",
+		slog.Int64("reminder_id", notification.Reminder.ID),
+		slog.Int64("task_id", notification.Task.ID),
+		slog.String("title", notification.Task.Title),
+		slog.Time("due_date", notification.Task.DueDate),
+	)
+
+	return nil
+}
+
+// dueDate возвращает срок задачи в её часовом поясе, а если пояс не задан
+// или неизвестен — в UTC.
+func dueDate(task models.Task) time.Time {
+	if location, err := time.LoadLocation(task.Timezone); err == nil {
+		return task.DueDate.In(location)
+	}
+	return task.DueDate.UTC()
+}
@@ -0,0 +1,163 @@
+package reminder_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/config"
+	"todo/internal/models"
+	"todo/internal/reminder"
+)
+
+func notification() models.Notification {
+	return models.Notification{
+		Reminder: models.Reminder{ID: 7, TaskID: 3},
+		Task: models.Task{
+			ID:          3,
+			Title:       "Планёрка",
+			Description: "Обсудить релиз",
+			DueDate:     time.Date(2025, 4, 21, 6, 0, 0, 0, time.UTC),
+			Timezone:    "Europe/Moscow",
+		},
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    int
+		expectErr string
+	}{
+		{name: "Success", status: http.StatusNoContent},
+		{name: "Server error", status: http.StatusBadGateway, expectErr: "webhook: unexpected status 502"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var received models.Notification
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			err := reminder.NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), notification())
+			if tc.expectErr != "" {
+				require.EqualError(t, err, tc.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.EqualValues(t, 7, received.Reminder.ID)
+			require.Equal(t, "Планёрка", received.Task.Title)
+		})
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	messages := make(chan smtpMessage, 1)
+	go serveSMTP(t, listener, messages)
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	notifier := reminder.NewSMTPNotifier(config.SMTP{
+		Host:    host,
+		Port:    port,
+		From:    "todo@example.com",
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Timeout: time.Second,
+	})
+	require.NoError(t, notifier.Notify(context.Background(), notification()))
+
+	msg := <-messages
+	require.Equal(t, "<todo@example.com>", msg.from)
+	require.Equal(t, []string{"<alice@example.com>", "<bob@example.com>"}, msg.to)
+	require.Contains(t, msg.data, "To: alice@example.com, bob@example.com\r\n")
+	require.Contains(t, msg.data, "Subject: =?utf-8?q?Reminder:_")
+	require.Contains(t, msg.data, "Content-Type: text/plain; charset=utf-8\r\n")
+	require.Contains(t, msg.data, "Task #3 \"Планёрка\" is due Mon, 21 Apr 2025 09:00:00 MSK.\r\n")
+	require.Contains(t, msg.data, "Обсудить релиз")
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// serveSMTP — минимальный SMTP-сервер для одного письма: принимает
+// команды клиента и отдаёт полученное письмо в messages.
+func serveSMTP(t *testing.T, listener net.Listener, messages chan<- smtpMessage) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, err := conn.Write([]byte(line + "\r\n"))
+		require.NoError(t, err)
+	}
+
+	var msg smtpMessage
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg.from = strings.TrimPrefix(command, "MAIL FROM:")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			msg.to = append(msg.to, strings.TrimPrefix(command, "RCPT TO:"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			msg.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			messages <- msg
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
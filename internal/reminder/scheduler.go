@@ -0,0 +1,113 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Store — хранилище, из которого планировщик выбирает сработавшие
+// напоминания и в котором отмечает результат доставки.
+type Store interface {
+	ClaimReminders(ctx context.Context, claim models.ReminderClaim) ([]models.Reminder, error)
+	MarkReminderSent(ctx context.Context, id int64, at time.Time) error
+	MarkReminderFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error
+	GetByID(ctx context.Context, id uint) (*models.Task, error)
+}
+
+// Scheduler периодически выбирает сработавшие напоминания и доставляет их.
+// Хранилище закрепляет выбранные напоминания за экземпляром, поэтому
+// несколько экземпляров приложения могут работать с одной базой, не
+// доставляя напоминание дважды.
+type Scheduler struct {
+	log      *slog.Logger
+	store    Store
+	notifier Notifier
+	cfg      config.Reminders
+}
+
+func NewScheduler(log *slog.Logger, store Store, notifier Notifier, cfg config.Reminders) *Scheduler {
+	return &Scheduler{
+		log:      log.With(slog.String("component", "reminder.Scheduler")),
+		store:    store,
+		notifier: notifier,
+		cfg:      cfg,
+	}
+}
+
+// Run опрашивает хранилище сразу и затем каждые PollInterval, пока ctx не
+// отменён.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Poll(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			s.log.Error("failed to poll reminders", sl.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll доставляет напоминания, сработавшие к моменту now, и возвращает
+// число доставленных. Неудачная доставка откладывается на RetryDelay и
+// не прерывает доставку остальных.
+func (s *Scheduler) Poll(ctx context.Context, now time.Time) (int, error) {
+	const op = "reminder.Scheduler.Poll"
+
+	reminders, err := s.store.ClaimReminders(ctx, models.ReminderClaim{
+		Now:         now,
+		Lease:       s.cfg.Lease,
+		Limit:       s.cfg.BatchSize,
+		MaxAttempts: s.cfg.MaxAttempts,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		log := s.log.With(slog.Int64("reminder_id", reminder.ID), slog.Int64("task_id", reminder.TaskID))
+
+		err := s.deliver(ctx, reminder)
+		if errors.Is(err, storage.ErrTaskNotFound) {
+			log.Info("task of reminder was deleted")
+			continue
+		}
+		if err != nil {
+			log.Error("failed to deliver reminder", slog.Int("attempt", reminder.Attempts+1), sl.Err(err))
+			err = s.store.MarkReminderFailed(ctx, reminder.ID, err.Error(), now.Add(s.cfg.RetryDelay))
+		} else {
+			log.Info("reminder delivered")
+			sent++
+			err = s.store.MarkReminderSent(ctx, reminder.ID, time.Now())
+		}
+		if err != nil && !errors.Is(err, storage.ErrReminderNotFound) {
+			log.Error("failed to save reminder delivery", sl.Err(err))
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver отправляет напоминание вместе с его задачей.
+func (s *Scheduler) deliver(ctx context.Context, reminder models.Reminder) error {
+	task, err := s.store.GetByID(ctx, uint(reminder.TaskID))
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, models.Notification{Reminder: reminder, Task: *task})
+}
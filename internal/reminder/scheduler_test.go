@@ -0,0 +1,145 @@
+package reminder_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/config"
+	"todo/internal/models"
+	"todo/internal/reminder"
+	"todo/internal/storage/memory"
+)
+
+// recorder запоминает доставленные напоминания и отказывает, пока fail
+// не пуст.
+type recorder struct {
+	mu    sync.Mutex
+	fail  []error
+	calls []models.Notification
+}
+
+func (r *recorder) Notify(_ context.Context, notification models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, notification)
+	if len(r.fail) > 0 {
+		err := r.fail[0]
+		r.fail = r.fail[1:]
+		return err
+	}
+	return nil
+}
+
+func TestSchedulerPoll(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	task, err := s.CreateTask(ctx, models.Task{Title: "standup", DueDate: day})
+	require.NoError(t, err)
+
+	before := models.Duration(10 * time.Minute)
+	created, err := s.CreateReminder(ctx, models.Reminder{TaskID: task.ID, Before: &before})
+	require.NoError(t, err)
+
+	notifier := &recorder{fail: []error{errors.New("webhook: unexpected status 502")}}
+	cfg := config.Reminders{Lease: time.Minute, BatchSize: 10, MaxAttempts: 3, RetryDelay: time.Minute}
+	scheduler := reminder.NewScheduler(slog.New(slog.NewTextHandler(io.Discard, nil)), s, notifier, cfg)
+
+	sent, err := scheduler.Poll(ctx, day.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, sent)
+	require.Empty(t, notifier.calls)
+
+	// Первая доставка не удалась и откладывается на retry_delay.
+	sent, err = scheduler.Poll(ctx, day)
+	require.NoError(t, err)
+	require.Zero(t, sent)
+	require.Len(t, notifier.calls, 1)
+	require.Equal(t, "standup", notifier.calls[0].Task.Title)
+
+	sent, err = scheduler.Poll(ctx, day.Add(30*time.Second))
+	require.NoError(t, err)
+	require.Zero(t, sent)
+
+	sent, err = scheduler.Poll(ctx, day.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.Len(t, notifier.calls, 2)
+	require.Equal(t, 1, notifier.calls[1].Reminder.Attempts)
+
+	reminders, err := s.ListReminders(ctx, uint(task.ID))
+	require.NoError(t, err)
+	require.Equal(t, created.ID, reminders[0].ID)
+	require.NotNil(t, reminders[0].SentAt)
+	require.Equal(t, "webhook: unexpected status 502", reminders[0].LastError)
+
+	// Доставленное напоминание больше не выбирается.
+	sent, err = scheduler.Poll(ctx, day.Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, sent)
+	require.Len(t, notifier.calls, 2)
+}
+
+func TestSchedulerConcurrentPolls(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		task, err := s.CreateTask(ctx, models.Task{Title: "task", DueDate: day})
+		require.NoError(t, err)
+		_, err = s.CreateReminder(ctx, models.Reminder{TaskID: task.ID, RemindAt: &day})
+		require.NoError(t, err)
+	}
+
+	notifier := &recorder{}
+	cfg := config.Reminders{Lease: time.Minute, BatchSize: 5, MaxAttempts: 3, RetryDelay: time.Minute}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Несколько планировщиков над одним хранилищем, как несколько
+	// экземпляров приложения над одной базой.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		scheduler := reminder.NewScheduler(logger, s, notifier, cfg)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				_, err := scheduler.Poll(ctx, day)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	delivered := make(map[int64]int)
+	for _, call := range notifier.calls {
+		delivered[call.Reminder.ID]++
+	}
+	require.Len(t, delivered, 20)
+	for id, count := range delivered {
+		require.Equal(t, 1, count, "reminder %d", id)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	first := &recorder{}
+	second := &recorder{fail: []error{errors.New("smtp: connection refused")}}
+
+	notifier := reminder.Notifiers{first, second}
+	err := notifier.Notify(context.Background(), models.Notification{})
+	require.EqualError(t, err, "smtp: connection refused")
+	require.Len(t, first.calls, 1)
+	require.Len(t, second.calls, 1)
+
+	require.NoError(t, notifier.Notify(context.Background(), models.Notification{}))
+}
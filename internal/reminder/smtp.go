@@ -0,0 +1,103 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/models"
+)
+
+// SMTPNotifier отправляет напоминание письмом. Если сервер поддерживает
+// STARTTLS, соединение шифруется до аутентификации.
+type SMTPNotifier struct {
+	cfg config.SMTP
+}
+
+func NewSMTPNotifier(cfg config.SMTP) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	dialer := net.Dialer{Timeout: n.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.cfg.Host, n.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer conn.Close()
+
+	if n.cfg.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(n.cfg.Timeout)); err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+	}
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("smtp: starttls: %w", err)
+		}
+	}
+
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp: auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("smtp: mail from: %w", err)
+	}
+	for _, to := range n.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp: rcpt to %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: data: %w", err)
+	}
+	if _, err := w.Write(n.message(notification)); err != nil {
+		return fmt.Errorf("smtp: write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// message составляет письмо с напоминанием. Переводы строк и точки в
+// начале строк приводит к виду SMTP writer из client.Data.
+func (n *SMTPNotifier) message(notification models.Notification) []byte {
+	task := notification.Task
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\n", mime.QEncoding.Encode("utf-8", "Reminder: "+task.Title))
+	fmt.Fprintf(&msg, "Date: %s\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\n\n")
+
+	fmt.Fprintf(&msg, "Task #%d \"%s\" is due %s.\n", task.ID, task.Title, dueDate(task).Format(time.RFC1123))
+	if task.Description != "" {
+		fmt.Fprintf(&msg, "\n%s\n", task.Description)
+	}
+
+	return msg.Bytes()
+}
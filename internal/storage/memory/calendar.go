@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// feedToken — токен календаря и хеш, по которому его находит FindFeedToken.
+type feedToken struct {
+	models.FeedToken
+	hash string
+}
+
+// CreateFeedToken сохраняет токен календаря по его хешу.
+func (s *Storage) CreateFeedToken(ctx context.Context, name, hash string) (*models.FeedToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := models.FeedToken{ID: s.nextFeedTokenID, Name: name, CreatedAt: time.Now()}
+	s.feedTokens[token.ID] = feedToken{FeedToken: token, hash: hash}
+	s.nextFeedTokenID++
+
+	return &token, nil
+}
+
+// ListFeedTokens возвращает токены календаря в порядке создания.
+func (s *Storage) ListFeedTokens(ctx context.Context) ([]models.FeedToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]models.FeedToken, 0, len(s.feedTokens))
+	for _, token := range s.feedTokens {
+		tokens = append(tokens, token.FeedToken)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].ID < tokens[j].ID
+	})
+
+	return tokens, nil
+}
+
+// DeleteFeedToken отзывает токен календаря.
+func (s *Storage) DeleteFeedToken(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.feedTokens[int64(id)]; !ok {
+		return storage.ErrFeedTokenNotFound
+	}
+	delete(s.feedTokens, int64(id))
+
+	return nil
+}
+
+// FindFeedToken возвращает токен календаря по хешу.
+func (s *Storage) FindFeedToken(ctx context.Context, hash string) (*models.FeedToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.feedTokens {
+		if token.hash == hash {
+			found := token.FeedToken
+			return &found, nil
+		}
+	}
+
+	return nil, storage.ErrFeedTokenNotFound
+}
+
+// CalendarObjects возвращает ресурсы CalDAV, созданные клиентами.
+func (s *Storage) CalendarObjects(ctx context.Context) ([]models.CalendarObject, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := make([]models.CalendarObject, 0, len(s.calendarObjects))
+	for _, object := range s.calendarObjects {
+		objects = append(objects, object)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].TaskID < objects[j].TaskID
+	})
+
+	return objects, nil
+}
+
+// SaveCalendarObject сохраняет имя ресурса и UID задачи, заменяя прежние.
+func (s *Storage) SaveCalendarObject(ctx context.Context, object models.CalendarObject) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[object.TaskID]; !ok {
+		return storage.ErrTaskNotFound
+	}
+	for taskID, other := range s.calendarObjects {
+		if other.Name == object.Name && taskID != object.TaskID {
+			return storage.ErrCalendarObjectExists
+		}
+	}
+	s.calendarObjects[object.TaskID] = object
+
+	return nil
+}
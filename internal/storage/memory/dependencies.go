@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Blockers возвращает задачи, которые блокируют задачу id, по сроку
+// выполнения.
+func (s *Storage) Blockers(ctx context.Context, id uint) ([]models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tasks[int64(id)]; !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	tasks := []models.Task{}
+	for _, blockerID := range s.blockers[int64(id)] {
+		tasks = append(tasks, *s.view(s.tasks[blockerID]))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].DueDate.Equal(tasks[j].DueDate) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].DueDate.Before(tasks[j].DueDate)
+	})
+
+	return tasks, nil
+}
+
+// AddBlocker делает задачу blockerID блокирующей для задачи id и
+// увеличивает версию задачи. Повторное добавление ничего не меняет.
+// Ненулевой version включает проверку версии.
+func (s *Storage) AddBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkBlocker(int64(id), int64(blockerID)); err != nil {
+		return nil, err
+	}
+
+	task, ok := s.tasks[int64(id)]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+	if version != 0 && version != task.Version {
+		return nil, storage.ErrVersionMismatch
+	}
+
+	if slices.Contains(s.blockers[task.ID], int64(blockerID)) {
+		return s.view(task), nil
+	}
+
+	s.blockers[task.ID] = append(s.blockers[task.ID], int64(blockerID))
+	task.UpdatedAt = time.Now()
+	task.Version++
+	s.tasks[task.ID] = task
+
+	return s.view(task), nil
+}
+
+// RemoveBlocker убирает зависимость задачи id от blockerID и увеличивает
+// версию задачи. Ненулевой version включает проверку версии.
+func (s *Storage) RemoveBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[int64(id)]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	i := slices.Index(s.blockers[task.ID], int64(blockerID))
+	if i < 0 {
+		return nil, storage.ErrDependencyNotFound
+	}
+	if version != 0 && version != task.Version {
+		return nil, storage.ErrVersionMismatch
+	}
+
+	s.blockers[task.ID] = slices.Delete(s.blockers[task.ID], i, i+1)
+	task.UpdatedAt = time.Now()
+	task.Version++
+	s.tasks[task.ID] = task
+
+	return s.view(task), nil
+}
+
+// NextTasks возвращает до limit невыполненных задач в порядке, в котором
+// их можно делать: каждая задача идёт после своих блокирующих, а среди
+// доступных первой идёт задача с ближайшим сроком.
+func (s *Storage) NextTasks(ctx context.Context, limit int) ([]models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []models.Task
+	var deps []models.TaskDependency
+	for _, task := range s.tasks {
+		if task.Status.Closed() {
+			continue
+		}
+		tasks = append(tasks, *s.view(task))
+		for _, blockerID := range s.blockers[task.ID] {
+			deps = append(deps, models.TaskDependency{TaskID: task.ID, BlockerID: blockerID})
+		}
+	}
+
+	tasks = models.OrderByDependencies(tasks, deps)
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	return tasks, nil
+}
+
+// checkBlocker повторяет проверку postgres: блокирующая задача существует
+// и сама не ждёт taskID, прямо или через другие задачи.
+func (s *Storage) checkBlocker(taskID, blockerID int64) error {
+	if _, ok := s.tasks[blockerID]; !ok {
+		return storage.ErrBlockerNotFound
+	}
+
+	seen := map[int64]bool{blockerID: true}
+	queue := []int64{blockerID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == taskID {
+			return storage.ErrDependencyCycle
+		}
+		for _, next := range s.blockers[id] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isBlocked сообщает, что задачу id блокирует хотя бы одна невыполненная
+// задача.
+func (s *Storage) isBlocked(id int64) bool {
+	for _, blockerID := range s.blockers[id] {
+		if !s.tasks[blockerID].Status.Closed() {
+			return true
+		}
+	}
+	return false
+}
+
+// removeDependencies удаляет зависимости удалённой задачи id, как каскадный
+// внешний ключ task_dependencies в базе.
+func (s *Storage) removeDependencies(id int64) {
+	delete(s.blockers, id)
+	for taskID, blockerIDs := range s.blockers {
+		s.blockers[taskID] = slices.DeleteFunc(blockerIDs, func(blockerID int64) bool {
+			return blockerID == id
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// AppendTaskEvent записывает событие в журнал изменений задач и оставляет
+// в журнале не больше keep последних событий.
+func (s *Storage) AppendTaskEvent(ctx context.Context, event models.WebhookEvent, keep int) (*models.TaskEvent, error) {
+	const op = "storage.memory.AppendTaskEvent"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Событие проходит через JSON, как в остальных хранилищах, чтобы
+	// подписчики не делили срезы задачи с вызывающим.
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stored := models.TaskEvent{}
+	if err := json.Unmarshal(payload, &stored.WebhookEvent); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextEventID
+	s.nextEventID++
+
+	s.events = append(s.events, stored)
+	if len(s.events) > keep {
+		s.events = append([]models.TaskEvent(nil), s.events[len(s.events)-keep:]...)
+	}
+
+	return &stored, nil
+}
+
+// ListTaskEvents возвращает до limit событий журнала с ID больше afterID.
+// Если события сразу после afterID уже вытеснены из журнала, возвращает
+// ErrEventsExpired.
+func (s *Storage) ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.events) > 0 && afterID < s.events[0].ID-1 {
+		return nil, storage.ErrEventsExpired
+	}
+
+	events := []models.TaskEvent{}
+	for _, event := range s.events {
+		if event.ID <= afterID {
+			continue
+		}
+		if len(events) == limit {
+			break
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,399 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Storage хранит задачи в памяти процесса. Данные теряются при перезапуске,
+// поэтому хранилище предназначено для локальной разработки и тестов.
+type Storage struct {
+	mu     sync.RWMutex
+	tasks  map[int64]models.Task
+	nextID int64
+
+	tags      map[int64]models.Tag
+	nextTagID int64
+	// taskTags хранит идентификаторы меток задачи, сами задачи в tasks
+	// хранятся без меток.
+	taskTags map[int64][]int64
+
+	projects      map[int64]models.Project
+	nextProjectID int64
+
+	// blockers хранит идентификаторы задач, которые блокируют задачу.
+	blockers map[int64][]int64
+
+	reminders      map[int64]reminder
+	nextReminderID int64
+
+	webhooks       map[int64]models.Webhook
+	nextWebhookID  int64
+	deliveries     map[int64]delivery
+	nextDeliveryID int64
+
+	// events — журнал изменений задач в порядке ID.
+	events      []models.TaskEvent
+	nextEventID int64
+
+	feedTokens      map[int64]feedToken
+	nextFeedTokenID int64
+
+	// calendarObjects — ресурсы CalDAV по id задачи.
+	calendarObjects map[int64]models.CalendarObject
+}
+
+func New() *Storage {
+	return &Storage{
+		tasks:     make(map[int64]models.Task),
+		nextID:    1,
+		tags:      make(map[int64]models.Tag),
+		nextTagID: 1,
+		taskTags:  make(map[int64][]int64),
+
+		projects:      make(map[int64]models.Project),
+		nextProjectID: 1,
+
+		blockers: make(map[int64][]int64),
+
+		reminders:      make(map[int64]reminder),
+		nextReminderID: 1,
+
+		webhooks:       make(map[int64]models.Webhook),
+		nextWebhookID:  1,
+		deliveries:     make(map[int64]delivery),
+		nextDeliveryID: 1,
+
+		nextEventID: 1,
+
+		feedTokens:      make(map[int64]feedToken),
+		nextFeedTokenID: 1,
+
+		calendarObjects: make(map[int64]models.CalendarObject),
+	}
+}
+
+func (s *Storage) CreateTask(ctx context.Context, task models.Task) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasProject(task.ProjectID) {
+		return nil, storage.ErrProjectNotFound
+	}
+	if task.ParentID != nil {
+		if err := s.checkParent(0, *task.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	task = s.insert(task)
+	if err := s.spawnNext(&task); err != nil {
+		return nil, err
+	}
+	s.tasks[task.ID] = task
+
+	return s.view(task), nil
+}
+
+func (s *Storage) GetByID(ctx context.Context, id uint) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[int64(id)]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	return s.view(task), nil
+}
+
+// UpdateTask перезаписывает задачу. Если task.Version не равен нулю,
+// обновление выполняется только при совпадении версии. Задачу с
+// открытыми подзадачами можно выполнить только с children ==
+// SubtasksComplete: тогда подзадачи выполняются вместе с ней.
+func (s *Storage) UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.tasks[task.ID]
+	if !ok {
+		return storage.ErrTaskNotFound
+	}
+	if task.Version != 0 && task.Version != existing.Version {
+		return storage.ErrVersionMismatch
+	}
+	if !s.hasProject(task.ProjectID) {
+		return storage.ErrProjectNotFound
+	}
+	if task.ParentID != nil {
+		if err := s.checkParent(task.ID, *task.ParentID); err != nil {
+			return err
+		}
+	}
+	completeChildren := task.Status == models.StatusDone && children == models.SubtasksComplete
+	if task.Status == models.StatusDone && !completeChildren && !existing.Status.Closed() && s.hasOpenSubtasks(task.ID) {
+		return storage.ErrOpenSubtasks
+	}
+
+	task.CreatedAt = existing.CreatedAt
+	task.NextID = existing.NextID
+	task.UpdatedAt = time.Now()
+	task.CompletedAt, task.CancelledAt = existing.CompletedAt, existing.CancelledAt
+	setStatus(task, task.Status, task.UpdatedAt)
+	task.Version = existing.Version + 1
+	task.Priority = task.Priority.OrNone()
+
+	s.taskTags[task.ID] = s.resolveTags(task.Tags)
+	stored := *task
+	stored.Tags = nil
+	stored.ProjectID = copyID(task.ProjectID)
+	stored.ParentID = copyID(task.ParentID)
+	s.rescheduleReminders(stored)
+	if err := s.spawnNext(&stored); err != nil {
+		return err
+	}
+	if completeChildren {
+		if _, err := s.completeSubtasks(task.ID, task.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	s.tasks[task.ID] = stored
+	viewed := s.view(stored)
+	task.Tags = viewed.Tags
+	task.Blocked = viewed.Blocked
+	task.RRule = viewed.RRule
+	task.NextID = viewed.NextID
+
+	return nil
+}
+
+func (s *Storage) PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[int64(id)]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+	if patch.Version != 0 && patch.Version != task.Version {
+		return nil, storage.ErrVersionMismatch
+	}
+
+	if patch.IsEmpty() {
+		return s.view(task), nil
+	}
+
+	if patch.ProjectID != nil && *patch.ProjectID != 0 && !s.hasProject(patch.ProjectID) {
+		return nil, storage.ErrProjectNotFound
+	}
+	if patch.ParentID != nil && *patch.ParentID != 0 {
+		if err := s.checkParent(task.ID, *patch.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	completeChildren := patch.Status != nil && *patch.Status == models.StatusDone && patch.Children == models.SubtasksComplete
+	if patch.Status != nil && *patch.Status == models.StatusDone && !completeChildren && !task.Status.Closed() && s.hasOpenSubtasks(task.ID) {
+		return nil, storage.ErrOpenSubtasks
+	}
+
+	if patch.Title != nil {
+		task.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		task.Description = *patch.Description
+	}
+	if patch.DueDate != nil {
+		task.DueDate = *patch.DueDate
+	}
+	now := time.Now()
+	if patch.Status != nil {
+		setStatus(&task, *patch.Status, now)
+	}
+	if patch.Priority != nil {
+		task.Priority = patch.Priority.OrNone()
+	}
+	if patch.Tags != nil {
+		s.taskTags[task.ID] = s.resolveTags(*patch.Tags)
+	}
+	if patch.ProjectID != nil {
+		task.ProjectID = nil
+		if *patch.ProjectID != 0 {
+			task.ProjectID = copyID(patch.ProjectID)
+		}
+	}
+	if patch.ParentID != nil {
+		task.ParentID = nil
+		if *patch.ParentID != 0 {
+			task.ParentID = copyID(patch.ParentID)
+		}
+	}
+	if patch.RRule != nil {
+		task.RRule = *patch.RRule
+	}
+	if patch.Timezone != nil {
+		task.Timezone = *patch.Timezone
+	}
+	task.UpdatedAt = now
+	task.Version++
+
+	if patch.DueDate != nil {
+		s.rescheduleReminders(task)
+	}
+	if err := s.spawnNext(&task); err != nil {
+		return nil, err
+	}
+	if completeChildren {
+		if _, err := s.completeSubtasks(task.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	s.tasks[task.ID] = task
+
+	return s.view(task), nil
+}
+
+// DeleteTask удаляет задачу. Ненулевой version включает проверку версии.
+func (s *Storage) DeleteTask(ctx context.Context, id uint, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[int64(id)]
+	if !ok {
+		return storage.ErrTaskNotFound
+	}
+	if version != 0 && version != task.Version {
+		return storage.ErrVersionMismatch
+	}
+
+	s.deleteSubtree(int64(id))
+
+	return nil
+}
+
+func (s *Storage) List(ctx context.Context, filter models.TaskFilter) (*models.TasksList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []models.Task
+	for _, task := range s.tasks {
+		if filter.Completed != nil && task.Status.Closed() != *filter.Completed {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, task.Status) {
+			continue
+		}
+		if filter.Date != nil && !sameDate(task.DueDate, *filter.Date) {
+			continue
+		}
+		if filter.Priority != nil && task.Priority != *filter.Priority {
+			continue
+		}
+		if len(filter.Tags) > 0 && !s.hasTags(task.ID, filter.Tags, filter.TagMatch) {
+			continue
+		}
+		if filter.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *filter.ProjectID) {
+			continue
+		}
+		if filter.ParentID != nil && (task.ParentID == nil || *task.ParentID != *filter.ParentID) {
+			continue
+		}
+		viewed := s.view(task)
+		if filter.Blocked != nil && viewed.Blocked != *filter.Blocked {
+			continue
+		}
+		matched = append(matched, *viewed)
+	}
+
+	// Порядок совпадает с postgres и sqlite: ORDER BY due_date ASC, id ASC,
+	// так что страницы задач с одинаковым сроком не пересекаются. При
+	// сортировке по приоритету более важные задачи идут первыми.
+	sort.Slice(matched, func(i, j int) bool {
+		if filter.Sort == models.SortByPriority && matched[i].Priority != matched[j].Priority {
+			return matched[i].Priority.Rank() > matched[j].Priority.Rank()
+		}
+		if matched[i].DueDate.Equal(matched[j].DueDate) {
+			return matched[i].ID < matched[j].ID
+		}
+		return matched[i].DueDate.Before(matched[j].DueDate)
+	})
+
+	var tasks []models.Task
+	offset := (filter.Page - 1) * filter.Limit
+	if offset < len(matched) {
+		end := offset + filter.Limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		tasks = matched[offset:end]
+	}
+
+	return &models.TasksList{
+		Data:  tasks,
+		Total: int64(len(matched)),
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// Ping всегда успешен: хранилище в памяти доступно, пока жив процесс.
+func (s *Storage) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Close ничего не делает: хранилищу в памяти нечего освобождать.
+func (s *Storage) Close() error {
+	return nil
+}
+
+// view возвращает копию задачи в том виде, в каком её отдают хранилища
+// с базой данных: с метками, упорядоченными по названию, и вычисленным
+// флагом Blocked.
+func (s *Storage) view(task models.Task) *models.Task {
+	task.Tags = make([]models.Tag, 0, len(s.taskTags[task.ID]))
+	for _, id := range s.taskTags[task.ID] {
+		task.Tags = append(task.Tags, s.tags[id])
+	}
+	sortTags(task.Tags)
+
+	task.Blocked = s.isBlocked(task.ID)
+
+	return &task
+}
+
+// sameDate сравнивает только календарные дни в локальной временной зоне,
+// как фильтр по дате в хранилищах с базой данных.
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.In(time.Local).Date()
+	by, bm, bd := b.In(time.Local).Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,1001 @@
+package memory_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/storage/memory"
+)
+
+func TestStorageCRUD(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	due := time.Now().Add(time.Hour)
+
+	created, err := s.CreateTask(ctx, models.Task{Title: "first", DueDate: due})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, created.ID)
+
+	task, err := s.GetByID(ctx, uint(created.ID))
+	require.NoError(t, err)
+	require.Equal(t, created, task)
+	require.Equal(t, "first", task.Title)
+	require.False(t, task.CreatedAt.IsZero())
+
+	task.Status = models.StatusDone
+	require.NoError(t, s.UpdateTask(ctx, task, models.SubtasksBlock))
+
+	task, err = s.GetByID(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, models.StatusDone, task.Status)
+
+	title := "patched"
+	patched, err := s.PatchTask(ctx, 1, models.TaskPatch{Title: &title})
+	require.NoError(t, err)
+	require.Equal(t, "patched", patched.Title)
+	require.Equal(t, models.StatusDone, patched.Status)
+	require.True(t, task.DueDate.Equal(patched.DueDate))
+
+	_, err = s.PatchTask(ctx, 2, models.TaskPatch{Title: &title})
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	require.NoError(t, s.DeleteTask(ctx, 1, 0))
+
+	_, err = s.GetByID(ctx, 1)
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+	require.ErrorIs(t, s.DeleteTask(ctx, 1, 0), storage.ErrTaskNotFound)
+	require.ErrorIs(t, s.UpdateTask(ctx, &models.Task{ID: 1}, models.SubtasksBlock), storage.ErrTaskNotFound)
+}
+
+func TestStorageVersion(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	created, err := s.CreateTask(ctx, models.Task{Title: "first", DueDate: time.Now()})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, created.Version)
+
+	task := *created
+	task.Title = "second"
+	require.NoError(t, s.UpdateTask(ctx, &task, models.SubtasksBlock))
+	require.EqualValues(t, 2, task.Version)
+
+	stale := *created
+	require.ErrorIs(t, s.UpdateTask(ctx, &stale, models.SubtasksBlock), storage.ErrVersionMismatch)
+
+	status := models.StatusDone
+	_, err = s.PatchTask(ctx, 1, models.TaskPatch{Status: &status, Version: 1})
+	require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+	patched, err := s.PatchTask(ctx, 1, models.TaskPatch{Status: &status, Version: 2})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, patched.Version)
+
+	require.ErrorIs(t, s.DeleteTask(ctx, 1, 2), storage.ErrVersionMismatch)
+	require.NoError(t, s.DeleteTask(ctx, 1, 3))
+	require.ErrorIs(t, s.DeleteTask(ctx, 1, 3), storage.ErrTaskNotFound)
+}
+
+func TestStorageList(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 12, 0, 0, 0, time.Local)
+
+	create(t, s, models.Task{Title: "c", DueDate: day.Add(48 * time.Hour)})
+	create(t, s, models.Task{Title: "a", DueDate: day, Status: models.StatusDone})
+	create(t, s, models.Task{Title: "b", DueDate: day.Add(time.Hour)})
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, list.Total)
+	require.Equal(t, []string{"a", "b", "c"}, titles(list.Data))
+
+	list, err = s.List(ctx, models.TaskFilter{Page: 2, Limit: 2})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, list.Total)
+	require.Equal(t, []string{"c"}, titles(list.Data))
+
+	completed := false
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Completed: &completed})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, titles(list.Data))
+
+	date := time.Date(2025, 4, 17, 0, 0, 0, 0, time.Local)
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Date: &date})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, titles(list.Data))
+
+	list, err = s.List(ctx, models.TaskFilter{Page: 5, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, list.Data)
+}
+
+func TestStorageListDateLocalZone(t *testing.T) {
+	// Фильтр по дате сравнивает дни в локальной зоне, а не в UTC.
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	ctx := context.Background()
+	s := memory.New()
+
+	create(t, s, models.Task{Title: "evening", DueDate: time.Date(2025, 4, 17, 20, 0, 0, 0, time.UTC)})
+	create(t, s, models.Task{Title: "night", DueDate: time.Date(2025, 4, 17, 22, 30, 0, 0, time.UTC)})
+	create(t, s, models.Task{Title: "next night", DueDate: time.Date(2025, 4, 18, 21, 30, 0, 0, time.UTC)})
+
+	date := time.Date(2025, 4, 18, 0, 0, 0, 0, time.Local)
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Date: &date})
+	require.NoError(t, err)
+	require.Equal(t, []string{"night"}, titles(list.Data))
+
+	date = time.Date(2025, 4, 17, 0, 0, 0, 0, time.Local)
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Date: &date})
+	require.NoError(t, err)
+	require.Equal(t, []string{"evening"}, titles(list.Data))
+}
+
+func TestStorageStatus(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 12, 0, 0, 0, time.UTC)
+
+	create(t, s, models.Task{Title: "todo", DueDate: day})
+	create(t, s, models.Task{Title: "started", DueDate: day.Add(time.Hour), Status: models.StatusInProgress})
+	create(t, s, models.Task{Title: "cancelled", DueDate: day.Add(2 * time.Hour), Status: models.StatusCancelled})
+
+	cancelled, err := s.GetByID(ctx, 3)
+	require.NoError(t, err)
+	require.NotNil(t, cancelled.CancelledAt)
+	require.Nil(t, cancelled.CompletedAt)
+
+	done := models.StatusDone
+	task, err := s.PatchTask(ctx, 1, models.TaskPatch{Status: &done})
+	require.NoError(t, err)
+	require.Equal(t, models.StatusDone, task.Status)
+	require.NotNil(t, task.CompletedAt)
+	completedAt := *task.CompletedAt
+
+	task.Title = "done"
+	require.NoError(t, s.UpdateTask(ctx, task, models.SubtasksBlock))
+	require.True(t, completedAt.Equal(*task.CompletedAt))
+
+	closed := true
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Completed: &closed})
+	require.NoError(t, err)
+	require.Equal(t, []string{"done", "cancelled"}, titles(list.Data))
+
+	list, err = s.List(ctx, models.TaskFilter{
+		Page:     1,
+		Limit:    10,
+		Statuses: []models.TaskStatus{models.StatusInProgress, models.StatusCancelled},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"started", "cancelled"}, titles(list.Data))
+
+	todo := models.StatusTodo
+	task, err = s.PatchTask(ctx, 1, models.TaskPatch{Status: &todo})
+	require.NoError(t, err)
+	require.Nil(t, task.CompletedAt)
+}
+
+func TestStorageConcurrentCreate(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 100})
+	require.NoError(t, err)
+	require.EqualValues(t, 50, list.Total)
+}
+
+func TestStorageListByPriority(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.Local)
+
+	created, err := s.CreateTask(ctx, models.Task{Title: "none", DueDate: day})
+	require.NoError(t, err)
+	require.Equal(t, models.PriorityNone, created.Priority)
+
+	create(t, s, models.Task{Title: "high-late", DueDate: day.Add(2 * time.Hour), Priority: models.PriorityHigh})
+	create(t, s, models.Task{Title: "urgent", DueDate: day.Add(3 * time.Hour), Priority: models.PriorityUrgent})
+	create(t, s, models.Task{Title: "high-early", DueDate: day.Add(time.Hour), Priority: models.PriorityHigh})
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"none", "high-early", "high-late", "urgent"}, titles(list.Data))
+	require.Equal(t, models.PriorityNone, list.Data[0].Priority)
+
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Sort: models.SortByPriority})
+	require.NoError(t, err)
+	require.Equal(t, []string{"urgent", "high-early", "high-late", "none"}, titles(list.Data))
+
+	high := models.PriorityHigh
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Priority: &high})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, list.Total)
+	require.Equal(t, []string{"high-early", "high-late"}, titles(list.Data))
+
+	low := models.PriorityLow
+	task, err := s.PatchTask(ctx, 1, models.TaskPatch{Priority: &low})
+	require.NoError(t, err)
+	require.Equal(t, models.PriorityLow, task.Priority)
+}
+
+func TestStorageTags(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.Local)
+
+	backend, err := s.CreateTag(ctx, models.Tag{Name: "backend", Color: "#1e90ff"})
+	require.NoError(t, err)
+	_, err = s.CreateTag(ctx, models.Tag{Name: "backend"})
+	require.ErrorIs(t, err, storage.ErrTagExists)
+
+	// Отсутствующие метки создаются по названию, повторы игнорируются.
+	both, err := s.CreateTask(ctx, models.Task{
+		Title:   "both",
+		DueDate: day,
+		Tags:    []models.Tag{{Name: "errand"}, {Name: "backend"}, {Name: "errand"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"backend", "errand"}, tagNames(both.Tags))
+	require.Equal(t, "#1e90ff", both.Tags[0].Color)
+
+	create(t, s, models.Task{Title: "backend", DueDate: day.Add(time.Hour), Tags: []models.Tag{{Name: "backend"}}})
+	create(t, s, models.Task{Title: "untagged", DueDate: day.Add(2 * time.Hour)})
+
+	tags, err := s.ListTags(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"backend", "errand"}, tagNames(tags))
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Tags: []string{"backend", "errand"}})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, list.Total)
+	require.Equal(t, []string{"both", "backend"}, titles(list.Data))
+
+	list, err = s.List(ctx, models.TaskFilter{
+		Page:     1,
+		Limit:    10,
+		Tags:     []string{"backend", "errand"},
+		TagMatch: models.TagMatchAll,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, list.Total)
+	require.Equal(t, []string{"both"}, titles(list.Data))
+
+	task, err := s.GetByID(ctx, 3)
+	require.NoError(t, err)
+	require.NotNil(t, task.Tags)
+	require.Empty(t, task.Tags)
+
+	renamed := "server"
+	_, err = s.UpdateTag(ctx, uint(backend.ID), models.TagPatch{Name: &renamed})
+	require.NoError(t, err)
+	errand := "errand"
+	_, err = s.UpdateTag(ctx, uint(backend.ID), models.TagPatch{Name: &errand})
+	require.ErrorIs(t, err, storage.ErrTagExists)
+
+	task, err = s.GetByID(ctx, uint(both.ID))
+	require.NoError(t, err)
+	require.Equal(t, []string{"errand", "server"}, tagNames(task.Tags))
+
+	task, err = s.PatchTask(ctx, uint(both.ID), models.TaskPatch{Tags: &[]models.Tag{{Name: "server"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"server"}, tagNames(task.Tags))
+	require.EqualValues(t, 2, task.Version)
+
+	require.NoError(t, s.DeleteTag(ctx, uint(backend.ID)))
+	require.ErrorIs(t, s.DeleteTag(ctx, uint(backend.ID)), storage.ErrTagNotFound)
+
+	task, err = s.GetByID(ctx, uint(both.ID))
+	require.NoError(t, err)
+	require.Empty(t, task.Tags)
+}
+
+func TestStorageProjects(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	home, err := s.CreateProject(ctx, models.Project{Name: "home", Position: 2})
+	require.NoError(t, err)
+	work, err := s.CreateProject(ctx, models.Project{Name: "work", Position: 1})
+	require.NoError(t, err)
+
+	projects, err := s.ListProjects(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"work", "home"}, projectNames(projects))
+
+	archived := true
+	_, err = s.UpdateProject(ctx, uint(work.ID), models.ProjectPatch{Archived: &archived})
+	require.NoError(t, err)
+
+	active := false
+	projects, err = s.ListProjects(ctx, &active)
+	require.NoError(t, err)
+	require.Equal(t, []string{"home"}, projectNames(projects))
+
+	missing := int64(42)
+	_, err = s.CreateTask(ctx, models.Task{Title: "lost", DueDate: day, ProjectID: &missing})
+	require.ErrorIs(t, err, storage.ErrProjectNotFound)
+
+	paint, err := s.CreateTask(ctx, models.Task{Title: "paint", DueDate: day, ProjectID: &home.ID})
+	require.NoError(t, err)
+	create(t, s, models.Task{Title: "clean", DueDate: day.Add(time.Hour), ProjectID: &home.ID})
+	create(t, s, models.Task{Title: "inbox", DueDate: day.Add(2 * time.Hour)})
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, ProjectID: &home.ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, list.Total)
+	require.Equal(t, []string{"paint", "clean"}, titles(list.Data))
+	require.Equal(t, home.ID, *list.Data[0].ProjectID)
+
+	_, err = s.DeleteProject(ctx, uint(home.ID), models.ProjectDeleteRefuse)
+	require.ErrorIs(t, err, storage.ErrProjectNotEmpty)
+
+	// Перенос задачи в другой проект и обратно во входящие.
+	task, err := s.PatchTask(ctx, uint(paint.ID), models.TaskPatch{ProjectID: &work.ID})
+	require.NoError(t, err)
+	require.Equal(t, work.ID, *task.ProjectID)
+
+	inbox := int64(0)
+	task, err = s.PatchTask(ctx, uint(paint.ID), models.TaskPatch{ProjectID: &inbox})
+	require.NoError(t, err)
+	require.Nil(t, task.ProjectID)
+
+	moved, err := s.DeleteProject(ctx, uint(home.ID), models.ProjectDeleteInbox)
+	require.NoError(t, err)
+	require.Equal(t, []string{"clean"}, titles(moved))
+	require.Nil(t, moved[0].ProjectID)
+	require.EqualValues(t, 2, moved[0].Version)
+	_, err = s.GetProject(ctx, uint(home.ID))
+	require.ErrorIs(t, err, storage.ErrProjectNotFound)
+
+	task, err = s.GetByID(ctx, 2)
+	require.NoError(t, err)
+	require.Nil(t, task.ProjectID)
+	require.EqualValues(t, 2, task.Version)
+
+	_, err = s.PatchTask(ctx, uint(paint.ID), models.TaskPatch{ProjectID: &work.ID})
+	require.NoError(t, err)
+	create(t, s, models.Task{Title: "primer", DueDate: day, ParentID: &paint.ID})
+
+	deleted, err := s.DeleteProject(ctx, uint(work.ID), models.ProjectDeleteCascade)
+	require.NoError(t, err)
+	require.Equal(t, []string{"paint", "primer"}, titles(deleted))
+
+	_, err = s.GetByID(ctx, uint(paint.ID))
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"clean", "inbox"}, titles(list.Data))
+
+	_, err = s.DeleteProject(ctx, uint(work.ID), models.ProjectDeleteCascade)
+	require.ErrorIs(t, err, storage.ErrProjectNotFound)
+}
+
+func TestStorageSubtasks(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	root, err := s.CreateTask(ctx, models.Task{Title: "release", DueDate: day.Add(3 * time.Hour)})
+	require.NoError(t, err)
+	docs, err := s.CreateTask(ctx, models.Task{Title: "docs", DueDate: day.Add(2 * time.Hour), ParentID: &root.ID})
+	require.NoError(t, err)
+	build, err := s.CreateTask(ctx, models.Task{Title: "build", DueDate: day, ParentID: &root.ID})
+	require.NoError(t, err)
+	tests, err := s.CreateTask(ctx, models.Task{Title: "tests", DueDate: day.Add(time.Hour), ParentID: &build.ID, Status: models.StatusDone})
+	require.NoError(t, err)
+	create(t, s, models.Task{Title: "other", DueDate: day})
+
+	missing := int64(42)
+	_, err = s.CreateTask(ctx, models.Task{Title: "orphan", DueDate: day, ParentID: &missing})
+	require.ErrorIs(t, err, storage.ErrParentNotFound)
+
+	// Задачу нельзя вложить в саму себя или в свою подзадачу.
+	_, err = s.PatchTask(ctx, uint(root.ID), models.TaskPatch{ParentID: &tests.ID})
+	require.ErrorIs(t, err, storage.ErrTaskCycle)
+	_, err = s.PatchTask(ctx, uint(root.ID), models.TaskPatch{ParentID: &root.ID})
+	require.ErrorIs(t, err, storage.ErrTaskCycle)
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, ParentID: &root.ID})
+	require.NoError(t, err)
+	require.Equal(t, []string{"build", "docs"}, titles(list.Data))
+
+	tasks, err := s.Subtree(ctx, uint(root.ID))
+	require.NoError(t, err)
+	require.Equal(t, []string{"build", "tests", "docs", "release"}, titles(tasks))
+	require.Equal(t, build.ID, *tasks[1].ParentID)
+
+	_, err = s.Subtree(ctx, 100)
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	progress, err := s.Progress(ctx, uint(root.ID))
+	require.NoError(t, err)
+	require.Equal(t, models.TaskProgress{Children: 2, Subtasks: 3, Completed: 1, Percent: 33}, *progress)
+
+	done := models.StatusDone
+	_, err = s.PatchTask(ctx, uint(root.ID), models.TaskPatch{Status: &done})
+	require.ErrorIs(t, err, storage.ErrOpenSubtasks)
+
+	// Отклонённое изменение не выполняет и подзадачи.
+	stale := *root
+	stale.Status = done
+	stale.Version = root.Version + 1
+	require.ErrorIs(t, s.UpdateTask(ctx, &stale, models.SubtasksComplete), storage.ErrVersionMismatch)
+	_, err = s.PatchTask(ctx, uint(root.ID), models.TaskPatch{Status: &done, Version: root.Version + 1, Children: models.SubtasksComplete})
+	require.ErrorIs(t, err, storage.ErrVersionMismatch)
+	progress, err = s.Progress(ctx, uint(root.ID))
+	require.NoError(t, err)
+	require.Equal(t, 1, progress.Completed)
+
+	task, err := s.PatchTask(ctx, uint(root.ID), models.TaskPatch{Status: &done, Children: models.SubtasksComplete})
+	require.NoError(t, err)
+	require.Equal(t, models.StatusDone, task.Status)
+
+	progress, err = s.Progress(ctx, uint(root.ID))
+	require.NoError(t, err)
+	require.Equal(t, 3, progress.Completed)
+
+	progress, err = s.Progress(ctx, uint(docs.ID))
+	require.NoError(t, err)
+	require.Equal(t, models.TaskProgress{Percent: 100}, *progress)
+
+	// Перенос подзадачи на верхний уровень.
+	top := int64(0)
+	task, err = s.PatchTask(ctx, uint(docs.ID), models.TaskPatch{ParentID: &top})
+	require.NoError(t, err)
+	require.Nil(t, task.ParentID)
+
+	require.NoError(t, s.DeleteTask(ctx, uint(root.ID), 0))
+	_, err = s.GetByID(ctx, uint(tests.ID))
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"other", "docs"}, titles(list.Data))
+}
+
+func TestStorageDependencies(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	docs, err := s.CreateTask(ctx, models.Task{Title: "docs", DueDate: day})
+	require.NoError(t, err)
+	build, err := s.CreateTask(ctx, models.Task{Title: "build", DueDate: day.Add(time.Hour)})
+	require.NoError(t, err)
+	design, err := s.CreateTask(ctx, models.Task{Title: "design", DueDate: day.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	deploy, err := s.CreateTask(ctx, models.Task{Title: "deploy", DueDate: day.Add(3 * time.Hour)})
+	require.NoError(t, err)
+
+	task, err := s.AddBlocker(ctx, uint(deploy.ID), uint(build.ID), 0)
+	require.NoError(t, err)
+	require.True(t, task.Blocked)
+	require.EqualValues(t, 2, task.Version)
+
+	// Повторное добавление не меняет версию.
+	task, err = s.AddBlocker(ctx, uint(deploy.ID), uint(build.ID), 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, task.Version)
+
+	_, err = s.AddBlocker(ctx, uint(build.ID), uint(design.ID), 1)
+	require.NoError(t, err)
+
+	_, err = s.AddBlocker(ctx, uint(design.ID), uint(deploy.ID), 0)
+	require.ErrorIs(t, err, storage.ErrDependencyCycle)
+	_, err = s.AddBlocker(ctx, uint(design.ID), uint(design.ID), 0)
+	require.ErrorIs(t, err, storage.ErrDependencyCycle)
+	_, err = s.AddBlocker(ctx, uint(design.ID), 42, 0)
+	require.ErrorIs(t, err, storage.ErrBlockerNotFound)
+	_, err = s.AddBlocker(ctx, 42, uint(design.ID), 0)
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+	_, err = s.AddBlocker(ctx, uint(docs.ID), uint(design.ID), 5)
+	require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+	blockers, err := s.Blockers(ctx, uint(deploy.ID))
+	require.NoError(t, err)
+	require.Equal(t, []string{"build"}, titles(blockers))
+
+	blocked := true
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Blocked: &blocked})
+	require.NoError(t, err)
+	require.Equal(t, []string{"build", "deploy"}, titles(list.Data))
+
+	blocked = false
+	list, err = s.List(ctx, models.TaskFilter{Page: 1, Limit: 10, Blocked: &blocked})
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs", "design"}, titles(list.Data))
+
+	// Блокирующие задачи идут раньше, даже если их срок позже.
+	next, err := s.NextTasks(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs", "design", "build", "deploy"}, titles(next))
+
+	next, err = s.NextTasks(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs", "design"}, titles(next))
+
+	done := models.StatusDone
+	_, err = s.PatchTask(ctx, uint(design.ID), models.TaskPatch{Status: &done})
+	require.NoError(t, err)
+
+	task, err = s.GetByID(ctx, uint(build.ID))
+	require.NoError(t, err)
+	require.False(t, task.Blocked)
+
+	next, err = s.NextTasks(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs", "build", "deploy"}, titles(next))
+
+	task, err = s.RemoveBlocker(ctx, uint(deploy.ID), uint(build.ID), 0)
+	require.NoError(t, err)
+	require.False(t, task.Blocked)
+	require.EqualValues(t, 3, task.Version)
+
+	_, err = s.RemoveBlocker(ctx, uint(deploy.ID), uint(build.ID), 0)
+	require.ErrorIs(t, err, storage.ErrDependencyNotFound)
+	_, err = s.RemoveBlocker(ctx, 42, uint(build.ID), 0)
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	// Удаление задачи удаляет и её зависимости.
+	require.NoError(t, s.DeleteTask(ctx, uint(design.ID), 0))
+	blockers, err = s.Blockers(ctx, uint(build.ID))
+	require.NoError(t, err)
+	require.Empty(t, blockers)
+
+	_, err = s.Blockers(ctx, 42)
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+}
+
+func TestStorageRecurrence(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	task, err := s.CreateTask(ctx, models.Task{
+		Title:    "standup",
+		DueDate:  day,
+		Tags:     []models.Tag{{Name: "work"}},
+		RRule:    "FREQ=DAILY;COUNT=2",
+		Timezone: "Europe/Moscow",
+	})
+	require.NoError(t, err)
+	require.Nil(t, task.NextID)
+
+	done := models.StatusDone
+	completed, err := s.PatchTask(ctx, uint(task.ID), models.TaskPatch{Status: &done})
+	require.NoError(t, err)
+	require.Empty(t, completed.RRule)
+	require.NotNil(t, completed.NextID)
+	require.EqualValues(t, 2, completed.Version)
+
+	next, err := s.GetByID(ctx, uint(*completed.NextID))
+	require.NoError(t, err)
+	require.Equal(t, models.StatusTodo, next.Status)
+	require.True(t, next.DueDate.Equal(day.AddDate(0, 0, 1)))
+	require.Equal(t, "FREQ=DAILY;COUNT=1", next.RRule)
+	require.Equal(t, "Europe/Moscow", next.Timezone)
+	require.Equal(t, []string{"work"}, models.TagNames(next.Tags))
+
+	// Последнее повторение серии завершает её без новой задачи.
+	next.Status = models.StatusDone
+	require.NoError(t, s.UpdateTask(ctx, next, models.SubtasksBlock))
+	require.Empty(t, next.RRule)
+	require.Nil(t, next.NextID)
+
+	stored, err := s.GetByID(ctx, uint(next.ID))
+	require.NoError(t, err)
+	require.Empty(t, stored.RRule)
+
+	list, err := s.List(ctx, models.TaskFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"standup", "standup"}, titles(list.Data))
+
+	// Задача, созданная выполненной, сразу переносит правило на повторение.
+	weekly, err := s.CreateTask(ctx, models.Task{Title: "review", DueDate: day, Status: models.StatusDone, RRule: "FREQ=WEEKLY"})
+	require.NoError(t, err)
+	require.Empty(t, weekly.RRule)
+	require.NotNil(t, weekly.NextID)
+
+	// Удаление повторения обнуляет ссылку на него.
+	require.NoError(t, s.DeleteTask(ctx, uint(*weekly.NextID), 0))
+	stored, err = s.GetByID(ctx, uint(weekly.ID))
+	require.NoError(t, err)
+	require.Nil(t, stored.NextID)
+
+	// Выполнение родителя вместе с подзадачами создаёт следующее
+	// повторение повторяющейся подзадачи.
+	parent, err := s.CreateTask(ctx, models.Task{Title: "release", DueDate: day})
+	require.NoError(t, err)
+	daily, err := s.CreateTask(ctx, models.Task{Title: "deploy", DueDate: day, ParentID: &parent.ID, RRule: "FREQ=DAILY"})
+	require.NoError(t, err)
+
+	_, err = s.PatchTask(ctx, uint(parent.ID), models.TaskPatch{Status: &done, Children: models.SubtasksComplete})
+	require.NoError(t, err)
+
+	stored, err = s.GetByID(ctx, uint(daily.ID))
+	require.NoError(t, err)
+	require.Equal(t, models.StatusDone, stored.Status)
+	require.Empty(t, stored.RRule)
+	require.NotNil(t, stored.NextID)
+
+	next, err = s.GetByID(ctx, uint(*stored.NextID))
+	require.NoError(t, err)
+	require.Equal(t, models.StatusTodo, next.Status)
+	require.True(t, next.DueDate.Equal(day.AddDate(0, 0, 1)))
+	require.Equal(t, "FREQ=DAILY", next.RRule)
+	require.Equal(t, parent.ID, *next.ParentID)
+}
+
+func TestStorageReminders(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	day := time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC)
+
+	task, err := s.CreateTask(ctx, models.Task{Title: "standup", DueDate: day, RRule: "FREQ=DAILY"})
+	require.NoError(t, err)
+	create(t, s, models.Task{Title: "done", DueDate: day, Status: models.StatusDone})
+
+	before := models.Duration(15 * time.Minute)
+	offset, err := s.CreateReminder(ctx, models.Reminder{TaskID: task.ID, Before: &before})
+	require.NoError(t, err)
+	require.True(t, day.Add(-15*time.Minute).Equal(offset.FireAt))
+
+	at := day.Add(-time.Hour)
+	absolute, err := s.CreateReminder(ctx, models.Reminder{TaskID: task.ID, RemindAt: &at})
+	require.NoError(t, err)
+
+	_, err = s.CreateReminder(ctx, models.Reminder{TaskID: 2, RemindAt: &at})
+	require.NoError(t, err)
+
+	_, err = s.CreateReminder(ctx, models.Reminder{TaskID: 42, RemindAt: &at})
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	reminders, err := s.ListReminders(ctx, uint(task.ID))
+	require.NoError(t, err)
+	require.Len(t, reminders, 2)
+	require.Equal(t, absolute.ID, reminders[0].ID)
+	require.Equal(t, before, *reminders[1].Before)
+
+	// Напоминание закрытой задачи не выбирается, выбранное закреплено
+	// за экземпляром на время аренды.
+	claim := models.ReminderClaim{Now: day.Add(-30 * time.Minute), Lease: time.Minute, Limit: 10, MaxAttempts: 2}
+	claimed, err := s.ClaimReminders(ctx, claim)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, absolute.ID, claimed[0].ID)
+
+	claimed, err = s.ClaimReminders(ctx, claim)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	require.NoError(t, s.MarkReminderFailed(ctx, absolute.ID, "smtp: timeout", claim.Now.Add(time.Minute)))
+	claim.Now = day
+	claimed, err = s.ClaimReminders(ctx, claim)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, 1, claimed[0].Attempts)
+	require.Equal(t, "smtp: timeout", claimed[0].LastError)
+
+	require.NoError(t, s.MarkReminderFailed(ctx, absolute.ID, "smtp: timeout", day))
+	require.NoError(t, s.MarkReminderSent(ctx, offset.ID, day))
+	require.ErrorIs(t, s.MarkReminderSent(ctx, 42, day), storage.ErrReminderNotFound)
+
+	// После max_attempts неудач напоминание больше не выбирается.
+	claimed, err = s.ClaimReminders(ctx, claim)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	// Перенос срока снова ставит напоминание относительно срока в очередь.
+	due := day.Add(2 * time.Hour)
+	_, err = s.PatchTask(ctx, uint(task.ID), models.TaskPatch{DueDate: &due})
+	require.NoError(t, err)
+
+	reminders, err = s.ListReminders(ctx, uint(task.ID))
+	require.NoError(t, err)
+	require.Equal(t, absolute.ID, reminders[0].ID)
+	require.True(t, due.Add(-15*time.Minute).Equal(reminders[1].FireAt))
+	require.Nil(t, reminders[1].SentAt)
+
+	// Следующее повторение получает напоминания относительно срока.
+	done := models.StatusDone
+	completed, err := s.PatchTask(ctx, uint(task.ID), models.TaskPatch{Status: &done})
+	require.NoError(t, err)
+
+	reminders, err = s.ListReminders(ctx, uint(*completed.NextID))
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	require.Equal(t, before, *reminders[0].Before)
+	require.True(t, due.AddDate(0, 0, 1).Add(-15*time.Minute).Equal(reminders[0].FireAt))
+
+	require.ErrorIs(t, s.DeleteReminder(ctx, uint(task.ID), uint(reminders[0].ID)), storage.ErrReminderNotFound)
+	require.NoError(t, s.DeleteReminder(ctx, uint(*completed.NextID), uint(reminders[0].ID)))
+
+	require.NoError(t, s.DeleteTask(ctx, uint(task.ID), 0))
+	_, err = s.ListReminders(ctx, uint(task.ID))
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+}
+
+func TestStorageWebhooks(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	_, err := s.GetWebhook(ctx, 42)
+	require.ErrorIs(t, err, storage.ErrWebhookNotFound)
+
+	ci, err := s.CreateWebhook(ctx, models.Webhook{
+		URL:    "https://ci.example.com/hooks",
+		Secret: "ci-secret-0123456789",
+		Events: []models.WebhookEventType{models.EventTaskCreated, models.EventTaskCompleted},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ci-secret-0123456789", ci.Secret)
+
+	all, err := s.CreateWebhook(ctx, models.Webhook{
+		URL:    "https://audit.example.com/hooks",
+		Secret: "audit-secret-0123456789",
+		Events: models.WebhookEventTypes,
+	})
+	require.NoError(t, err)
+
+	webhooks, err := s.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	require.Equal(t, ci.ID, webhooks[0].ID)
+	require.Empty(t, webhooks[0].Secret)
+	require.Equal(t, []models.WebhookEventType{models.EventTaskCreated, models.EventTaskCompleted}, webhooks[0].Events)
+	require.Equal(t, models.WebhookEventTypes, webhooks[1].Events)
+
+	got, err := s.GetWebhook(ctx, uint(all.ID))
+	require.NoError(t, err)
+	require.Empty(t, got.Secret)
+	require.Equal(t, "https://audit.example.com/hooks", got.URL)
+
+	task := models.Task{ID: 7, Title: "Ship"}
+	n, err := s.EnqueueEvent(ctx, models.WebhookEvent{Type: models.EventTaskCreated, OccurredAt: time.Now(), Task: task})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = s.EnqueueEvent(ctx, models.WebhookEvent{Type: models.EventTaskUpdated, OccurredAt: time.Now(), Task: task})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	now := time.Now().Add(time.Second)
+	claim := models.DeliveryClaim{Now: now, Lease: time.Minute, Limit: 10}
+	claimed, err := s.ClaimDeliveries(ctx, claim)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	require.Equal(t, ci.ID, claimed[0].Delivery.WebhookID)
+	require.Equal(t, "https://ci.example.com/hooks", claimed[0].URL)
+	require.Equal(t, "ci-secret-0123456789", claimed[0].Secret)
+	require.Equal(t, "audit-secret-0123456789", claimed[1].Secret)
+	require.Equal(t, models.EventTaskUpdated, claimed[2].Delivery.Event)
+	var event models.WebhookEvent
+	require.NoError(t, json.Unmarshal(claimed[0].Delivery.Payload, &event))
+	require.Equal(t, models.EventTaskCreated, event.Type)
+	require.Equal(t, "Ship", event.Task.Title)
+
+	// Закреплённые доставки не выбираются повторно до истечения аренды.
+	again, err := s.ClaimDeliveries(ctx, claim)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	retryAt := now.Add(time.Minute)
+	require.NoError(t, s.RecordDeliveryAttempt(ctx, claimed[0].Delivery.ID, models.DeliveryAttempt{At: now, ResponseCode: 204}))
+	require.NoError(t, s.RecordDeliveryAttempt(ctx, claimed[1].Delivery.ID, models.DeliveryAttempt{
+		At: now, ResponseCode: 502, Error: "webhook: unexpected status 502", RetryAt: &retryAt,
+	}))
+	require.NoError(t, s.RecordDeliveryAttempt(ctx, claimed[2].Delivery.ID, models.DeliveryAttempt{At: now, Error: "connection refused"}))
+
+	log, err := s.ListDeliveries(ctx, uint(ci.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, log.Total)
+	delivered := log.Data[0]
+	require.Equal(t, models.DeliveryDelivered, delivered.Status)
+	require.Equal(t, 1, delivered.Attempts)
+	require.Equal(t, 204, delivered.ResponseCode)
+	require.Nil(t, delivered.NextAttemptAt)
+	require.NotNil(t, delivered.DeliveredAt)
+
+	log, err = s.ListDeliveries(ctx, uint(all.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, log.Total)
+	require.Equal(t, claimed[2].Delivery.ID, log.Data[0].ID)
+	require.Equal(t, models.DeliveryFailed, log.Data[0].Status)
+	require.Equal(t, "connection refused", log.Data[0].LastError)
+	require.Nil(t, log.Data[0].NextAttemptAt)
+	require.Equal(t, models.DeliveryPending, log.Data[1].Status)
+	require.Equal(t, 502, log.Data[1].ResponseCode)
+	require.WithinDuration(t, retryAt, *log.Data[1].NextAttemptAt, time.Second)
+
+	failed := models.DeliveryFailed
+	log, err = s.ListDeliveries(ctx, uint(all.ID), models.DeliveryFilter{Page: 1, Limit: 10, Status: &failed})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, log.Total)
+	require.Equal(t, claimed[2].Delivery.ID, log.Data[0].ID)
+
+	log, err = s.ListDeliveries(ctx, uint(all.ID), models.DeliveryFilter{Page: 2, Limit: 1})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, log.Total)
+	require.Len(t, log.Data, 1)
+	require.Equal(t, claimed[1].Delivery.ID, log.Data[0].ID)
+
+	// Повтор выбирается, когда наступает его время.
+	claimed, err = s.ClaimDeliveries(ctx, models.DeliveryClaim{Now: retryAt, Lease: time.Minute, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, 1, claimed[0].Delivery.Attempts)
+
+	require.NoError(t, s.DeleteWebhook(ctx, uint(all.ID)))
+	require.ErrorIs(t, s.DeleteWebhook(ctx, uint(all.ID)), storage.ErrWebhookNotFound)
+	_, err = s.ListDeliveries(ctx, uint(all.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+	require.ErrorIs(t, err, storage.ErrWebhookNotFound)
+	require.ErrorIs(t, s.RecordDeliveryAttempt(ctx, claimed[0].Delivery.ID, models.DeliveryAttempt{At: retryAt}), storage.ErrWebhookNotFound)
+}
+
+func TestStorageTaskEvents(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	events, err := s.ListTaskEvents(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	projectID := int64(2)
+	for i, title := range []string{"a", "b", "c", "d"} {
+		stored, err := s.AppendTaskEvent(ctx, models.WebhookEvent{
+			Type:       models.EventTaskUpdated,
+			OccurredAt: time.Now(),
+			Task:       models.Task{ID: int64(i + 1), Title: title, ProjectID: &projectID, Tags: []models.Tag{{ID: 1, Name: "ui"}}},
+		}, 3)
+		require.NoError(t, err)
+		require.Equal(t, int64(i+1), stored.ID)
+	}
+
+	// Журнал хранит три последних события.
+	events, err = s.ListTaskEvents(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, int64(2), events[0].ID)
+	require.Equal(t, models.EventTaskUpdated, events[0].Type)
+	require.Equal(t, "b", events[0].Task.Title)
+	require.Equal(t, projectID, *events[0].Task.ProjectID)
+	require.Equal(t, "ui", events[0].Task.Tags[0].Name)
+
+	events, err = s.ListTaskEvents(ctx, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "c", events[0].Task.Title)
+
+	events, err = s.ListTaskEvents(ctx, 4, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	_, err = s.ListTaskEvents(ctx, 0, 10)
+	require.ErrorIs(t, err, storage.ErrEventsExpired)
+}
+
+func TestStorageFeedTokens(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	_, err := s.FindFeedToken(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrFeedTokenNotFound)
+
+	phone, err := s.CreateFeedToken(ctx, "phone", "hash-phone")
+	require.NoError(t, err)
+	require.NotZero(t, phone.ID)
+	require.Empty(t, phone.Token)
+
+	laptop, err := s.CreateFeedToken(ctx, "laptop", "hash-laptop")
+	require.NoError(t, err)
+
+	tokens, err := s.ListFeedTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	require.Equal(t, "phone", tokens[0].Name)
+	require.Equal(t, laptop.ID, tokens[1].ID)
+
+	found, err := s.FindFeedToken(ctx, "hash-laptop")
+	require.NoError(t, err)
+	require.Equal(t, laptop.ID, found.ID)
+	require.Equal(t, "laptop", found.Name)
+
+	require.NoError(t, s.DeleteFeedToken(ctx, uint(laptop.ID)))
+	require.ErrorIs(t, s.DeleteFeedToken(ctx, uint(laptop.ID)), storage.ErrFeedTokenNotFound)
+
+	_, err = s.FindFeedToken(ctx, "hash-laptop")
+	require.ErrorIs(t, err, storage.ErrFeedTokenNotFound)
+}
+
+func TestStorageCalendarObjects(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	first, err := s.CreateTask(ctx, models.Task{Title: "first", DueDate: time.Now()})
+	require.NoError(t, err)
+	second, err := s.CreateTask(ctx, models.Task{Title: "second", DueDate: time.Now()})
+	require.NoError(t, err)
+
+	objects, err := s.CalendarObjects(ctx)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+
+	require.NoError(t, s.SaveCalendarObject(ctx, models.CalendarObject{TaskID: first.ID, Name: "a.ics", UID: "a@client"}))
+	require.ErrorIs(t, s.SaveCalendarObject(ctx, models.CalendarObject{TaskID: second.ID, Name: "a.ics", UID: "b@client"}),
+		storage.ErrCalendarObjectExists)
+	require.ErrorIs(t, s.SaveCalendarObject(ctx, models.CalendarObject{TaskID: 100, Name: "c.ics", UID: "c@client"}),
+		storage.ErrTaskNotFound)
+	require.NoError(t, s.SaveCalendarObject(ctx, models.CalendarObject{TaskID: second.ID, Name: "b.ics", UID: "b@client"}))
+
+	// Повторное сохранение заменяет имя и UID задачи.
+	require.NoError(t, s.SaveCalendarObject(ctx, models.CalendarObject{TaskID: first.ID, Name: "a2.ics", UID: "a2@client"}))
+
+	objects, err = s.CalendarObjects(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.CalendarObject{
+		{TaskID: first.ID, Name: "a2.ics", UID: "a2@client"},
+		{TaskID: second.ID, Name: "b.ics", UID: "b@client"},
+	}, objects)
+
+	// Удаление задачи удаляет и ресурс.
+	require.NoError(t, s.DeleteTask(ctx, uint(second.ID), 0))
+
+	objects, err = s.CalendarObjects(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.CalendarObject{{TaskID: first.ID, Name: "a2.ics", UID: "a2@client"}}, objects)
+}
+
+func create(t *testing.T, s *memory.Storage, task models.Task) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	_, err := s.CreateTask(ctx, task)
+	require.NoError(t, err)
+}
+
+func titles(tasks []models.Task) []string {
+	var res []string
+	for _, task := range tasks {
+		res = append(res, task.Title)
+	}
+	return res
+}
+
+func tagNames(tags []models.Tag) []string {
+	var res []string
+	for _, tag := range tags {
+		res = append(res, tag.Name)
+	}
+	return res
+}
+
+func projectNames(projects []models.Project) []string {
+	var res []string
+	for _, project := range projects {
+		res = append(res, project.Name)
+	}
+	return res
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func (s *Storage) CreateProject(ctx context.Context, project models.Project) (*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	project.ID = s.nextProjectID
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	s.projects[project.ID] = project
+	s.nextProjectID++
+
+	return &project, nil
+}
+
+func (s *Storage) GetProject(ctx context.Context, id uint) (*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.projects[int64(id)]
+	if !ok {
+		return nil, storage.ErrProjectNotFound
+	}
+
+	return &project, nil
+}
+
+// ListProjects возвращает проекты в порядке position, а при равенстве —
+// в порядке создания. Ненулевой archived оставляет только архивные или
+// только активные проекты.
+func (s *Storage) ListProjects(ctx context.Context, archived *bool) ([]models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]models.Project, 0, len(s.projects))
+	for _, project := range s.projects {
+		if archived != nil && project.Archived != *archived {
+			continue
+		}
+		projects = append(projects, project)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Position != projects[j].Position {
+			return projects[i].Position < projects[j].Position
+		}
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
+
+func (s *Storage) UpdateProject(ctx context.Context, id uint, patch models.ProjectPatch) (*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[int64(id)]
+	if !ok {
+		return nil, storage.ErrProjectNotFound
+	}
+
+	if patch.Name != nil {
+		project.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		project.Description = *patch.Description
+	}
+	if patch.Archived != nil {
+		project.Archived = *patch.Archived
+	}
+	if patch.Position != nil {
+		project.Position = *patch.Position
+	}
+	project.UpdatedAt = time.Now()
+
+	s.projects[project.ID] = project
+
+	return &project, nil
+}
+
+// DeleteProject удаляет проект, поступая с его задачами согласно policy,
+// и возвращает затронутые задачи: перенесённые во входящие — в новом
+// состоянии, удалённые вместе с подзадачами — в состоянии до удаления.
+func (s *Storage) DeleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[int64(id)]; !ok {
+		return nil, storage.ErrProjectNotFound
+	}
+
+	var taskIDs []int64
+	for _, task := range s.tasks {
+		if task.ProjectID != nil && *task.ProjectID == int64(id) {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+
+	var affected []models.Task
+	if len(taskIDs) > 0 {
+		switch policy {
+		case models.ProjectDeleteInbox:
+			now := time.Now()
+			for _, taskID := range taskIDs {
+				task := s.tasks[taskID]
+				task.ProjectID = nil
+				task.UpdatedAt = now
+				task.Version++
+				s.tasks[taskID] = task
+				affected = append(affected, *s.view(task))
+			}
+		case models.ProjectDeleteCascade:
+			// Подзадачи удаляются вместе с задачами, как по каскадному
+			// внешнему ключу parent_id в базе. Подзадача может и сама
+			// лежать в проекте, поэтому задачи собираются без повторов.
+			seen := make(map[int64]bool)
+			for _, taskID := range taskIDs {
+				for _, subtaskID := range append(s.descendants(taskID), taskID) {
+					if !seen[subtaskID] {
+						seen[subtaskID] = true
+						affected = append(affected, *s.view(s.tasks[subtaskID]))
+					}
+				}
+			}
+			for _, taskID := range taskIDs {
+				s.deleteSubtree(taskID)
+			}
+		default:
+			return nil, storage.ErrProjectNotEmpty
+		}
+	}
+
+	delete(s.projects, int64(id))
+
+	sortTasks(affected)
+
+	return affected, nil
+}
+
+// hasProject сообщает, что задачу можно отнести к проекту id: nil означает
+// входящие и допустим всегда.
+func (s *Storage) hasProject(id *int64) bool {
+	if id == nil {
+		return true
+	}
+	_, ok := s.projects[*id]
+	return ok
+}
+
+// copyID возвращает копию указателя, чтобы хранимая задача не разделяла
+// память с задачей вызывающего.
+func copyID(id *int64) *int64 {
+	if id == nil {
+		return nil
+	}
+	value := *id
+	return &value
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"time"
+
+	"todo/internal/models"
+)
+
+// insert добавляет новую задачу и возвращает её в том виде, в каком она
+// хранится: без меток, которые хранятся в taskTags.
+func (s *Storage) insert(task models.Task) models.Task {
+	now := time.Now()
+	task.ID = s.nextID
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.Version = 1
+	task.Priority = task.Priority.OrNone()
+	task.ProjectID = copyID(task.ProjectID)
+	task.ParentID = copyID(task.ParentID)
+	task.NextID = nil
+	task.CompletedAt, task.CancelledAt = nil, nil
+	setStatus(&task, task.Status, now)
+
+	s.taskTags[task.ID] = s.resolveTags(task.Tags)
+	task.Tags = nil
+	s.tasks[task.ID] = task
+	s.nextID++
+
+	return task
+}
+
+// spawnNext повторяет spawnNext хранилищ с базой данных: создаёт следующее
+// повторение закрытой повторяющейся задачи и переносит на него правило и
+// напоминания относительно срока.
+// Изменённую задачу сохраняет вызывающий.
+func (s *Storage) spawnNext(task *models.Task) error {
+	if !task.Status.Closed() || task.RRule == "" {
+		return nil
+	}
+
+	next, ok, err := s.view(*task).NextOccurrence()
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		next = s.insert(next)
+		s.copyReminders(task.ID, next)
+		task.NextID = &next.ID
+	}
+	task.RRule = ""
+
+	return nil
+}
+
+// unlinkNext обнуляет ссылки на удалённое повторение id, как внешний ключ
+// next_id с ON DELETE SET NULL в базе.
+func (s *Storage) unlinkNext(id int64) {
+	for taskID, task := range s.tasks {
+		if task.NextID != nil && *task.NextID == id {
+			task.NextID = nil
+			s.tasks[taskID] = task
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// reminder — напоминание и момент, до которого оно закреплено за
+// доставкой.
+type reminder struct {
+	models.Reminder
+	lockedUntil time.Time
+}
+
+// CreateReminder добавляет напоминание к задаче reminder.TaskID и
+// вычисляет момент его срабатывания по сроку задачи.
+func (s *Storage) CreateReminder(ctx context.Context, r models.Reminder) (*models.Reminder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[r.TaskID]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	r.ID = s.nextReminderID
+	r.FireAt = r.FireTime(task.DueDate)
+	r.CreatedAt = time.Now()
+	r.SentAt = nil
+	r.Attempts = 0
+	r.LastError = ""
+	s.reminders[r.ID] = reminder{Reminder: r}
+	s.nextReminderID++
+
+	return &r, nil
+}
+
+// ListReminders возвращает напоминания задачи в порядке срабатывания.
+func (s *Storage) ListReminders(ctx context.Context, taskID uint) ([]models.Reminder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tasks[int64(taskID)]; !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	reminders := []models.Reminder{}
+	for _, r := range s.reminders {
+		if r.TaskID == int64(taskID) {
+			reminders = append(reminders, r.Reminder)
+		}
+	}
+	sortReminders(reminders)
+
+	return reminders, nil
+}
+
+// DeleteReminder удаляет напоминание id задачи taskID.
+func (s *Storage) DeleteReminder(ctx context.Context, taskID, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reminders[int64(id)]
+	if !ok || r.TaskID != int64(taskID) {
+		return storage.ErrReminderNotFound
+	}
+	delete(s.reminders, int64(id))
+
+	return nil
+}
+
+// ClaimReminders закрепляет за вызывающим сработавшие и ещё не доставленные
+// напоминания открытых задач на claim.Lease и возвращает их в порядке
+// срабатывания.
+func (s *Storage) ClaimReminders(ctx context.Context, claim models.ReminderClaim) ([]models.Reminder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []models.Reminder{}
+	for _, r := range s.reminders {
+		if r.SentAt != nil || r.FireAt.After(claim.Now) || r.Attempts >= claim.MaxAttempts ||
+			r.lockedUntil.After(claim.Now) || s.tasks[r.TaskID].Status.Closed() {
+			continue
+		}
+		due = append(due, r.Reminder)
+	}
+	sortReminders(due)
+	if len(due) > claim.Limit {
+		due = due[:claim.Limit]
+	}
+
+	for _, r := range due {
+		s.reminders[r.ID] = reminder{Reminder: r, lockedUntil: claim.Now.Add(claim.Lease)}
+	}
+
+	return due, nil
+}
+
+// MarkReminderSent отмечает напоминание доставленным в момент at.
+func (s *Storage) MarkReminderSent(ctx context.Context, id int64, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reminders[id]
+	if !ok {
+		return storage.ErrReminderNotFound
+	}
+	r.SentAt = &at
+	r.lockedUntil = time.Time{}
+	s.reminders[id] = r
+
+	return nil
+}
+
+// MarkReminderFailed записывает неудачную попытку доставки: увеличивает
+// число попыток, сохраняет причину и откладывает следующую попытку до
+// retryAt.
+func (s *Storage) MarkReminderFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reminders[id]
+	if !ok {
+		return storage.ErrReminderNotFound
+	}
+	r.Attempts++
+	r.LastError = reason
+	r.lockedUntil = retryAt
+	s.reminders[id] = r
+
+	return nil
+}
+
+// rescheduleReminders переносит напоминания задачи, заданные относительно
+// срока, на её текущий срок. Перенесённое напоминание снова ждёт доставки.
+func (s *Storage) rescheduleReminders(task models.Task) {
+	for id, r := range s.reminders {
+		if r.TaskID != task.ID || r.Before == nil {
+			continue
+		}
+		fireAt := r.FireTime(task.DueDate)
+		if fireAt.Equal(r.FireAt) {
+			continue
+		}
+		r.FireAt = fireAt
+		r.SentAt = nil
+		r.Attempts = 0
+		r.LastError = ""
+		r.lockedUntil = time.Time{}
+		s.reminders[id] = r
+	}
+}
+
+// copyReminders добавляет задаче next напоминания задачи from, заданные
+// относительно срока.
+func (s *Storage) copyReminders(from int64, next models.Task) {
+	now := time.Now()
+	for _, r := range s.reminders {
+		if r.TaskID != from || r.Before == nil {
+			continue
+		}
+		before := *r.Before
+		copied := models.Reminder{
+			ID:        s.nextReminderID,
+			TaskID:    next.ID,
+			Before:    &before,
+			CreatedAt: now,
+		}
+		copied.FireAt = copied.FireTime(next.DueDate)
+		s.reminders[copied.ID] = reminder{Reminder: copied}
+		s.nextReminderID++
+	}
+}
+
+// deleteReminders удаляет напоминания задачи, как внешний ключ с
+// ON DELETE CASCADE в базе.
+func (s *Storage) deleteReminders(taskID int64) {
+	for id, r := range s.reminders {
+		if r.TaskID == taskID {
+			delete(s.reminders, id)
+		}
+	}
+}
+
+// sortReminders упорядочивает напоминания по моменту срабатывания, затем
+// по id.
+func sortReminders(reminders []models.Reminder) {
+	sort.Slice(reminders, func(i, j int) bool {
+		if reminders[i].FireAt.Equal(reminders[j].FireAt) {
+			return reminders[i].ID < reminders[j].ID
+		}
+		return reminders[i].FireAt.Before(reminders[j].FireAt)
+	})
+}
@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"time"
+
+	"todo/internal/models"
+)
+
+// setStatus переводит задачу в status и обновляет отметки времени, как
+// хранилища с базой данных: отметка ставится при переходе в done или
+// cancelled, сохраняется, пока задача остаётся в этом статусе, и
+// сбрасывается при выходе из него.
+func setStatus(task *models.Task, status models.TaskStatus, now time.Time) {
+	task.Status = status.OrTodo()
+	task.CompletedAt = stamp(task.CompletedAt, task.Status == models.StatusDone, now)
+	task.CancelledAt = stamp(task.CancelledAt, task.Status == models.StatusCancelled, now)
+}
+
+// stamp возвращает отметку времени статуса: прежнюю или now, если задача
+// в этом статусе, и nil, если нет.
+func stamp(current *time.Time, on bool, now time.Time) *time.Time {
+	if !on {
+		return nil
+	}
+	if current != nil {
+		return current
+	}
+	return &now
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Subtree возвращает задачу id и все её подзадачи на любой глубине плоским
+// списком, упорядоченным по сроку выполнения.
+func (s *Storage) Subtree(ctx context.Context, id uint) ([]models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	root, ok := s.tasks[int64(id)]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	tasks := []models.Task{*s.view(root)}
+	for _, taskID := range s.descendants(root.ID) {
+		tasks = append(tasks, *s.view(s.tasks[taskID]))
+	}
+
+	sortTasks(tasks)
+
+	return tasks, nil
+}
+
+// sortTasks упорядочивает задачи по сроку выполнения, при равных сроках —
+// по id.
+func sortTasks(tasks []models.Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].DueDate.Equal(tasks[j].DueDate) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].DueDate.Before(tasks[j].DueDate)
+	})
+}
+
+// Progress считает подзадачи задачи id и долю выполненных среди них.
+func (s *Storage) Progress(ctx context.Context, id uint) (*models.TaskProgress, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	root, ok := s.tasks[int64(id)]
+	if !ok {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	children, completed := 0, 0
+	descendants := s.descendants(root.ID)
+	for _, taskID := range descendants {
+		task := s.tasks[taskID]
+		if *task.ParentID == root.ID {
+			children++
+		}
+		if task.Status.Closed() {
+			completed++
+		}
+	}
+
+	progress := models.NewTaskProgress(children, len(descendants), completed, root.Status.Closed())
+
+	return &progress, nil
+}
+
+// completeSubtasks отмечает выполненными открытые подзадачи задачи id на
+// любой глубине и возвращает их идентификаторы. Для повторяющихся подзадач
+// создаётся следующее повторение. Вызывающий держит s.mu.
+func (s *Storage) completeSubtasks(id int64, now time.Time) ([]int64, error) {
+	var completed []int64
+
+	for _, taskID := range s.descendants(id) {
+		task := s.tasks[taskID]
+		if task.Status.Closed() {
+			continue
+		}
+		setStatus(&task, models.StatusDone, now)
+		task.UpdatedAt = now
+		task.Version++
+		if err := s.spawnNext(&task); err != nil {
+			return nil, err
+		}
+		s.tasks[taskID] = task
+		completed = append(completed, taskID)
+	}
+
+	return completed, nil
+}
+
+// descendants возвращает идентификаторы всех подзадач задачи id на любой
+// глубине.
+func (s *Storage) descendants(id int64) []int64 {
+	children := make(map[int64][]int64)
+	for _, task := range s.tasks {
+		if task.ParentID != nil {
+			children[*task.ParentID] = append(children[*task.ParentID], task.ID)
+		}
+	}
+
+	var result []int64
+	queue := children[id]
+	for len(queue) > 0 {
+		taskID := queue[0]
+		queue = queue[1:]
+		result = append(result, taskID)
+		queue = append(queue, children[taskID]...)
+	}
+
+	return result
+}
+
+// checkParent повторяет проверку postgres: родитель должен существовать и
+// не быть самой задачей taskID или её подзадачей.
+func (s *Storage) checkParent(taskID, parentID int64) error {
+	parent, ok := s.tasks[parentID]
+	if !ok {
+		return storage.ErrParentNotFound
+	}
+
+	for {
+		if parent.ID == taskID {
+			return storage.ErrTaskCycle
+		}
+		if parent.ParentID == nil {
+			return nil
+		}
+		parent = s.tasks[*parent.ParentID]
+	}
+}
+
+// hasOpenSubtasks сообщает, что у задачи id есть невыполненные подзадачи.
+func (s *Storage) hasOpenSubtasks(id int64) bool {
+	for _, taskID := range s.descendants(id) {
+		if !s.tasks[taskID].Status.Closed() {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteSubtree удаляет задачу вместе со всеми подзадачами и их
+// зависимостями, как внешние ключи в базе.
+func (s *Storage) deleteSubtree(id int64) {
+	for _, taskID := range append(s.descendants(id), id) {
+		delete(s.tasks, taskID)
+		delete(s.taskTags, taskID)
+		s.removeDependencies(taskID)
+		s.unlinkNext(taskID)
+		s.deleteReminders(taskID)
+		delete(s.calendarObjects, taskID)
+	}
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+func (s *Storage) CreateTag(ctx context.Context, tag models.Tag) (*models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tagByName(tag.Name); ok {
+		return nil, storage.ErrTagExists
+	}
+
+	tag.ID = s.nextTagID
+	s.tags[tag.ID] = tag
+	s.nextTagID++
+
+	return &tag, nil
+}
+
+func (s *Storage) GetTag(ctx context.Context, id uint) (*models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tag, ok := s.tags[int64(id)]
+	if !ok {
+		return nil, storage.ErrTagNotFound
+	}
+
+	return &tag, nil
+}
+
+// ListTags возвращает все метки, упорядоченные по названию.
+func (s *Storage) ListTags(ctx context.Context) ([]models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tags := make([]models.Tag, 0, len(s.tags))
+	for _, tag := range s.tags {
+		tags = append(tags, tag)
+	}
+	sortTags(tags)
+
+	return tags, nil
+}
+
+func (s *Storage) UpdateTag(ctx context.Context, id uint, patch models.TagPatch) (*models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[int64(id)]
+	if !ok {
+		return nil, storage.ErrTagNotFound
+	}
+
+	if patch.Name != nil {
+		if other, ok := s.tagByName(*patch.Name); ok && other.ID != tag.ID {
+			return nil, storage.ErrTagExists
+		}
+		tag.Name = *patch.Name
+	}
+	if patch.Color != nil {
+		tag.Color = *patch.Color
+	}
+
+	s.tags[tag.ID] = tag
+
+	return &tag, nil
+}
+
+// DeleteTag удаляет метку и снимает её со всех задач.
+func (s *Storage) DeleteTag(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[int64(id)]; !ok {
+		return storage.ErrTagNotFound
+	}
+
+	delete(s.tags, int64(id))
+
+	for taskID, tagIDs := range s.taskTags {
+		kept := tagIDs[:0]
+		for _, tagID := range tagIDs {
+			if tagID != int64(id) {
+				kept = append(kept, tagID)
+			}
+		}
+		s.taskTags[taskID] = kept
+	}
+
+	return nil
+}
+
+func (s *Storage) tagByName(name string) (models.Tag, bool) {
+	for _, tag := range s.tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+	return models.Tag{}, false
+}
+
+// resolveTags находит метки по названию, создавая отсутствующие, и
+// возвращает их идентификаторы. Вызывается под блокировкой на запись.
+func (s *Storage) resolveTags(tags []models.Tag) []int64 {
+	colors := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if _, ok := colors[tag.Name]; !ok {
+			colors[tag.Name] = tag.Color
+		}
+	}
+
+	ids := make([]int64, 0, len(colors))
+	for _, name := range models.TagNames(tags) {
+		tag, ok := s.tagByName(name)
+		if !ok {
+			tag = models.Tag{ID: s.nextTagID, Name: name, Color: colors[name]}
+			s.tags[tag.ID] = tag
+			s.nextTagID++
+		}
+		ids = append(ids, tag.ID)
+	}
+
+	return ids
+}
+
+// hasTags повторяет фильтр postgres: в режиме all у задачи должны быть все
+// метки names, иначе хотя бы одна.
+func (s *Storage) hasTags(taskID int64, names []string, match models.TagMatch) bool {
+	found := 0
+	for _, id := range s.taskTags[taskID] {
+		for _, name := range names {
+			if s.tags[id].Name == name {
+				found++
+			}
+		}
+	}
+
+	if match == models.TagMatchAll {
+		return found == len(names)
+	}
+	return found > 0
+}
+
+func sortTags(tags []models.Tag) {
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+}
@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// delivery — доставка события и момент, до которого она закреплена за
+// попыткой.
+type delivery struct {
+	models.WebhookDelivery
+	lockedUntil time.Time
+}
+
+// CreateWebhook сохраняет подписку на события задач.
+func (s *Storage) CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook.ID = s.nextWebhookID
+	webhook.Events = slices.Clone(webhook.Events)
+	webhook.CreatedAt = time.Now()
+	s.webhooks[webhook.ID] = webhook
+	s.nextWebhookID++
+
+	return &webhook, nil
+}
+
+// ListWebhooks возвращает подписки в порядке создания без ключей подписи.
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhooks := make([]models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		webhooks = append(webhooks, publicWebhook(webhook))
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+// GetWebhook возвращает подписку без ключа подписи.
+func (s *Storage) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhook, ok := s.webhooks[int64(id)]
+	if !ok {
+		return nil, storage.ErrWebhookNotFound
+	}
+	webhook = publicWebhook(webhook)
+
+	return &webhook, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом её доставок.
+func (s *Storage) DeleteWebhook(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[int64(id)]; !ok {
+		return storage.ErrWebhookNotFound
+	}
+	delete(s.webhooks, int64(id))
+
+	for deliveryID, d := range s.deliveries {
+		if d.WebhookID == int64(id) {
+			delete(s.deliveries, deliveryID)
+		}
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает страницу журнала доставок подписки, новые
+// доставки первыми.
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID uint, filter models.DeliveryFilter) (*models.DeliveryList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.webhooks[int64(webhookID)]; !ok {
+		return nil, storage.ErrWebhookNotFound
+	}
+
+	matched := []models.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.WebhookID != int64(webhookID) || filter.Status != nil && d.Status != *filter.Status {
+			continue
+		}
+		matched = append(matched, d.WebhookDelivery)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ID > matched[j].ID
+	})
+
+	total := len(matched)
+	start := (filter.Page - 1) * filter.Limit
+	if start > total {
+		start = total
+	}
+	end := start + filter.Limit
+	if end > total {
+		end = total
+	}
+
+	return &models.DeliveryList{
+		Data:  matched[start:end],
+		Total: int64(total),
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// EnqueueEvent ставит событие в очередь доставки каждой подписке на него и
+// возвращает число поставленных доставок.
+func (s *Storage) EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error) {
+	const op = "storage.memory.EnqueueEvent"
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Подписки перебираются по порядку, чтобы ID доставок не зависели от
+	// порядка обхода map.
+	ids := make([]int64, 0, len(s.webhooks))
+	for id := range s.webhooks {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	now := time.Now()
+	enqueued := 0
+	for _, id := range ids {
+		if !slices.Contains(s.webhooks[id].Events, event.Type) {
+			continue
+		}
+		nextAttemptAt := now
+		s.deliveries[s.nextDeliveryID] = delivery{WebhookDelivery: models.WebhookDelivery{
+			ID:            s.nextDeliveryID,
+			WebhookID:     id,
+			Event:         event.Type,
+			Payload:       payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &nextAttemptAt,
+			CreatedAt:     now,
+		}}
+		s.nextDeliveryID++
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+// ClaimDeliveries закрепляет за вызывающим доставки, которым пора выполнить
+// попытку, на claim.Lease и возвращает их в порядке очереди.
+func (s *Storage) ClaimDeliveries(ctx context.Context, claim models.DeliveryClaim) ([]models.OutgoingDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []delivery
+	for _, d := range s.deliveries {
+		if d.Status != models.DeliveryPending || d.NextAttemptAt.After(claim.Now) || d.lockedUntil.After(claim.Now) {
+			continue
+		}
+		due = append(due, d)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextAttemptAt.Equal(*due[j].NextAttemptAt) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+	if len(due) > claim.Limit {
+		due = due[:claim.Limit]
+	}
+
+	claimed := make([]models.OutgoingDelivery, 0, len(due))
+	for _, d := range due {
+		d.lockedUntil = claim.Now.Add(claim.Lease)
+		s.deliveries[d.ID] = d
+
+		webhook := s.webhooks[d.WebhookID]
+		claimed = append(claimed, models.OutgoingDelivery{
+			Delivery: d.WebhookDelivery,
+			URL:      webhook.URL,
+			Secret:   webhook.Secret,
+		})
+	}
+
+	return claimed, nil
+}
+
+// RecordDeliveryAttempt записывает результат попытки доставки id. Если
+// подписку уже удалили вместе с доставкой, возвращает ErrWebhookNotFound.
+func (s *Storage) RecordDeliveryAttempt(ctx context.Context, id int64, attempt models.DeliveryAttempt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return storage.ErrWebhookNotFound
+	}
+
+	d.Attempts++
+	d.ResponseCode = attempt.ResponseCode
+	d.LastError = attempt.Error
+	d.NextAttemptAt = attempt.RetryAt
+	d.lockedUntil = time.Time{}
+	switch {
+	case attempt.Error == "":
+		d.Status = models.DeliveryDelivered
+		d.DeliveredAt = &attempt.At
+		d.NextAttemptAt = nil
+	case attempt.RetryAt == nil:
+		d.Status = models.DeliveryFailed
+	}
+	s.deliveries[id] = d
+
+	return nil
+}
+
+// publicWebhook возвращает копию подписки без ключа подписи.
+func publicWebhook(webhook models.Webhook) models.Webhook {
+	webhook.Secret = ""
+	webhook.Events = slices.Clone(webhook.Events)
+	return webhook
+}
@@ -0,0 +1,57 @@
+package migrations_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/storage/migrations"
+	"todo/internal/storage/sqlite"
+)
+
+func TestMigratorSQLite(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := migrations.New(db, migrations.SQLite)
+	require.NoError(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for _, status := range statuses {
+		require.False(t, status.Applied)
+	}
+
+	// Status только читает базу и не создаёт таблицу миграций.
+	var tables int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'`).Scan(&tables))
+	require.Zero(t, tables)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(statuses))
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	_, err = db.Exec(`SELECT COUNT(*) FROM tasks`)
+	require.NoError(t, err)
+
+	for range statuses {
+		_, err := migrator.Down(ctx)
+		require.NoError(t, err)
+	}
+
+	_, err = migrator.Down(ctx)
+	require.ErrorIs(t, err, migrations.ErrNoMigrations)
+
+	_, err = db.Exec(`SELECT COUNT(*) FROM tasks`)
+	require.Error(t, err)
+}
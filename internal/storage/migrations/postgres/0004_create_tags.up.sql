@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS tags (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL UNIQUE,
+	color VARCHAR(7) NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS task_tags (
+	task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	tag_id BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON task_tags (tag_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_next_id;
+ALTER TABLE tasks DROP COLUMN next_id;
+ALTER TABLE tasks DROP COLUMN timezone;
+ALTER TABLE tasks DROP COLUMN rrule;
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'todo';
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tasks ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE;
+UPDATE tasks SET status = 'done', completed_at = updated_at WHERE completed;
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks (status);
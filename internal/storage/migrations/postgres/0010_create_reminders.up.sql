@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reminders (
+	id BIGSERIAL PRIMARY KEY,
+	task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	remind_at TIMESTAMP WITH TIME ZONE,
+	before_seconds BIGINT,
+	fire_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	sent_at TIMESTAMP WITH TIME ZONE,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	locked_until TIMESTAMP WITH TIME ZONE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	CHECK ((remind_at IS NULL) <> (before_seconds IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_reminders_task_id ON reminders (task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders (fire_at) WHERE sent_at IS NULL;
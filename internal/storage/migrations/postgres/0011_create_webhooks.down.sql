@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_pending;
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhook_events_event;
+DROP TABLE IF EXISTS webhook_events;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS task_events (
+	id BIGSERIAL PRIMARY KEY,
+	event VARCHAR(32) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS feed_tokens (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS calendar_objects (
+	task_id BIGINT PRIMARY KEY REFERENCES tasks (id) ON DELETE CASCADE,
+	name VARCHAR(255) NOT NULL UNIQUE,
+	uid VARCHAR(255) NOT NULL
+);
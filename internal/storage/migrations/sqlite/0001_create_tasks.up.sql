@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS tasks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title VARCHAR(255) NOT NULL,
+	description TEXT,
+	due_date TEXT NOT NULL,
+	completed BOOLEAN DEFAULT FALSE,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks (due_date);
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_due_date ON tasks (completed, due_date);
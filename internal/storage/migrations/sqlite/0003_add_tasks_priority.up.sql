@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT 'none'
+	CHECK (priority IN ('none', 'low', 'medium', 'high', 'urgent'));
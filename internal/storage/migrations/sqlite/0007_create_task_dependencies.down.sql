@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_dependencies_blocker_id;
+DROP TABLE IF EXISTS task_dependencies;
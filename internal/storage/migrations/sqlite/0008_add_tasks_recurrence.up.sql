@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN rrule VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN next_id INTEGER REFERENCES tasks (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_next_id ON tasks (next_id);
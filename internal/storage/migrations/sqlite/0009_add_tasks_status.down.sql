@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_status;
+UPDATE tasks SET completed = (status = 'done');
+ALTER TABLE tasks DROP COLUMN cancelled_at;
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN status;
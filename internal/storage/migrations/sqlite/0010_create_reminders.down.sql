@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reminders_pending;
+DROP INDEX IF EXISTS idx_reminders_task_id;
+DROP TABLE IF EXISTS reminders;
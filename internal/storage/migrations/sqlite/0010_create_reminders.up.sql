@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reminders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	remind_at TEXT,
+	before_seconds INTEGER,
+	fire_at TEXT NOT NULL,
+	sent_at TEXT,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	locked_until TEXT,
+	created_at TEXT NOT NULL,
+	CHECK ((remind_at IS NULL) <> (before_seconds IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_reminders_task_id ON reminders (task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders (fire_at) WHERE sent_at IS NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS task_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event VARCHAR(32) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS feed_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(100) NOT NULL,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	created_at TEXT NOT NULL
+);
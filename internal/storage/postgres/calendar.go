@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// CreateFeedToken сохраняет токен календаря по его хешу.
+func (s *Storage) CreateFeedToken(ctx context.Context, name, hash string) (*models.FeedToken, error) {
+	const op = "storage.postgres.CreateFeedToken"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	token := models.FeedToken{Name: name, CreatedAt: time.Now()}
+
+	err := s.conn(ctx).QueryRowContext(ctx,
+		`INSERT INTO feed_tokens (name, token_hash, created_at) VALUES ($1, $2, $3) RETURNING id`,
+		token.Name, hash, token.CreatedAt,
+	).Scan(&token.ID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &token, nil
+}
+
+// ListFeedTokens возвращает токены календаря в порядке создания.
+func (s *Storage) ListFeedTokens(ctx context.Context) ([]models.FeedToken, error) {
+	const op = "storage.postgres.ListFeedTokens"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT id, name, created_at FROM feed_tokens ORDER BY id`)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	tokens := []models.FeedToken{}
+	for rows.Next() {
+		token, err := scanFeedToken(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		tokens = append(tokens, *token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return tokens, nil
+}
+
+// DeleteFeedToken отзывает токен календаря.
+func (s *Storage) DeleteFeedToken(ctx context.Context, id uint) error {
+	const op = "storage.postgres.DeleteFeedToken"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	result, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM feed_tokens WHERE id = $1`, id)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if deleted == 0 {
+		return storage.ErrFeedTokenNotFound
+	}
+
+	return nil
+}
+
+// FindFeedToken возвращает токен календаря по хешу.
+func (s *Storage) FindFeedToken(ctx context.Context, hash string) (*models.FeedToken, error) {
+	const op = "storage.postgres.FindFeedToken"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	token, err := scanFeedToken(s.conn(ctx).QueryRowContext(ctx,
+		`SELECT id, name, created_at FROM feed_tokens WHERE token_hash = $1`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrFeedTokenNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return token, nil
+}
+
+func scanFeedToken(row scanner) (*models.FeedToken, error) {
+	var token models.FeedToken
+
+	if err := row.Scan(&token.ID, &token.Name, &token.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// CalendarObjects возвращает ресурсы CalDAV, созданные клиентами.
+func (s *Storage) CalendarObjects(ctx context.Context) ([]models.CalendarObject, error) {
+	const op = "storage.postgres.CalendarObjects"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT task_id, name, uid FROM calendar_objects ORDER BY task_id`)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	objects := []models.CalendarObject{}
+	for rows.Next() {
+		var object models.CalendarObject
+		if err := rows.Scan(&object.TaskID, &object.Name, &object.UID); err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		objects = append(objects, object)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return objects, nil
+}
+
+// SaveCalendarObject сохраняет имя ресурса и UID задачи, заменяя прежние.
+func (s *Storage) SaveCalendarObject(ctx context.Context, object models.CalendarObject) error {
+	const op = "storage.postgres.SaveCalendarObject"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	exists, err := taskExists(ctx, s.conn(ctx), object.TaskID)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	if !exists {
+		return storage.ErrTaskNotFound
+	}
+
+	_, err = s.conn(ctx).ExecContext(ctx, `
+		INSERT INTO calendar_objects (task_id, name, uid) VALUES ($1, $2, $3)
+		ON CONFLICT (task_id) DO UPDATE SET name = excluded.name, uid = excluded.uid`,
+		object.TaskID, object.Name, object.UID,
+	)
+	if isUniqueViolation(err) {
+		return storage.ErrCalendarObjectExists
+	}
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	return nil
+}
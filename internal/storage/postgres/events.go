@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// taskEventsChannel — канал NOTIFY, в который AppendTaskEvent сообщает ID
+// записанного события.
+const taskEventsChannel = "task_events"
+
+// catchUpBatch — сколько событий журнала читается за раз после
+// восстановления соединения слушателя.
+const catchUpBatch = 500
+
+// AppendTaskEvent записывает событие в журнал изменений задач, оставляет в
+// журнале не больше keep последних событий и уведомляет слушателей всех
+// экземпляров приложения. Внутри InTx событие фиксируется вместе с
+// изменением задачи. Уведомление доставляется после фиксации транзакции,
+// поэтому слушатель уже видит запись.
+//
+// Запись в журнал идёт под блокировкой до конца транзакции: события
+// фиксируются в порядке ID, и слушатель, продолжающий журнал после
+// последнего полученного ID, не пропустит событие с меньшим ID,
+// зафиксированное позже.
+func (s *Storage) AppendTaskEvent(ctx context.Context, event models.WebhookEvent, keep int) (*models.TaskEvent, error) {
+	const op = "storage.postgres.AppendTaskEvent"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, taskEventsChannel); err != nil {
+		return nil, wrap(ctx, op+": lock", err)
+	}
+
+	stored := models.TaskEvent{WebhookEvent: event}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO task_events (event, payload, created_at) VALUES ($1, $2, $3) RETURNING id`,
+		event.Type, string(payload), time.Now(),
+	).Scan(&stored.ID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_events WHERE id <= $1`, stored.ID-int64(keep)); err != nil {
+		return nil, wrap(ctx, op+": trim", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, taskEventsChannel, strconv.FormatInt(stored.ID, 10))
+	if err != nil {
+		return nil, wrap(ctx, op+": notify", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &stored, nil
+}
+
+// ListTaskEvents возвращает до limit событий журнала с ID больше afterID.
+// Если события сразу после afterID уже вытеснены из журнала, возвращает
+// ErrEventsExpired.
+func (s *Storage) ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error) {
+	const op = "storage.postgres.ListTaskEvents"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var oldest sql.NullInt64
+	if err := s.conn(ctx).QueryRowContext(ctx, `SELECT MIN(id) FROM task_events`).Scan(&oldest); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	if oldest.Valid && afterID < oldest.Int64-1 {
+		return nil, storage.ErrEventsExpired
+	}
+
+	rows, err := s.conn(ctx).QueryContext(ctx,
+		`SELECT id, payload FROM task_events WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	events := []models.TaskEvent{}
+	for rows.Next() {
+		event, err := scanTaskEvent(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return events, nil
+}
+
+// ListenTaskEvents передаёт handle события, которые записывают в журнал все
+// экземпляры приложения, пока ctx не отменён. После восстановления
+// соединения пропущенные уведомления заменяются чтением журнала.
+func (s *Storage) ListenTaskEvents(ctx context.Context, handle func(models.TaskEvent)) error {
+	const op = "storage.postgres.ListenTaskEvents"
+
+	var lastID int64
+	if err := s.conn(ctx).QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM task_events`).Scan(&lastID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	listener := pq.NewListener(s.connStr, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	if err := listener.Listen(taskEventsChannel); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Ping обнаруживает разорванное соединение, пока уведомлений нет.
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			go listener.Ping()
+		case n := <-listener.Notify:
+			if n == nil {
+				var err error
+				if lastID, err = s.catchUpTaskEvents(ctx, lastID, handle); err != nil {
+					return fmt.Errorf("%s: %w", op, err)
+				}
+				continue
+			}
+
+			id, err := strconv.ParseInt(n.Extra, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			event, err := s.getTaskEvent(ctx, id)
+			if errors.Is(err, sql.ErrNoRows) {
+				// Событие уже вытеснено из журнала.
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			handle(*event)
+			lastID = max(lastID, id)
+		}
+	}
+}
+
+// catchUpTaskEvents передаёт handle события журнала после lastID и
+// возвращает ID последнего из них. Если журнал уже вытеснил часть событий,
+// чтение продолжается с самого старого сохранённого.
+func (s *Storage) catchUpTaskEvents(ctx context.Context, lastID int64, handle func(models.TaskEvent)) (int64, error) {
+	for {
+		events, err := s.ListTaskEvents(ctx, lastID, catchUpBatch)
+		if errors.Is(err, storage.ErrEventsExpired) {
+			err = s.conn(ctx).QueryRowContext(ctx, `SELECT COALESCE(MIN(id), 1) - 1 FROM task_events`).Scan(&lastID)
+			if err != nil {
+				return lastID, err
+			}
+			continue
+		}
+		if err != nil {
+			return lastID, err
+		}
+
+		for _, event := range events {
+			handle(event)
+			lastID = event.ID
+		}
+		if len(events) < catchUpBatch {
+			return lastID, nil
+		}
+	}
+}
+
+// getTaskEvent возвращает событие журнала по ID.
+func (s *Storage) getTaskEvent(ctx context.Context, id int64) (*models.TaskEvent, error) {
+	const op = "storage.postgres.getTaskEvent"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	event, err := scanTaskEvent(s.conn(ctx).QueryRowContext(ctx, `SELECT id, payload FROM task_events WHERE id = $1`, id))
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return event, nil
+}
+
+// scanTaskEvent читает ID и тело события журнала.
+func scanTaskEvent(row scanner) (*models.TaskEvent, error) {
+	var (
+		event   models.TaskEvent
+		payload string
+	)
+
+	if err := row.Scan(&event.ID, &payload); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(payload), &event.WebhookEvent); err != nil {
+		return nil, fmt.Errorf("decode task event %d: %w", event.ID, err)
+	}
+
+	return &event, nil
+}
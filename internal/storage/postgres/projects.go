@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// projectColumns — список колонок проекта в порядке, который ожидает scanProject.
+const projectColumns = "id, name, description, archived, position, created_at, updated_at"
+
+func (s *Storage) CreateProject(ctx context.Context, project models.Project) (*models.Project, error) {
+	const op = "storage.postgres.CreateProject"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		INSERT INTO projects (name, description, archived, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	now := time.Now()
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	err := s.conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		project.Name,
+		project.Description,
+		project.Archived,
+		project.Position,
+		project.CreatedAt,
+		project.UpdatedAt,
+	).Scan(&project.ID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &project, nil
+}
+
+func (s *Storage) GetProject(ctx context.Context, id uint) (*models.Project, error) {
+	const op = "storage.postgres.GetProject"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
+
+	project, err := scanProject(s.conn(ctx).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return project, nil
+}
+
+// ListProjects возвращает проекты в порядке position, а при равенстве —
+// в порядке создания. Ненулевой archived оставляет только архивные или
+// только активные проекты.
+func (s *Storage) ListProjects(ctx context.Context, archived *bool) ([]models.Project, error) {
+	const op = "storage.postgres.ListProjects"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `SELECT ` + projectColumns + ` FROM projects`
+	var args []any
+
+	if archived != nil {
+		query += ` WHERE archived = $1`
+		args = append(args, *archived)
+	}
+	query += ` ORDER BY position, id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		projects = append(projects, *project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return projects, nil
+}
+
+func (s *Storage) UpdateProject(ctx context.Context, id uint, patch models.ProjectPatch) (*models.Project, error) {
+	const op = "storage.postgres.UpdateProject"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		UPDATE projects
+		SET name = COALESCE($1, name),
+			description = COALESCE($2, description),
+			archived = COALESCE($3, archived),
+			position = COALESCE($4, position),
+			updated_at = $5
+		WHERE id = $6
+		RETURNING ` + projectColumns
+
+	project, err := scanProject(s.conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		patch.Name,
+		patch.Description,
+		patch.Archived,
+		patch.Position,
+		time.Now(),
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return project, nil
+}
+
+// DeleteProject удаляет проект, поступая с его задачами согласно policy,
+// и возвращает затронутые задачи: перенесённые во входящие — в новом
+// состоянии, удалённые вместе с подзадачами — в состоянии до удаления.
+func (s *Storage) DeleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error) {
+	const op = "storage.postgres.DeleteProject"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	var affected []models.Task
+	switch policy {
+	case models.ProjectDeleteInbox:
+		query := `
+			UPDATE tasks SET project_id = NULL, updated_at = $1, version = version + 1
+			WHERE project_id = $2
+			RETURNING ` + taskColumns
+		affected, err = queryTasks(ctx, tx, query, time.Now(), id)
+		if err != nil {
+			return nil, wrap(ctx, op+": move tasks to inbox", err)
+		}
+	case models.ProjectDeleteCascade:
+		// Подзадачи удаляются каскадом по parent_id, поэтому их нужно
+		// прочитать до удаления вместе с задачами проекта.
+		query := `
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM tasks WHERE project_id = $1
+				UNION
+				SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			)
+			SELECT ` + taskColumns + ` FROM tasks
+			WHERE id IN (SELECT id FROM subtree)`
+		affected, err = queryTasks(ctx, tx, query, id)
+		if err != nil {
+			return nil, wrap(ctx, op+": read tasks", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE project_id = $1`, id); err != nil {
+			return nil, wrap(ctx, op+": delete tasks", err)
+		}
+	default:
+		var hasTasks bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE project_id = $1)`, id).Scan(&hasTasks)
+		if err != nil {
+			return nil, wrap(ctx, op+": check project tasks", err)
+		}
+		if hasTasks {
+			return nil, storage.ErrProjectNotEmpty
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrap(ctx, op+": get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, storage.ErrProjectNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return affected, nil
+}
+
+// queryTasks читает задачи запросом query вместе с метками, упорядочивая
+// их по сроку выполнения и id.
+func queryTasks(ctx context.Context, q querier, query string, args ...any) ([]models.Task, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].DueDate.Equal(tasks[j].DueDate) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].DueDate.Before(tasks[j].DueDate)
+	})
+
+	if err := attachTags(ctx, q, tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func scanProject(row scanner) (*models.Project, error) {
+	project := &models.Project{}
+
+	err := row.Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.Archived,
+		&project.Position,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// nullableID возвращает значение ссылки на проект или родительскую задачу:
+// идентификатор 0 означает, что ссылки нет.
+func nullableID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// isForeignKeyViolation сообщает, что запрос сослался на несуществующую строку.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
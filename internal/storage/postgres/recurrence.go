@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"todo/internal/models"
+)
+
+// insertTask добавляет задачу вместе с метками и заполняет её ID и Version.
+func insertTask(ctx context.Context, q querier, task *models.Task) error {
+	query := `
+		INSERT INTO tasks (title, description, due_date, status, completed, priority, project_id, parent_id,
+			rrule, timezone, created_at, updated_at, completed_at, cancelled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, version`
+
+	task.Status = task.Status.OrTodo()
+	task.CompletedAt, task.CancelledAt = nil, nil
+	closedAt := task.UpdatedAt
+	switch task.Status {
+	case models.StatusDone:
+		task.CompletedAt = &closedAt
+	case models.StatusCancelled:
+		task.CancelledAt = &closedAt
+	}
+
+	err := q.QueryRowContext(
+		ctx,
+		query,
+		task.Title,
+		task.Description,
+		task.DueDate,
+		task.Status,
+		task.Status.Closed(),
+		task.Priority,
+		task.ProjectID,
+		task.ParentID,
+		task.RRule,
+		task.Timezone,
+		task.CreatedAt,
+		task.UpdatedAt,
+		task.CompletedAt,
+		task.CancelledAt,
+	).Scan(&task.ID, &task.Version)
+	if err != nil {
+		return err
+	}
+
+	task.Tags, err = setTaskTags(ctx, q, task.ID, task.Tags)
+	return err
+}
+
+// spawnNext создаёт следующее повторение закрытой повторяющейся задачи.
+// Правило и напоминания относительно срока переходят к новой задаче: у
+// закрытой правило очищается, а next_id указывает на новую. Если серия
+// закончилась, правило просто очищается.
+// Версия закрытой задачи второй раз не увеличивается.
+func spawnNext(ctx context.Context, q querier, task *models.Task) error {
+	if !task.Status.Closed() || task.RRule == "" {
+		return nil
+	}
+
+	next, ok, err := task.NextOccurrence()
+	if err != nil {
+		return fmt.Errorf("next occurrence: %w", err)
+	}
+
+	if ok {
+		now := time.Now()
+		next.CreatedAt = now
+		next.UpdatedAt = now
+		if err := insertTask(ctx, q, &next); err != nil {
+			return fmt.Errorf("insert next occurrence: %w", err)
+		}
+		if err := copyReminders(ctx, q, task.ID, next.ID, next.DueDate); err != nil {
+			return fmt.Errorf("copy reminders: %w", err)
+		}
+		task.NextID = &next.ID
+	}
+
+	_, err = q.ExecContext(ctx, `UPDATE tasks SET rrule = '', next_id = $1 WHERE id = $2`, task.NextID, task.ID)
+	if err != nil {
+		return fmt.Errorf("end recurrence: %w", err)
+	}
+	task.RRule = ""
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// reminderColumns — список колонок напоминания в порядке, который ожидает
+// scanReminder.
+const reminderColumns = "id, task_id, remind_at, before_seconds, fire_at, sent_at, attempts, last_error, created_at"
+
+// CreateReminder добавляет напоминание к задаче reminder.TaskID и
+// вычисляет момент его срабатывания по сроку задачи.
+func (s *Storage) CreateReminder(ctx context.Context, reminder models.Reminder) (*models.Reminder, error) {
+	const op = "storage.postgres.CreateReminder"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var due time.Time
+	err := s.conn(ctx).QueryRowContext(ctx, `SELECT due_date FROM tasks WHERE id = $1`, reminder.TaskID).Scan(&due)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	reminder.FireAt = reminder.FireTime(due)
+	reminder.CreatedAt = time.Now()
+	reminder.SentAt = nil
+	reminder.Attempts = 0
+	reminder.LastError = ""
+
+	query := `
+		INSERT INTO reminders (task_id, remind_at, before_seconds, fire_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	err = s.conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		reminder.TaskID,
+		reminder.RemindAt,
+		beforeSeconds(reminder.Before),
+		reminder.FireAt,
+		reminder.CreatedAt,
+	).Scan(&reminder.ID)
+	if isForeignKeyViolation(err) {
+		return nil, storage.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &reminder, nil
+}
+
+// ListReminders возвращает напоминания задачи в порядке срабатывания.
+func (s *Storage) ListReminders(ctx context.Context, taskID uint) ([]models.Reminder, error) {
+	const op = "storage.postgres.ListReminders"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	exists, err := taskExists(ctx, s.conn(ctx), int64(taskID))
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	if !exists {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = $1 ORDER BY fire_at, id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	reminders, err := scanReminders(rows)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return reminders, nil
+}
+
+// DeleteReminder удаляет напоминание id задачи taskID.
+func (s *Storage) DeleteReminder(ctx context.Context, taskID, id uint) error {
+	const op = "storage.postgres.DeleteReminder"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	result, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM reminders WHERE id = $1 AND task_id = $2`, id, taskID)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if deleted == 0 {
+		return storage.ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// ClaimReminders закрепляет за вызывающим сработавшие и ещё не доставленные
+// напоминания открытых задач на claim.Lease и возвращает их в порядке
+// срабатывания. FOR UPDATE SKIP LOCKED пропускает напоминания, которые в
+// этот момент выбирает другой экземпляр, поэтому одно напоминание не
+// достанется двоим.
+func (s *Storage) ClaimReminders(ctx context.Context, claim models.ReminderClaim) ([]models.Reminder, error) {
+	const op = "storage.postgres.ClaimReminders"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		WITH due AS (
+			SELECT r.id AS due_id FROM reminders r JOIN tasks t ON t.id = r.task_id
+			WHERE r.sent_at IS NULL AND r.fire_at <= $2 AND r.attempts < $3
+				AND (r.locked_until IS NULL OR r.locked_until <= $2) AND NOT t.completed
+			ORDER BY r.fire_at, r.id
+			LIMIT $4
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		UPDATE reminders SET locked_until = $1
+		FROM due WHERE reminders.id = due.due_id
+		RETURNING ` + reminderColumns
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, claim.Now.Add(claim.Lease), claim.Now, claim.MaxAttempts, claim.Limit)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	reminders, err := scanReminders(rows)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.Slice(reminders, func(i, j int) bool {
+		if reminders[i].FireAt.Equal(reminders[j].FireAt) {
+			return reminders[i].ID < reminders[j].ID
+		}
+		return reminders[i].FireAt.Before(reminders[j].FireAt)
+	})
+
+	return reminders, nil
+}
+
+// MarkReminderSent отмечает напоминание доставленным в момент at.
+func (s *Storage) MarkReminderSent(ctx context.Context, id int64, at time.Time) error {
+	const op = "storage.postgres.MarkReminderSent"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `UPDATE reminders SET sent_at = $1, locked_until = NULL WHERE id = $2`
+
+	return s.finishReminder(ctx, op, query, at, id)
+}
+
+// MarkReminderFailed записывает неудачную попытку доставки: увеличивает
+// число попыток, сохраняет причину и откладывает следующую попытку до
+// retryAt.
+func (s *Storage) MarkReminderFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	const op = "storage.postgres.MarkReminderFailed"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `UPDATE reminders SET attempts = attempts + 1, last_error = $1, locked_until = $2 WHERE id = $3`
+
+	return s.finishReminder(ctx, op, query, reason, retryAt, id)
+}
+
+// finishReminder выполняет изменение одного напоминания и возвращает
+// ErrReminderNotFound, если его уже удалили.
+func (s *Storage) finishReminder(ctx context.Context, op, query string, args ...any) error {
+	result, err := s.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if updated == 0 {
+		return storage.ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// rescheduleReminders переносит напоминания задачи, заданные относительно
+// срока, на новый срок due. Перенесённое напоминание снова ждёт доставки,
+// даже если по старому сроку уже сработало.
+func rescheduleReminders(ctx context.Context, q querier, taskID int64, due time.Time) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE reminders
+		SET fire_at = $2::timestamptz - before_seconds * INTERVAL '1 second',
+			sent_at = NULL, attempts = 0, last_error = '', locked_until = NULL
+		WHERE task_id = $1 AND before_seconds IS NOT NULL
+			AND fire_at <> $2::timestamptz - before_seconds * INTERVAL '1 second'`,
+		taskID, due,
+	)
+	if err != nil {
+		return fmt.Errorf("reschedule reminders: %w", err)
+	}
+
+	return nil
+}
+
+// copyReminders добавляет задаче to напоминания задачи from, заданные
+// относительно срока, с моментом срабатывания по сроку due.
+func copyReminders(ctx context.Context, q querier, from, to int64, due time.Time) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO reminders (task_id, before_seconds, fire_at, created_at)
+		SELECT $1, before_seconds, $3::timestamptz - before_seconds * INTERVAL '1 second', $4
+		FROM reminders WHERE task_id = $2 AND before_seconds IS NOT NULL`,
+		to, from, due, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("copy reminders: %w", err)
+	}
+
+	return nil
+}
+
+// beforeSeconds возвращает смещение напоминания в секундах или nil для
+// напоминания на конкретный момент.
+func beforeSeconds(before *models.Duration) any {
+	if before == nil {
+		return nil
+	}
+	return int64(time.Duration(*before) / time.Second)
+}
+
+func scanReminders(rows *sql.Rows) ([]models.Reminder, error) {
+	reminders := []models.Reminder{}
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+func scanReminder(row scanner) (*models.Reminder, error) {
+	var (
+		reminder models.Reminder
+		before   sql.NullInt64
+	)
+
+	err := row.Scan(
+		&reminder.ID,
+		&reminder.TaskID,
+		&reminder.RemindAt,
+		&before,
+		&reminder.FireAt,
+		&reminder.SentAt,
+		&reminder.Attempts,
+		&reminder.LastError,
+		&reminder.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if before.Valid {
+		duration := models.Duration(time.Duration(before.Int64) * time.Second)
+		reminder.Before = &duration
+	}
+
+	return &reminder, nil
+}
@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Subtree возвращает задачу id и все её подзадачи на любой глубине плоским
+// списком, упорядоченным по сроку выполнения.
+func (s *Storage) Subtree(ctx context.Context, id uint) ([]models.Task, error) {
+	const op = "storage.postgres.Subtree"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id
+		)
+		SELECT ` + taskColumns + ` FROM tasks
+		WHERE id IN (SELECT id FROM subtree)
+		ORDER BY due_date ASC, id ASC`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if len(tasks) == 0 {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	if err := attachTags(ctx, s.conn(ctx), tasks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return tasks, nil
+}
+
+// Progress считает подзадачи задачи id и долю выполненных среди них.
+func (s *Storage) Progress(ctx context.Context, id uint) (*models.TaskProgress, error) {
+	const op = "storage.postgres.Progress"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, parent_id, completed FROM tasks WHERE id = $1
+			UNION
+			SELECT t.id, t.parent_id, t.completed FROM tasks t JOIN subtree s ON t.parent_id = s.id
+		)
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE id <> $1 AND parent_id = $1),
+			COUNT(*) FILTER (WHERE id <> $1 AND completed),
+			COUNT(*) FILTER (WHERE id = $1 AND completed)
+		FROM subtree`
+
+	var total, children, completed, rootDone int
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, id).Scan(&total, &children, &completed, &rootDone)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if total == 0 {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	progress := models.NewTaskProgress(children, total-1, completed, rootDone > 0)
+
+	return &progress, nil
+}
+
+// completeSubtasks отмечает выполненными открытые подзадачи задачи id на
+// любой глубине и возвращает их идентификаторы. Для повторяющихся подзадач
+// создаётся следующее повторение. UpdateTask и PatchTask вызывают её в
+// своей транзакции после записи самой задачи.
+func completeSubtasks(ctx context.Context, q querier, id int64, now time.Time) ([]int64, error) {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id FROM tasks WHERE parent_id = $1
+			UNION
+			SELECT t.id FROM tasks t JOIN descendants d ON t.parent_id = d.id
+		)
+		UPDATE tasks SET status = 'done', completed = TRUE, completed_at = $2, updated_at = $2, version = version + 1
+		WHERE NOT completed AND id IN (SELECT id FROM descendants)
+		RETURNING ` + taskColumns
+
+	tasks, err := queryTasks(ctx, q, query, id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := make([]int64, 0, len(tasks))
+	for i := range tasks {
+		if err := spawnNext(ctx, q, &tasks[i]); err != nil {
+			return nil, err
+		}
+		completed = append(completed, tasks[i].ID)
+	}
+
+	return completed, nil
+}
+
+// checkParent проверяет, что задачу taskID можно вложить в parentID:
+// родитель существует и не является самой задачей или её подзадачей.
+// Для новой задачи taskID равен 0.
+//
+// Задача и цепочка предков parentID блокируются до конца транзакции, иначе
+// две транзакции, вкладывающие задачи друг в друга, обе не увидят цикла.
+// Если цепочка изменилась, пока транзакция ждала блокировки, она читается
+// и блокируется заново.
+func checkParent(ctx context.Context, q querier, op string, taskID, parentID int64) error {
+	for {
+		parents, err := lockAncestors(ctx, q, taskID, parentID)
+		if err != nil {
+			return wrap(ctx, op+": check parent", err)
+		}
+
+		if _, ok := parents[parentID]; !ok {
+			return storage.ErrParentNotFound
+		}
+
+		stable := true
+		visited := make(map[int64]bool)
+		for id := parentID; !visited[id]; {
+			if id == taskID {
+				return storage.ErrTaskCycle
+			}
+			visited[id] = true
+
+			parent, ok := parents[id]
+			if !ok {
+				stable = false
+				break
+			}
+			if !parent.Valid {
+				break
+			}
+			id = parent.Int64
+		}
+
+		if stable {
+			return nil
+		}
+	}
+}
+
+// lockAncestors блокирует задачу taskID и цепочку предков parentID и
+// возвращает parent_id заблокированных задач после снятия чужих блокировок.
+func lockAncestors(ctx context.Context, q querier, taskID, parentID int64) (map[int64]sql.NullInt64, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM tasks WHERE id = $1
+			UNION
+			SELECT t.id, t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.parent_id
+		)
+		SELECT id, parent_id FROM tasks
+		WHERE id IN (SELECT id FROM ancestors) OR id = $2
+		ORDER BY id
+		FOR UPDATE`
+
+	rows, err := q.QueryContext(ctx, query, parentID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parents := make(map[int64]sql.NullInt64)
+	for rows.Next() {
+		var (
+			id     int64
+			parent sql.NullInt64
+		)
+		if err := rows.Scan(&id, &parent); err != nil {
+			return nil, err
+		}
+		parents[id] = parent
+	}
+
+	return parents, rows.Err()
+}
+
+// checkCompletion не даёт выполнить открытую задачу id, пока у неё есть
+// невыполненные подзадачи.
+func checkCompletion(ctx context.Context, q querier, op string, id int64) error {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id, completed FROM tasks WHERE parent_id = $1
+			UNION
+			SELECT t.id, t.completed FROM tasks t JOIN descendants d ON t.parent_id = d.id
+		)
+		SELECT EXISTS (SELECT 1 FROM descendants WHERE NOT completed)
+			AND EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND NOT completed)`
+
+	var blocked bool
+
+	if err := q.QueryRowContext(ctx, query, id).Scan(&blocked); err != nil {
+		return wrap(ctx, op+": check subtasks", err)
+	}
+
+	if blocked {
+		return storage.ErrOpenSubtasks
+	}
+
+	return nil
+}
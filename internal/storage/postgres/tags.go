@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// querier — общие методы *sql.DB и *sql.Tx, чтобы вспомогательные запросы
+// можно было выполнять как отдельно, так и внутри транзакции.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *Storage) CreateTag(ctx context.Context, tag models.Tag) (*models.Tag, error) {
+	const op = "storage.postgres.CreateTag"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `INSERT INTO tags (name, color) VALUES ($1, $2) RETURNING id`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, tag.Name, tag.Color).Scan(&tag.ID)
+	if isUniqueViolation(err) {
+		return nil, storage.ErrTagExists
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &tag, nil
+}
+
+func (s *Storage) GetTag(ctx context.Context, id uint) (*models.Tag, error) {
+	const op = "storage.postgres.GetTag"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var tag models.Tag
+
+	err := s.conn(ctx).QueryRowContext(ctx, `SELECT id, name, color FROM tags WHERE id = $1`, id).
+		Scan(&tag.ID, &tag.Name, &tag.Color)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &tag, nil
+}
+
+// ListTags возвращает все метки, упорядоченные по названию.
+func (s *Storage) ListTags(ctx context.Context) ([]models.Tag, error) {
+	const op = "storage.postgres.ListTags"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT id, name, color FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color); err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return tags, nil
+}
+
+// UpdateTag переименовывает метку или меняет её цвет. Задачи ссылаются на
+// метку по идентификатору, поэтому изменение сразу видно во всех задачах.
+func (s *Storage) UpdateTag(ctx context.Context, id uint, patch models.TagPatch) (*models.Tag, error) {
+	const op = "storage.postgres.UpdateTag"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		UPDATE tags
+		SET name = COALESCE($1, name), color = COALESCE($2, color)
+		WHERE id = $3
+		RETURNING id, name, color`
+
+	var tag models.Tag
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, patch.Name, patch.Color, id).Scan(&tag.ID, &tag.Name, &tag.Color)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrTagNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, storage.ErrTagExists
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &tag, nil
+}
+
+// DeleteTag удаляет метку; её связи с задачами удаляются каскадно.
+func (s *Storage) DeleteTag(ctx context.Context, id uint) error {
+	const op = "storage.postgres.DeleteTag"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	result, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return storage.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// setTaskTags заменяет метки задачи. Метки ищутся по названию, отсутствующие
+// создаются с переданным цветом. Возвращает итоговые метки, упорядоченные
+// по названию.
+func setTaskTags(ctx context.Context, q querier, taskID int64, tags []models.Tag) ([]models.Tag, error) {
+	if _, err := q.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = $1`, taskID); err != nil {
+		return nil, fmt.Errorf("delete task tags: %w", err)
+	}
+
+	colors := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if _, ok := colors[tag.Name]; !ok {
+			colors[tag.Name] = tag.Color
+		}
+	}
+
+	// DO UPDATE нужен, чтобы RETURNING вернул строку и для существующей метки.
+	upsert := `
+		INSERT INTO tags (name, color) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id, name, color`
+
+	result := make([]models.Tag, 0, len(colors))
+	for _, name := range models.TagNames(tags) {
+		var tag models.Tag
+		err := q.QueryRowContext(ctx, upsert, name, colors[name]).Scan(&tag.ID, &tag.Name, &tag.Color)
+		if err != nil {
+			return nil, fmt.Errorf("upsert tag %q: %w", name, err)
+		}
+
+		_, err = q.ExecContext(ctx, `INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2)`, taskID, tag.ID)
+		if err != nil {
+			return nil, fmt.Errorf("link tag %q: %w", name, err)
+		}
+
+		result = append(result, tag)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// attachTags загружает метки задач одним запросом. Задачи без меток
+// получают пустой список, чтобы в JSON было [], а не null.
+func attachTags(ctx context.Context, q querier, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(tasks))
+	placeholders := make([]string, len(tasks))
+	args := make([]any, len(tasks))
+	for i := range tasks {
+		tasks[i].Tags = []models.Tag{}
+		index[tasks[i].ID] = i
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = tasks[i].ID
+	}
+
+	query := `
+		SELECT tt.task_id, t.id, t.name, t.color
+		FROM task_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.task_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY t.name`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("load task tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var tag models.Tag
+		if err := rows.Scan(&taskID, &tag.ID, &tag.Name, &tag.Color); err != nil {
+			return fmt.Errorf("load task tags: %w", err)
+		}
+		i := index[taskID]
+		tasks[i].Tags = append(tasks[i].Tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load task tags: %w", err)
+	}
+
+	return nil
+}
+
+// tagCondition возвращает условие WHERE для фильтра по меткам, начиная
+// нумерацию параметров с argPosition, и аргументы к нему.
+func tagCondition(names []string, match models.TagMatch, argPosition int) (string, []any) {
+	placeholders := make([]string, len(names))
+	args := make([]any, 0, len(names)+1)
+	for i, name := range names {
+		placeholders[i] = fmt.Sprintf("$%d", argPosition)
+		args = append(args, name)
+		argPosition++
+	}
+
+	condition := ` AND id IN (
+		SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE t.name IN (` + strings.Join(placeholders, ", ") + `)`
+
+	if match == models.TagMatchAll {
+		condition += fmt.Sprintf(" GROUP BY tt.task_id HAVING COUNT(*) = $%d", argPosition)
+		args = append(args, len(names))
+	}
+
+	return condition + ")", args
+}
+
+// isUniqueViolation сообщает, что запрос нарушил ограничение уникальности.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
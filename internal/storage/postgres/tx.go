@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// txKey — ключ контекста, в котором InTx передаёт открытую транзакцию.
+type txKey struct{}
+
+// InTx выполняет fn в одной транзакции: методы хранилища, вызванные с
+// контекстом fn, работают внутри неё, так что изменение задачи и записанные
+// после него события фиксируются вместе или не фиксируются вовсе.
+// Транзакция фиксируется, если fn не вернула ошибку. Вложенный вызов
+// продолжает внешнюю транзакцию.
+func (s *Storage) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "storage.postgres.InTx"
+
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// conn возвращает транзакцию InTx из контекста или пул соединений.
+func (s *Storage) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return s.db
+}
+
+// txn — транзакция одного метода хранилища. Внутри транзакции InTx она
+// становится точкой сохранения: откат метода отменяет только его
+// изменения, а фиксирует их внешняя транзакция.
+type txn struct {
+	*sql.Tx
+	ctx       context.Context
+	savepoint bool
+	done      bool
+}
+
+// begin открывает транзакцию метода или точку сохранения в транзакции InTx.
+func (s *Storage) begin(ctx context.Context) (*txn, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT method`); err != nil {
+			return nil, err
+		}
+		return &txn{Tx: tx, ctx: ctx, savepoint: true}, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn{Tx: tx, ctx: ctx}, nil
+}
+
+// Commit фиксирует транзакцию метода или освобождает точку сохранения.
+func (t *txn) Commit() error {
+	if !t.savepoint {
+		return t.Tx.Commit()
+	}
+
+	t.done = true
+	_, err := t.ExecContext(t.ctx, `RELEASE SAVEPOINT method`)
+	return err
+}
+
+// Rollback откатывает транзакцию метода или изменения после точки
+// сохранения. После Commit ничего не делает. Откат выполняется и после
+// отмены контекста метода, иначе внешняя транзакция останется прерванной.
+func (t *txn) Rollback() error {
+	if !t.savepoint {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return nil
+	}
+
+	t.done = true
+	_, err := t.ExecContext(context.WithoutCancel(t.ctx), `ROLLBACK TO SAVEPOINT method`)
+	return err
+}
@@ -0,0 +1,286 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// Blockers возвращает задачи, которые блокируют задачу id, по сроку
+// выполнения.
+func (s *Storage) Blockers(ctx context.Context, id uint) ([]models.Task, error) {
+	const op = "storage.sqlite.Blockers"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	exists, err := taskExists(ctx, s.db, int64(id))
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	if !exists {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	query := `
+		SELECT ` + taskColumns + ` FROM tasks
+		WHERE id IN (SELECT blocker_id FROM task_dependencies WHERE task_id = $1)
+		ORDER BY due_date ASC, id ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if err := attachTags(ctx, s.db, tasks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return tasks, nil
+}
+
+// AddBlocker делает задачу blockerID блокирующей для задачи id и
+// увеличивает версию задачи. Повторное добавление ничего не меняет.
+// Ненулевой version включает проверку версии.
+func (s *Storage) AddBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	const op = "storage.sqlite.AddBlocker"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if err := checkBlocker(ctx, tx, op, int64(id), int64(blockerID)); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO task_dependencies (task_id, blocker_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`,
+		id, blockerID,
+	)
+	if isForeignKeyViolation(err) {
+		return nil, storage.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrap(ctx, op+": get rows affected", err)
+	}
+
+	task, err := touchTask(ctx, tx, op, int64(id), version, added > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return task, nil
+}
+
+// RemoveBlocker убирает зависимость задачи id от blockerID и увеличивает
+// версию задачи. Ненулевой version включает проверку версии.
+func (s *Storage) RemoveBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	const op = "storage.sqlite.RemoveBlocker"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2`,
+		id, blockerID,
+	)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrap(ctx, op+": get rows affected", err)
+	}
+
+	if removed == 0 {
+		exists, err := taskExists(ctx, tx, int64(id))
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		if !exists {
+			return nil, storage.ErrTaskNotFound
+		}
+		return nil, storage.ErrDependencyNotFound
+	}
+
+	task, err := touchTask(ctx, tx, op, int64(id), version, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return task, nil
+}
+
+// NextTasks возвращает до limit невыполненных задач в порядке, в котором
+// их можно делать: каждая задача идёт после своих блокирующих, а среди
+// доступных первой идёт задача с ближайшим сроком.
+func (s *Storage) NextTasks(ctx context.Context, limit int) ([]models.Task, error) {
+	const op = "storage.sqlite.NextTasks"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	// Порядок зависит от всего графа, поэтому загружаются все открытые
+	// задачи, а метки — только для попавших в ответ.
+	rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE NOT completed`)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	deps, err := openDependencies(ctx, s.db)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	tasks = models.OrderByDependencies(tasks, deps)
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	if err := attachTags(ctx, s.db, tasks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return tasks, nil
+}
+
+// openDependencies возвращает зависимости между невыполненными задачами.
+func openDependencies(ctx context.Context, q querier) ([]models.TaskDependency, error) {
+	query := `
+		SELECT d.task_id, d.blocker_id
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id
+		JOIN tasks b ON b.id = d.blocker_id
+		WHERE NOT t.completed AND NOT b.completed`
+
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deps []models.TaskDependency
+	for rows.Next() {
+		var dep models.TaskDependency
+		if err := rows.Scan(&dep.TaskID, &dep.BlockerID); err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps, rows.Err()
+}
+
+// checkBlocker проверяет, что задачу taskID можно сделать зависимой от
+// blockerID: блокирующая задача существует и сама не ждёт taskID, прямо
+// или через другие задачи.
+func checkBlocker(ctx context.Context, q querier, op string, taskID, blockerID int64) error {
+	query := `
+		WITH RECURSIVE blockers AS (
+			SELECT id FROM tasks WHERE id = $1
+			UNION
+			SELECT d.blocker_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.id
+		)
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE id = $2) FROM blockers`
+
+	var blockers, cycles int
+
+	if err := q.QueryRowContext(ctx, query, blockerID, taskID).Scan(&blockers, &cycles); err != nil {
+		return wrap(ctx, op+": check blocker", err)
+	}
+
+	if blockers == 0 {
+		return storage.ErrBlockerNotFound
+	}
+	if cycles > 0 {
+		return storage.ErrDependencyCycle
+	}
+
+	return nil
+}
+
+// touchTask возвращает задачу id после изменения её зависимостей. Если
+// changed, версия задачи увеличивается. Ненулевой version включает проверку
+// версии.
+func touchTask(ctx context.Context, q querier, op string, id, version int64, changed bool) (*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND ($2 = 0 OR version = $2)`
+	args := []any{id, version}
+	if changed {
+		query = `
+			UPDATE tasks SET updated_at = $3, version = version + 1
+			WHERE id = $1 AND ($2 = 0 OR version = $2)
+			RETURNING ` + taskColumns
+		args = append(args, formatTime(time.Now()))
+	}
+
+	task, err := scanTask(q.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingTaskError(ctx, q, op, id)
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	tasks := []models.Task{*task}
+	if err := attachTags(ctx, q, tasks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &tasks[0], nil
+}
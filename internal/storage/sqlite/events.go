@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// AppendTaskEvent записывает событие в журнал изменений задач и оставляет
+// в журнале не больше keep последних событий.
+func (s *Storage) AppendTaskEvent(ctx context.Context, event models.WebhookEvent, keep int) (*models.TaskEvent, error) {
+	const op = "storage.sqlite.AppendTaskEvent"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	stored := models.TaskEvent{WebhookEvent: event}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO task_events (event, payload, created_at) VALUES ($1, $2, $3) RETURNING id`,
+		event.Type, string(payload), formatTime(time.Now()),
+	).Scan(&stored.ID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_events WHERE id <= $1`, stored.ID-int64(keep)); err != nil {
+		return nil, wrap(ctx, op+": trim", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &stored, nil
+}
+
+// ListTaskEvents возвращает до limit событий журнала с ID больше afterID.
+// Если события сразу после afterID уже вытеснены из журнала, возвращает
+// ErrEventsExpired.
+func (s *Storage) ListTaskEvents(ctx context.Context, afterID int64, limit int) ([]models.TaskEvent, error) {
+	const op = "storage.sqlite.ListTaskEvents"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var oldest sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MIN(id) FROM task_events`).Scan(&oldest); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	if oldest.Valid && afterID < oldest.Int64-1 {
+		return nil, storage.ErrEventsExpired
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, payload FROM task_events WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	events := []models.TaskEvent{}
+	for rows.Next() {
+		event, err := scanTaskEvent(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return events, nil
+}
+
+// scanTaskEvent читает ID и тело события журнала.
+func scanTaskEvent(row scanner) (*models.TaskEvent, error) {
+	var (
+		event   models.TaskEvent
+		payload string
+	)
+
+	if err := row.Scan(&event.ID, &payload); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(payload), &event.WebhookEvent); err != nil {
+		return nil, fmt.Errorf("decode task event %d: %w", event.ID, err)
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,369 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// reminderColumns — список колонок напоминания в порядке, который ожидает
+// scanReminder.
+const reminderColumns = "id, task_id, remind_at, before_seconds, fire_at, sent_at, attempts, last_error, created_at"
+
+// CreateReminder добавляет напоминание к задаче reminder.TaskID и
+// вычисляет момент его срабатывания по сроку задачи.
+func (s *Storage) CreateReminder(ctx context.Context, reminder models.Reminder) (*models.Reminder, error) {
+	const op = "storage.sqlite.CreateReminder"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var dueDate string
+	err := s.db.QueryRowContext(ctx, `SELECT due_date FROM tasks WHERE id = $1`, reminder.TaskID).Scan(&dueDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	due, err := parseTime(dueDate)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	reminder.FireAt = reminder.FireTime(due)
+	reminder.CreatedAt = time.Now()
+	reminder.SentAt = nil
+	reminder.Attempts = 0
+	reminder.LastError = ""
+
+	query := `
+		INSERT INTO reminders (task_id, remind_at, before_seconds, fire_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	err = s.db.QueryRowContext(
+		ctx,
+		query,
+		reminder.TaskID,
+		nullableTime(reminder.RemindAt),
+		beforeSeconds(reminder.Before),
+		formatTime(reminder.FireAt),
+		formatTime(reminder.CreatedAt),
+	).Scan(&reminder.ID)
+	if isForeignKeyViolation(err) {
+		return nil, storage.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &reminder, nil
+}
+
+// ListReminders возвращает напоминания задачи в порядке срабатывания.
+func (s *Storage) ListReminders(ctx context.Context, taskID uint) ([]models.Reminder, error) {
+	const op = "storage.sqlite.ListReminders"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	exists, err := taskExists(ctx, s.db, int64(taskID))
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	if !exists {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = $1 ORDER BY fire_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	reminders, err := scanReminders(rows)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return reminders, nil
+}
+
+// DeleteReminder удаляет напоминание id задачи taskID.
+func (s *Storage) DeleteReminder(ctx context.Context, taskID, id uint) error {
+	const op = "storage.sqlite.DeleteReminder"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM reminders WHERE id = $1 AND task_id = $2`, id, taskID)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if deleted == 0 {
+		return storage.ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// ClaimReminders закрепляет за вызывающим сработавшие и ещё не доставленные
+// напоминания открытых задач на claim.Lease и возвращает их в порядке
+// срабатывания. SQLite допускает одного писателя, поэтому одно UPDATE
+// не даст двум экземплярам выбрать одно напоминание.
+func (s *Storage) ClaimReminders(ctx context.Context, claim models.ReminderClaim) ([]models.Reminder, error) {
+	const op = "storage.sqlite.ClaimReminders"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		UPDATE reminders SET locked_until = $1
+		WHERE id IN (
+			SELECT r.id FROM reminders r JOIN tasks t ON t.id = r.task_id
+			WHERE r.sent_at IS NULL AND r.fire_at <= $2 AND r.attempts < $3
+				AND (r.locked_until IS NULL OR r.locked_until <= $2) AND NOT t.completed
+			ORDER BY r.fire_at, r.id
+			LIMIT $4
+		)
+		RETURNING ` + reminderColumns
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		query,
+		formatTime(claim.Now.Add(claim.Lease)),
+		formatTime(claim.Now),
+		claim.MaxAttempts,
+		claim.Limit,
+	)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	reminders, err := scanReminders(rows)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.Slice(reminders, func(i, j int) bool {
+		if reminders[i].FireAt.Equal(reminders[j].FireAt) {
+			return reminders[i].ID < reminders[j].ID
+		}
+		return reminders[i].FireAt.Before(reminders[j].FireAt)
+	})
+
+	return reminders, nil
+}
+
+// MarkReminderSent отмечает напоминание доставленным в момент at.
+func (s *Storage) MarkReminderSent(ctx context.Context, id int64, at time.Time) error {
+	const op = "storage.sqlite.MarkReminderSent"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `UPDATE reminders SET sent_at = $1, locked_until = NULL WHERE id = $2`
+
+	return s.finishReminder(ctx, op, query, formatTime(at), id)
+}
+
+// MarkReminderFailed записывает неудачную попытку доставки: увеличивает
+// число попыток, сохраняет причину и откладывает следующую попытку до
+// retryAt.
+func (s *Storage) MarkReminderFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	const op = "storage.sqlite.MarkReminderFailed"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `UPDATE reminders SET attempts = attempts + 1, last_error = $1, locked_until = $2 WHERE id = $3`
+
+	return s.finishReminder(ctx, op, query, reason, formatTime(retryAt), id)
+}
+
+// finishReminder выполняет изменение одного напоминания и возвращает
+// ErrReminderNotFound, если его уже удалили.
+func (s *Storage) finishReminder(ctx context.Context, op, query string, args ...any) error {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if updated == 0 {
+		return storage.ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// rescheduleReminders переносит напоминания задачи, заданные относительно
+// срока, на новый срок due. Перенесённое напоминание снова ждёт доставки,
+// даже если по старому сроку уже сработало.
+func rescheduleReminders(ctx context.Context, q querier, taskID int64, due time.Time) error {
+	offsets, err := reminderOffsets(ctx, q, taskID)
+	if err != nil {
+		return err
+	}
+
+	for _, offset := range offsets {
+		fireAt := formatTime(offset.reminder.FireTime(due))
+		if fireAt == offset.fireAt {
+			continue
+		}
+
+		_, err := q.ExecContext(ctx, `
+			UPDATE reminders
+			SET fire_at = $1, sent_at = NULL, attempts = 0, last_error = '', locked_until = NULL
+			WHERE id = $2`,
+			fireAt, offset.reminder.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("reschedule reminder %d: %w", offset.reminder.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// copyReminders добавляет задаче to напоминания задачи from, заданные
+// относительно срока, с моментом срабатывания по сроку due.
+func copyReminders(ctx context.Context, q querier, from, to int64, due time.Time) error {
+	offsets, err := reminderOffsets(ctx, q, from)
+	if err != nil {
+		return err
+	}
+
+	now := formatTime(time.Now())
+	for _, offset := range offsets {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO reminders (task_id, before_seconds, fire_at, created_at)
+			VALUES ($1, $2, $3, $4)`,
+			to, beforeSeconds(offset.reminder.Before), formatTime(offset.reminder.FireTime(due)), now,
+		)
+		if err != nil {
+			return fmt.Errorf("copy reminder %d: %w", offset.reminder.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// reminderOffset — напоминание относительно срока и сохранённый момент его
+// срабатывания.
+type reminderOffset struct {
+	reminder models.Reminder
+	fireAt   string
+}
+
+// reminderOffsets возвращает напоминания задачи, заданные относительно
+// срока.
+func reminderOffsets(ctx context.Context, q querier, taskID int64) ([]reminderOffset, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT id, before_seconds, fire_at FROM reminders WHERE task_id = $1 AND before_seconds IS NOT NULL`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var offsets []reminderOffset
+	for rows.Next() {
+		var offset reminderOffset
+		var seconds int64
+		if err := rows.Scan(&offset.reminder.ID, &seconds, &offset.fireAt); err != nil {
+			return nil, fmt.Errorf("scan reminder: %w", err)
+		}
+		before := models.Duration(time.Duration(seconds) * time.Second)
+		offset.reminder.Before = &before
+		offsets = append(offsets, offset)
+	}
+
+	return offsets, rows.Err()
+}
+
+// beforeSeconds возвращает смещение напоминания в секундах или nil для
+// напоминания на конкретный момент.
+func beforeSeconds(before *models.Duration) any {
+	if before == nil {
+		return nil
+	}
+	return int64(time.Duration(*before) / time.Second)
+}
+
+func scanReminders(rows *sql.Rows) ([]models.Reminder, error) {
+	reminders := []models.Reminder{}
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+func scanReminder(row scanner) (*models.Reminder, error) {
+	var (
+		reminder          models.Reminder
+		remindAt, sentAt  sql.NullString
+		before            sql.NullInt64
+		fireAt, createdAt string
+	)
+
+	err := row.Scan(
+		&reminder.ID,
+		&reminder.TaskID,
+		&remindAt,
+		&before,
+		&fireAt,
+		&sentAt,
+		&reminder.Attempts,
+		&reminder.LastError,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if reminder.RemindAt, err = parseNullTime(remindAt); err != nil {
+		return nil, err
+	}
+	if before.Valid {
+		duration := models.Duration(time.Duration(before.Int64) * time.Second)
+		reminder.Before = &duration
+	}
+	if reminder.FireAt, err = parseTime(fireAt); err != nil {
+		return nil, err
+	}
+	if reminder.SentAt, err = parseNullTime(sentAt); err != nil {
+		return nil, err
+	}
+	if reminder.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &reminder, nil
+}
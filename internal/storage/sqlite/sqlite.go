@@ -0,0 +1,776 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/storage/migrations"
+	"todo/internal/tracing"
+)
+
+// timeFormat хранит время в UTC с фиксированной длиной дробной части,
+// чтобы лексикографический порядок строк совпадал с хронологическим,
+// а функции даты SQLite понимали значение.
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+var tracer = otel.Tracer("todo/internal/storage/sqlite")
+
+type Storage struct {
+	db           *sql.DB
+	queryTimeout time.Duration
+}
+
+// New открывает базу SQLite и применяет миграции. Ненулевой queryTimeout
+// ограничивает время выполнения каждого запроса.
+func New(path string, queryTimeout time.Duration) (*Storage, error) {
+	const op = "storage.sqlite.New"
+
+	db, err := Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	migrator, err := migrations.New(db, migrations.SQLite)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{db: db, queryTimeout: queryTimeout}, nil
+}
+
+// Open открывает файл базы данных без изменения схемы.
+func Open(path string) (*sql.DB, error) {
+	const op = "storage.sqlite.Open"
+
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: open database: %w", op, err)
+	}
+
+	// SQLite допускает только одного писателя, а база ":memory:" существует
+	// в пределах одного соединения.
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: ping database: %w", op, err)
+	}
+
+	return db, nil
+}
+
+// priorityRank переводит приоритет в число для сортировки: чем важнее
+// задача, тем больше значение.
+const priorityRank = `CASE priority
+	WHEN 'urgent' THEN 4
+	WHEN 'high' THEN 3
+	WHEN 'medium' THEN 2
+	WHEN 'low' THEN 1
+	ELSE 0
+END`
+
+// blockedColumn вычисляет флаг blocked: задачу блокирует хотя бы одна
+// незакрытая задача. Колонка completed хранит признак закрытой задачи
+// (done или cancelled), её же используют зависимости и подзадачи.
+const blockedColumn = `EXISTS (
+	SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+	WHERE d.task_id = tasks.id AND NOT b.completed)`
+
+// taskColumns — список колонок задачи в порядке, который ожидает scanTask.
+const taskColumns = "id, title, description, due_date, status, created_at, updated_at, completed_at, cancelled_at, version, priority, " +
+	"project_id, parent_id, rrule, timezone, next_id, " + blockedColumn
+
+func (s *Storage) CreateTask(ctx context.Context, task models.Task) (*models.Task, error) {
+	const op = "storage.sqlite.Create"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	now := time.Now()
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.Priority = task.Priority.OrNone()
+	task.NextID = nil
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if task.ParentID != nil {
+		if err := checkParent(ctx, tx, op, 0, *task.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = insertTask(ctx, tx, &task)
+	if isForeignKeyViolation(err) {
+		return nil, storage.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if err := spawnNext(ctx, tx, &task); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &task, nil
+}
+
+func (s *Storage) GetByID(ctx context.Context, id uint) (*models.Task, error) {
+	const op = "storage.sqlite.GetByID"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1`
+
+	task, err := scanTask(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	tasks := []models.Task{*task}
+	if err := attachTags(ctx, s.db, tasks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &tasks[0], nil
+}
+
+// UpdateTask перезаписывает задачу. Если task.Version не равен нулю,
+// обновление выполняется только при совпадении версии в базе. Задачу с
+// открытыми подзадачами можно выполнить только с children ==
+// SubtasksComplete: тогда подзадачи выполняются в той же транзакции.
+func (s *Storage) UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	const op = "storage.sqlite.UpdateTask"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		UPDATE tasks
+		SET title = $1, description = $2, due_date = $3, status = $4, completed = $5, priority = $6, project_id = $7,
+			parent_id = $8, rrule = $9, timezone = $10, updated_at = $11, version = version + 1,
+			completed_at = CASE WHEN $12 THEN COALESCE(completed_at, $11) END,
+			cancelled_at = CASE WHEN $13 THEN COALESCE(cancelled_at, $11) END
+		WHERE id = $14 AND ($15 = 0 OR version = $15)
+		RETURNING created_at, completed_at, cancelled_at, version, next_id, ` + blockedColumn
+
+	task.UpdatedAt = time.Now()
+	task.Priority = task.Priority.OrNone()
+	task.Status = task.Status.OrTodo()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if task.ParentID != nil {
+		if err := checkParent(ctx, tx, op, task.ID, *task.ParentID); err != nil {
+			return err
+		}
+	}
+
+	completeChildren := task.Status == models.StatusDone && children == models.SubtasksComplete
+	if task.Status == models.StatusDone && !completeChildren {
+		if err := checkCompletion(ctx, tx, op, task.ID); err != nil {
+			return err
+		}
+	}
+
+	var createdAt string
+	var completedAt, cancelledAt sql.NullString
+	var nextID sql.NullInt64
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		task.Title,
+		task.Description,
+		formatTime(task.DueDate),
+		task.Status,
+		task.Status.Closed(),
+		task.Priority,
+		task.ProjectID,
+		task.ParentID,
+		task.RRule,
+		task.Timezone,
+		formatTime(task.UpdatedAt),
+		task.Status == models.StatusDone,
+		task.Status == models.StatusCancelled,
+		task.ID,
+		task.Version,
+	).Scan(&createdAt, &completedAt, &cancelledAt, &task.Version, &nextID, &task.Blocked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingTaskError(ctx, tx, op, task.ID)
+	}
+	if isForeignKeyViolation(err) {
+		return storage.ErrProjectNotFound
+	}
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	if task.CreatedAt, err = parseTime(createdAt); err != nil {
+		return wrap(ctx, op, err)
+	}
+	if task.CompletedAt, err = parseNullTime(completedAt); err != nil {
+		return wrap(ctx, op, err)
+	}
+	if task.CancelledAt, err = parseNullTime(cancelledAt); err != nil {
+		return wrap(ctx, op, err)
+	}
+	task.NextID = nil
+	if nextID.Valid {
+		task.NextID = &nextID.Int64
+	}
+
+	// Подзадачи выполняются после записи задачи: если версия не совпала,
+	// транзакция откатывается раньше.
+	if completeChildren {
+		if _, err := completeSubtasks(ctx, tx, task.ID, task.UpdatedAt); err != nil {
+			return wrap(ctx, op, err)
+		}
+	}
+
+	if task.Tags, err = setTaskTags(ctx, tx, task.ID, task.Tags); err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	if err := rescheduleReminders(ctx, tx, task.ID, task.DueDate); err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	if err := spawnNext(ctx, tx, task); err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	const op = "storage.sqlite.PatchTask"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	if patch.IsEmpty() {
+		task, err := s.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if patch.Version != 0 && patch.Version != task.Version {
+			return nil, storage.ErrVersionMismatch
+		}
+		return task, nil
+	}
+
+	var args []interface{}
+	var sets []string
+	argPosition := 1
+
+	set := func(column string, value interface{}) {
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, argPosition))
+		args = append(args, value)
+		argPosition++
+	}
+
+	if patch.Title != nil {
+		set("title", *patch.Title)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	if patch.DueDate != nil {
+		set("due_date", formatTime(*patch.DueDate))
+	}
+	now := time.Now()
+	if patch.Status != nil {
+		status := patch.Status.OrTodo()
+		set("status", status)
+		set("completed", status.Closed())
+		stamp := func(column string, on bool) {
+			sets = append(sets, fmt.Sprintf("%s = CASE WHEN $%d THEN COALESCE(%s, $%d) END", column, argPosition, column, argPosition+1))
+			args = append(args, on, formatTime(now))
+			argPosition += 2
+		}
+		stamp("completed_at", status == models.StatusDone)
+		stamp("cancelled_at", status == models.StatusCancelled)
+	}
+	if patch.Priority != nil {
+		set("priority", patch.Priority.OrNone())
+	}
+	if patch.ProjectID != nil {
+		set("project_id", nullableID(*patch.ProjectID))
+	}
+	if patch.ParentID != nil {
+		set("parent_id", nullableID(*patch.ParentID))
+	}
+	if patch.RRule != nil {
+		set("rrule", *patch.RRule)
+	}
+	if patch.Timezone != nil {
+		set("timezone", *patch.Timezone)
+	}
+	set("updated_at", formatTime(now))
+
+	query := fmt.Sprintf(`
+		UPDATE tasks
+		SET %s, version = version + 1
+		WHERE id = $%d AND ($%d = 0 OR version = $%d)
+		RETURNING `+taskColumns,
+		strings.Join(sets, ", "), argPosition, argPosition+1, argPosition+1)
+	args = append(args, id, patch.Version)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if patch.ParentID != nil && *patch.ParentID != 0 {
+		if err := checkParent(ctx, tx, op, int64(id), *patch.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	completeChildren := patch.Status != nil && *patch.Status == models.StatusDone && patch.Children == models.SubtasksComplete
+	if patch.Status != nil && *patch.Status == models.StatusDone && !completeChildren {
+		if err := checkCompletion(ctx, tx, op, int64(id)); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := scanTask(tx.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingTaskError(ctx, tx, op, int64(id))
+	}
+	if isForeignKeyViolation(err) {
+		return nil, storage.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if completeChildren {
+		if _, err := completeSubtasks(ctx, tx, task.ID, now); err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+	}
+
+	if patch.Tags != nil {
+		task.Tags, err = setTaskTags(ctx, tx, task.ID, *patch.Tags)
+	} else {
+		tasks := []models.Task{*task}
+		err = attachTags(ctx, tx, tasks)
+		task = &tasks[0]
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if patch.DueDate != nil {
+		if err := rescheduleReminders(ctx, tx, task.ID, task.DueDate); err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+	}
+
+	if err := spawnNext(ctx, tx, task); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return task, nil
+}
+
+// DeleteTask удаляет задачу. Ненулевой version включает проверку версии.
+func (s *Storage) DeleteTask(ctx context.Context, id uint, version int64) error {
+	const op = "storage.sqlite.DeleteTask"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `DELETE FROM tasks WHERE id = $1 AND ($2 = 0 OR version = $2)`
+
+	result, err := s.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return missingTaskError(ctx, s.db, op, int64(id))
+	}
+
+	return nil
+}
+
+func (s *Storage) List(ctx context.Context, filter models.TaskFilter) (*models.TasksList, error) {
+	const op = "storage.sqlite.List"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var args []interface{}
+	var conditions []string
+	argPosition := 1
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE 1=1`
+
+	if filter.Completed != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND completed = $%d", argPosition))
+		args = append(args, *filter.Completed)
+		argPosition++
+	}
+
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			placeholders[i] = fmt.Sprintf("$%d", argPosition)
+			args = append(args, status)
+			argPosition++
+		}
+		conditions = append(conditions, " AND status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	if filter.Date != nil {
+		start, end := localDay(*filter.Date)
+		conditions = append(conditions, fmt.Sprintf(" AND due_date >= $%d AND due_date < $%d", argPosition, argPosition+1))
+		args = append(args, formatTime(start), formatTime(end))
+		argPosition += 2
+	}
+
+	if filter.Priority != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND priority = $%d", argPosition))
+		args = append(args, *filter.Priority)
+		argPosition++
+	}
+
+	if len(filter.Tags) > 0 {
+		condition, tagArgs := tagCondition(filter.Tags, filter.TagMatch, argPosition)
+		conditions = append(conditions, condition)
+		args = append(args, tagArgs...)
+		argPosition += len(tagArgs)
+	}
+
+	if filter.ProjectID != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND project_id = $%d", argPosition))
+		args = append(args, *filter.ProjectID)
+		argPosition++
+	}
+
+	if filter.ParentID != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND parent_id = $%d", argPosition))
+		args = append(args, *filter.ParentID)
+		argPosition++
+	}
+
+	if filter.Blocked != nil {
+		if *filter.Blocked {
+			conditions = append(conditions, " AND "+blockedColumn)
+		} else {
+			conditions = append(conditions, " AND NOT "+blockedColumn)
+		}
+	}
+
+	for _, condition := range conditions {
+		query += condition
+	}
+
+	orderBy := "due_date ASC, id ASC"
+	if filter.Sort == models.SortByPriority {
+		orderBy = priorityRank + " DESC, due_date ASC, id ASC"
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, argPosition, argPosition+1)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if err := attachTags(ctx, s.db, tasks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	var total int64
+	countQuery := "SELECT COUNT(*) FROM tasks WHERE 1=1"
+	for _, condition := range conditions {
+		countQuery += condition
+	}
+
+	err = s.db.QueryRowContext(ctx, countQuery, args[:len(args)-2]...).Scan(&total)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &models.TasksList{
+		Data:  tasks,
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// missingTaskError определяет, почему условное изменение не затронуло ни одной
+// строки: задачи нет или её версия не совпала с ожидаемой.
+func missingTaskError(ctx context.Context, q querier, op string, id int64) error {
+	exists, err := taskExists(ctx, q, id)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	if exists {
+		return storage.ErrVersionMismatch
+	}
+
+	return storage.ErrTaskNotFound
+}
+
+// taskExists сообщает, что задача id есть в базе.
+func taskExists(ctx context.Context, q querier, id int64) (bool, error) {
+	var exists bool
+
+	err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check task exists: %w", err)
+	}
+
+	return exists, nil
+}
+
+// Close закрывает пул соединений с базой данных.
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// Ping проверяет соединение с базой данных.
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.sqlite.Ping"
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MigrationStatus возвращает состояние миграций схемы.
+func (s *Storage) MigrationStatus(ctx context.Context) ([]migrations.Status, error) {
+	const op = "storage.sqlite.MigrationStatus"
+
+	migrator, err := migrations.New(s.db, migrations.SQLite)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return statuses, nil
+}
+
+// Stats возвращает статистику пула соединений.
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// startQuery начинает дочерний спан запроса с именем op и ограничивает
+// контекст настроенным таймаутом. Возвращаемая функция завершает и то, и
+// другое.
+func (s *Storage) startQuery(ctx context.Context, op string) (context.Context, func()) {
+	ctx, span := tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemSqlite, semconv.DBOperationName(op)),
+	)
+	ctx, cancel := s.withTimeout(ctx)
+
+	return ctx, func() {
+		cancel()
+		span.End()
+	}
+}
+
+// withTimeout ограничивает контекст запроса настроенным таймаутом.
+func (s *Storage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+// wrap добавляет к ошибке запроса причину отмены контекста: драйвер сообщает
+// об отменённом запросе собственной ошибкой, а вызывающему нужно отличать
+// таймаут от сбоя базы данных.
+func wrap(ctx context.Context, op string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		err = fmt.Errorf("%w: %w", err, ctxErr)
+	}
+	err = fmt.Errorf("%s: %w", op, err)
+	tracing.RecordError(ctx, err)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row scanner) (*models.Task, error) {
+	var (
+		task                          models.Task
+		description                   sql.NullString
+		dueDate, createdAt, updatedAt string
+		completedAt, cancelledAt      sql.NullString
+		projectID, parentID, nextID   sql.NullInt64
+	)
+
+	err := row.Scan(
+		&task.ID,
+		&task.Title,
+		&description,
+		&dueDate,
+		&task.Status,
+		&createdAt,
+		&updatedAt,
+		&completedAt,
+		&cancelledAt,
+		&task.Version,
+		&task.Priority,
+		&projectID,
+		&parentID,
+		&task.RRule,
+		&task.Timezone,
+		&nextID,
+		&task.Blocked,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Description = description.String
+	if projectID.Valid {
+		task.ProjectID = &projectID.Int64
+	}
+	if parentID.Valid {
+		task.ParentID = &parentID.Int64
+	}
+	if nextID.Valid {
+		task.NextID = &nextID.Int64
+	}
+
+	if task.DueDate, err = parseTime(dueDate); err != nil {
+		return nil, err
+	}
+	if task.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if task.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if task.CompletedAt, err = parseNullTime(completedAt); err != nil {
+		return nil, err
+	}
+	if task.CancelledAt, err = parseNullTime(cancelledAt); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// localDay возвращает полуоткрытый интервал [start, end) календарного дня t
+// в локальной временной зоне: так дату фильтра понимают обработчики и
+// хранилище в памяти.
+func localDay(t time.Time) (start, end time.Time) {
+	y, m, d := t.In(time.Local).Date()
+	start = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 0, 1)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// nullableTime возвращает отметку времени для необязательной колонки: nil
+// сохраняется как NULL.
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time %q: %w", value, err)
+	}
+	return t.Local(), nil
+}
+
+// parseNullTime разбирает необязательную отметку времени: NULL — nil.
+func parseNullTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
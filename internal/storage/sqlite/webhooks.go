@@ -0,0 +1,427 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// deliveryColumns — список колонок доставки в порядке, который ожидает
+// scanDelivery.
+const deliveryColumns = "id, webhook_id, event, payload, status, attempts, next_attempt_at, response_code, last_error, " +
+	"delivered_at, created_at"
+
+// CreateWebhook сохраняет подписку на события задач.
+func (s *Storage) CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	const op = "storage.sqlite.CreateWebhook"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	webhook.CreatedAt = time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO webhooks (url, secret, created_at) VALUES ($1, $2, $3) RETURNING id`,
+		webhook.URL, webhook.Secret, formatTime(webhook.CreatedAt),
+	).Scan(&webhook.ID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	for _, event := range webhook.Events {
+		_, err := tx.ExecContext(ctx, `INSERT INTO webhook_events (webhook_id, event) VALUES ($1, $2)`, webhook.ID, event)
+		if err != nil {
+			return nil, wrap(ctx, op+": subscribe", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &webhook, nil
+}
+
+// ListWebhooks возвращает подписки в порядке создания без ключей подписи.
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	const op = "storage.sqlite.ListWebhooks"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, url, created_at FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	if err := attachEvents(ctx, s.db, webhooks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook возвращает подписку без ключа подписи.
+func (s *Storage) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	const op = "storage.sqlite.GetWebhook"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	webhook, err := scanWebhook(s.db.QueryRowContext(ctx, `SELECT id, url, created_at FROM webhooks WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	webhooks := []models.Webhook{*webhook}
+	if err := attachEvents(ctx, s.db, webhooks); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &webhooks[0], nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом её доставок.
+func (s *Storage) DeleteWebhook(ctx context.Context, id uint) error {
+	const op = "storage.sqlite.DeleteWebhook"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if deleted == 0 {
+		return storage.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает страницу журнала доставок подписки, новые
+// доставки первыми.
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID uint, filter models.DeliveryFilter) (*models.DeliveryList, error) {
+	const op = "storage.sqlite.ListDeliveries"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1)`, webhookID).Scan(&exists)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	if !exists {
+		return nil, storage.ErrWebhookNotFound
+	}
+
+	condition := "webhook_id = $1"
+	args := []any{webhookID}
+	if filter.Status != nil {
+		condition += " AND status = $2"
+		args = append(args, *filter.Status)
+	}
+
+	var total int64
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE `+condition, args...).Scan(&total)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM webhook_deliveries WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d`,
+		deliveryColumns, condition, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	return &models.DeliveryList{
+		Data:  deliveries,
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// EnqueueEvent ставит событие в очередь доставки каждой подписке на него и
+// возвращает число поставленных доставок. Тело запроса сохраняется
+// строкой, чтобы подпись считалась по тем же байтам, что уйдут подписчику.
+func (s *Storage) EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error) {
+	const op = "storage.sqlite.EnqueueEvent"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, next_attempt_at, created_at)
+		SELECT webhook_id, $1, $2, $3, $3 FROM webhook_events WHERE event = $1
+		ORDER BY webhook_id`
+
+	result, err := s.db.ExecContext(ctx, query, event.Type, string(payload), formatTime(time.Now()))
+	if err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+
+	enqueued, err := result.RowsAffected()
+	if err != nil {
+		return 0, wrap(ctx, op+": get rows affected", err)
+	}
+
+	return int(enqueued), nil
+}
+
+// ClaimDeliveries закрепляет за вызывающим доставки, которым пора выполнить
+// попытку, на claim.Lease и возвращает их в порядке очереди. SQLite
+// допускает одного писателя, поэтому одно UPDATE не даст двум экземплярам
+// выбрать одну доставку.
+func (s *Storage) ClaimDeliveries(ctx context.Context, claim models.DeliveryClaim) ([]models.OutgoingDelivery, error) {
+	const op = "storage.sqlite.ClaimDeliveries"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	query := `
+		UPDATE webhook_deliveries SET locked_until = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $2 AND (locked_until IS NULL OR locked_until <= $2)
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+		)
+		RETURNING ` + deliveryColumns + `,
+			(SELECT url FROM webhooks w WHERE w.id = webhook_id),
+			(SELECT secret FROM webhooks w WHERE w.id = webhook_id)`
+
+	rows, err := s.db.QueryContext(ctx, query, formatTime(claim.Now.Add(claim.Lease)), formatTime(claim.Now), claim.Limit)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	defer rows.Close()
+
+	claimed := []models.OutgoingDelivery{}
+	for rows.Next() {
+		var outgoing models.OutgoingDelivery
+		delivery, err := scanDelivery(rows, &outgoing.URL, &outgoing.Secret)
+		if err != nil {
+			return nil, wrap(ctx, op, err)
+		}
+		outgoing.Delivery = *delivery
+		claimed = append(claimed, outgoing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.Slice(claimed, func(i, j int) bool {
+		a, b := claimed[i].Delivery, claimed[j].Delivery
+		if a.NextAttemptAt.Equal(*b.NextAttemptAt) {
+			return a.ID < b.ID
+		}
+		return a.NextAttemptAt.Before(*b.NextAttemptAt)
+	})
+
+	return claimed, nil
+}
+
+// RecordDeliveryAttempt записывает результат попытки доставки id. Если
+// подписку уже удалили вместе с доставкой, возвращает ErrWebhookNotFound.
+func (s *Storage) RecordDeliveryAttempt(ctx context.Context, id int64, attempt models.DeliveryAttempt) error {
+	const op = "storage.sqlite.RecordDeliveryAttempt"
+
+	ctx, done := s.startQuery(ctx, op)
+	defer done()
+
+	status := models.DeliveryPending
+	var deliveredAt *time.Time
+	switch {
+	case attempt.Error == "":
+		status = models.DeliveryDelivered
+		deliveredAt = &attempt.At
+		attempt.RetryAt = nil
+	case attempt.RetryAt == nil:
+		status = models.DeliveryFailed
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = attempts + 1, next_attempt_at = $2, locked_until = NULL, response_code = $3,
+			last_error = $4, delivered_at = $5
+		WHERE id = $6`
+
+	result, err := s.db.ExecContext(ctx, query,
+		status, nullableTime(attempt.RetryAt), attempt.ResponseCode, attempt.Error, nullableTime(deliveredAt), id,
+	)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return wrap(ctx, op+": get rows affected", err)
+	}
+	if updated == 0 {
+		return storage.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// attachEvents заполняет события подписок одним запросом.
+func attachEvents(ctx context.Context, q querier, webhooks []models.Webhook) error {
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(webhooks))
+	placeholders := make([]string, len(webhooks))
+	args := make([]any, len(webhooks))
+	for i := range webhooks {
+		webhooks[i].Events = []models.WebhookEventType{}
+		index[webhooks[i].ID] = i
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = webhooks[i].ID
+	}
+
+	query := `SELECT webhook_id, event FROM webhook_events WHERE webhook_id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("load webhook events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webhookID int64
+		var event models.WebhookEventType
+		if err := rows.Scan(&webhookID, &event); err != nil {
+			return fmt.Errorf("load webhook events: %w", err)
+		}
+		i := index[webhookID]
+		webhooks[i].Events = append(webhooks[i].Events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load webhook events: %w", err)
+	}
+
+	for i := range webhooks {
+		webhooks[i].Events = models.SortEvents(webhooks[i].Events)
+	}
+
+	return nil
+}
+
+func scanWebhook(row scanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var createdAt string
+
+	if err := row.Scan(&webhook.ID, &webhook.URL, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if webhook.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// scanDelivery читает доставку; extra получает колонки, следующие за
+// deliveryColumns.
+func scanDelivery(row scanner, extra ...any) (*models.WebhookDelivery, error) {
+	var (
+		delivery                   models.WebhookDelivery
+		payload, createdAt         string
+		nextAttemptAt, deliveredAt sql.NullString
+	)
+
+	dest := []any{
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.Event,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&delivery.ResponseCode,
+		&delivery.LastError,
+		&deliveredAt,
+		&createdAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = json.RawMessage(payload)
+
+	var err error
+	if delivery.NextAttemptAt, err = parseNullTime(nextAttemptAt); err != nil {
+		return nil, err
+	}
+	if delivery.DeliveredAt, err = parseNullTime(deliveredAt); err != nil {
+		return nil, err
+	}
+	if delivery.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
@@ -3,6 +3,23 @@ package storage
 import "errors"
 
 var (
-	ErrTaskNotFound = errors.New("task not found")
-	ErrURLExists    = errors.New("url exists")
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrURLExists          = errors.New("url exists")
+	ErrVersionMismatch    = errors.New("task version mismatch")
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagExists          = errors.New("tag already exists")
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrProjectNotEmpty    = errors.New("project has tasks")
+	ErrParentNotFound     = errors.New("parent task not found")
+	ErrTaskCycle          = errors.New("task cannot be nested under itself")
+	ErrOpenSubtasks       = errors.New("task has open subtasks")
+	ErrBlockerNotFound    = errors.New("blocker task not found")
+	ErrDependencyCycle    = errors.New("dependency cycle")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrReminderNotFound   = errors.New("reminder not found")
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrEventsExpired      = errors.New("task events expired")
+	ErrFeedTokenNotFound  = errors.New("feed token not found")
+
+	ErrCalendarObjectExists = errors.New("calendar object name already used")
 )
@@ -0,0 +1,135 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"todo/internal/config"
+	"todo/internal/models"
+	"todo/internal/storage/sqlite"
+	"todo/internal/tracing"
+)
+
+// recorder собирает спаны всех тестов пакета. Глобальный провайдер
+// устанавливается один раз: трейсеры, полученные до этого, привязываются
+// только к первому установленному провайдеру.
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	// Setup с выключенным экспортом только устанавливает W3C-пропагатор.
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: config.TracingDisabled})
+	if err != nil {
+		panic(err)
+	}
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	os.Exit(m.Run())
+}
+
+func TestSetup(t *testing.T) {
+	cases := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{name: "disabled", exporter: config.TracingDisabled},
+		{name: "stdout", exporter: config.TracingStdout},
+		{name: "unknown", exporter: "zipkin", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup подменяет глобальный провайдер, поэтому после теста
+			// возвращаем провайдер с recorder.
+			provider := otel.GetTracerProvider()
+			t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+			shutdown, err := tracing.Setup(context.Background(), config.Tracing{
+				Exporter:    tc.exporter,
+				ServiceName: "todo",
+				SampleRatio: 1,
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	var handlerSpan trace.SpanContext
+
+	router := chi.NewRouter()
+	router.Use(tracing.Middleware)
+	router.Route("/tasks/{id}", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			handlerSpan = trace.SpanContextFromContext(r.Context())
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := findSpan(t, "GET /tasks/{id}")
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, traceID, span.SpanContext().TraceID().String())
+	require.Equal(t, spanID, span.Parent().SpanID().String())
+	require.Equal(t, span.SpanContext(), handlerSpan)
+	require.Equal(t, codes.Error, span.Status().Code)
+	require.Contains(t, span.Attributes(), attribute.String("http.route", "/tasks/{id}"))
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+}
+
+func TestStorageSpans(t *testing.T) {
+	s, err := sqlite.New(filepath.Join(t.TempDir(), "todo.db"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err = s.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+	require.NoError(t, err)
+	parent.End()
+
+	span := findSpan(t, "storage.sqlite.Create")
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	require.Contains(t, span.Attributes(), attribute.String("db.system", "sqlite"))
+	require.Contains(t, span.Attributes(), attribute.String("db.operation.name", "storage.sqlite.Create"))
+}
+
+func findSpan(t *testing.T, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	require.Failf(t, "span not found", "no ended span named %q", name)
+	return nil
+}
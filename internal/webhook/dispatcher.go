@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// maxResponseBody ограничивает, сколько ответа подписчика читается перед
+// закрытием соединения.
+const maxResponseBody = 64 << 10
+
+// Store — очередь доставок, из которой диспетчер выбирает доставки и в
+// которой записывает результаты попыток.
+type Store interface {
+	ClaimDeliveries(ctx context.Context, claim models.DeliveryClaim) ([]models.OutgoingDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, id int64, attempt models.DeliveryAttempt) error
+}
+
+// Dispatcher периодически выбирает из очереди доставки, которым пора
+// выполнить попытку, и отправляет их подписчикам. Хранилище закрепляет
+// выбранные доставки за экземпляром, поэтому несколько экземпляров
+// приложения могут работать с одной очередью.
+type Dispatcher struct {
+	log    *slog.Logger
+	store  Store
+	client *http.Client
+	cfg    config.Webhooks
+}
+
+func NewDispatcher(log *slog.Logger, store Store, cfg config.Webhooks) *Dispatcher {
+	return &Dispatcher{
+		log:    log.With(slog.String("component", "webhook.Dispatcher")),
+		store:  store,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+	}
+}
+
+// Run опрашивает очередь сразу и затем каждые PollInterval, пока ctx не
+// отменён.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Poll(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			d.log.Error("failed to poll webhook deliveries", sl.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll выполняет попытку для каждой доставки, которой пора, и возвращает
+// число успешных. Доставки отправляются параллельно, поэтому вся выборка
+// укладывается в Timeout и не теряет закрепление. Неудачная попытка
+// откладывается по экспоненте и не прерывает остальные.
+func (d *Dispatcher) Poll(ctx context.Context, now time.Time) (int, error) {
+	const op = "webhook.Dispatcher.Poll"
+
+	deliveries, err := d.store.ClaimDeliveries(ctx, models.DeliveryClaim{
+		Now:   now,
+		Lease: d.cfg.Lease,
+		Limit: d.cfg.BatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	for _, outgoing := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if d.attempt(ctx, now, outgoing) {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return delivered, nil
+}
+
+// attempt отправляет доставку, записывает результат и сообщает, что
+// подписчик её принял.
+func (d *Dispatcher) attempt(ctx context.Context, now time.Time, outgoing models.OutgoingDelivery) bool {
+	delivery := outgoing.Delivery
+	log := d.log.With(
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int64("webhook_id", delivery.WebhookID),
+		slog.String("event", string(delivery.Event)),
+	)
+
+	code, err := d.send(ctx, outgoing)
+
+	attempt := models.DeliveryAttempt{At: time.Now(), ResponseCode: code}
+	number := delivery.Attempts + 1
+	if err != nil {
+		attempt.Error = err.Error()
+		if number < d.cfg.MaxAttempts {
+			retryAt := now.Add(d.backoff(number))
+			attempt.RetryAt = &retryAt
+			log.Warn("webhook delivery failed, will retry",
+				slog.Int("attempt", number), slog.Time("retry_at", retryAt), sl.Err(err))
+		} else {
+			log.Error("webhook delivery failed, giving up", slog.Int("attempt", number), sl.Err(err))
+		}
+	} else {
+		log.Info("webhook delivered", slog.Int("attempt", number), slog.Int("status", code))
+	}
+
+	err = d.store.RecordDeliveryAttempt(ctx, delivery.ID, attempt)
+	if err != nil && !errors.Is(err, storage.ErrWebhookNotFound) {
+		log.Error("failed to save webhook delivery attempt", sl.Err(err))
+	}
+
+	return attempt.Error == ""
+}
+
+// send отправляет тело доставки POST-запросом и возвращает код ответа.
+// Успехом считается любой ответ 2xx.
+func (d *Dispatcher) send(ctx context.Context, outgoing models.OutgoingDelivery) (int, error) {
+	delivery := outgoing.Delivery
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, outgoing.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("webhook: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-webhooks")
+	req.Header.Set(HeaderEvent, string(delivery.Event))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(outgoing.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff возвращает задержку перед попыткой, следующей за неудачной
+// попыткой number: BackoffBase, удваиваемый с каждой попыткой, но не
+// больше BackoffMax.
+func (d *Dispatcher) backoff(number int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < number; i++ {
+		delay *= 2
+		if d.cfg.BackoffMax > 0 && delay >= d.cfg.BackoffMax {
+			return d.cfg.BackoffMax
+		}
+	}
+	if d.cfg.BackoffMax > 0 && delay > d.cfg.BackoffMax {
+		return d.cfg.BackoffMax
+	}
+	return delay
+}
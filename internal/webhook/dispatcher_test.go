@@ -0,0 +1,169 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/config"
+	"todo/internal/models"
+	"todo/internal/storage/memory"
+	"todo/internal/webhook"
+)
+
+// receiver запоминает полученные запросы и отвечает кодами из codes по
+// очереди; когда они заканчиваются — 204.
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+
+	code := http.StatusNoContent
+	if len(rc.codes) > 0 {
+		code = rc.codes[0]
+		rc.codes = rc.codes[1:]
+	}
+	w.WriteHeader(code)
+}
+
+func newDispatcher(s *memory.Storage, cfg config.Webhooks) *webhook.Dispatcher {
+	return webhook.NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), s, cfg)
+}
+
+func TestDispatcherPoll(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	rc := &receiver{codes: []int{http.StatusBadGateway}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	const secret = "0123456789abcdef0123"
+	hook, err := s.CreateWebhook(ctx, models.Webhook{URL: server.URL, Secret: secret, Events: models.WebhookEventTypes})
+	require.NoError(t, err)
+
+	_, err = s.EnqueueEvent(ctx, models.WebhookEvent{
+		Type:       models.EventTaskCompleted,
+		OccurredAt: time.Now(),
+		Task:       models.Task{ID: 3, Title: "release", Status: models.StatusDone},
+	})
+	require.NoError(t, err)
+
+	cfg := config.Webhooks{BatchSize: 10, Lease: time.Minute, Timeout: 5 * time.Second, MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour}
+	dispatcher := newDispatcher(s, cfg)
+
+	now := time.Now()
+	delivered, err := dispatcher.Poll(ctx, now)
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.Len(t, rc.requests, 1)
+
+	req := rc.requests[0]
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	require.Equal(t, "task.completed", req.Header.Get(webhook.HeaderEvent))
+	require.True(t, webhook.Verify(secret, rc.bodies[0], req.Header.Get(webhook.HeaderSignature)))
+	require.False(t, webhook.Verify("another-secret-value", rc.bodies[0], req.Header.Get(webhook.HeaderSignature)))
+
+	var event models.WebhookEvent
+	require.NoError(t, json.Unmarshal(rc.bodies[0], &event))
+	require.Equal(t, models.EventTaskCompleted, event.Type)
+	require.Equal(t, "release", event.Task.Title)
+
+	log, err := s.ListDeliveries(ctx, uint(hook.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, log.Data, 1)
+	first := log.Data[0]
+	require.Equal(t, strconv.FormatInt(first.ID, 10), req.Header.Get(webhook.HeaderDelivery))
+	require.Equal(t, models.DeliveryPending, first.Status)
+	require.Equal(t, 1, first.Attempts)
+	require.Equal(t, http.StatusBadGateway, first.ResponseCode)
+	require.Equal(t, "webhook: unexpected status 502", first.LastError)
+	require.Equal(t, now.Add(time.Minute), *first.NextAttemptAt)
+
+	// До срока повтора доставка не отправляется.
+	delivered, err = dispatcher.Poll(ctx, now.Add(30*time.Second))
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.Len(t, rc.requests, 1)
+
+	delivered, err = dispatcher.Poll(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Len(t, rc.requests, 2)
+	require.Equal(t, rc.bodies[0], rc.bodies[1])
+
+	log, err = s.ListDeliveries(ctx, uint(hook.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, models.DeliveryDelivered, log.Data[0].Status)
+	require.Equal(t, 2, log.Data[0].Attempts)
+	require.Equal(t, http.StatusNoContent, log.Data[0].ResponseCode)
+	require.Empty(t, log.Data[0].LastError)
+	require.NotNil(t, log.Data[0].DeliveredAt)
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	rc := &receiver{codes: []int{500, 500, 500, 500, 500}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	hook, err := s.CreateWebhook(ctx, models.Webhook{URL: server.URL, Secret: "0123456789abcdef0123", Events: models.WebhookEventTypes})
+	require.NoError(t, err)
+	_, err = s.EnqueueEvent(ctx, models.WebhookEvent{Type: models.EventTaskDeleted, OccurredAt: time.Now(), Task: models.Task{ID: 1}})
+	require.NoError(t, err)
+
+	cfg := config.Webhooks{BatchSize: 10, Lease: time.Minute, Timeout: 5 * time.Second, MaxAttempts: 4, BackoffBase: time.Minute, BackoffMax: 3 * time.Minute}
+	dispatcher := newDispatcher(s, cfg)
+
+	// Задержка удваивается и ограничена BackoffMax.
+	now := time.Now()
+	for _, delay := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		_, err := dispatcher.Poll(ctx, now)
+		require.NoError(t, err)
+
+		log, err := s.ListDeliveries(ctx, uint(hook.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, models.DeliveryPending, log.Data[0].Status)
+		require.Equal(t, now.Add(delay), *log.Data[0].NextAttemptAt)
+
+		now = now.Add(delay)
+	}
+
+	_, err = dispatcher.Poll(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, rc.requests, 4)
+
+	log, err := s.ListDeliveries(ctx, uint(hook.ID), models.DeliveryFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, models.DeliveryFailed, log.Data[0].Status)
+	require.Equal(t, 4, log.Data[0].Attempts)
+	require.Nil(t, log.Data[0].NextAttemptAt)
+
+	// Исчерпанная доставка больше не выбирается.
+	_, err = dispatcher.Poll(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, rc.requests, 4)
+}
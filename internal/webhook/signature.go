@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Заголовки запроса, с которым событие отправляется подписчику.
+const (
+	// HeaderEvent содержит тип события, например task.created.
+	HeaderEvent = "X-Todo-Event"
+	// HeaderDelivery содержит ID доставки. При повторных попытках он не
+	// меняется, по нему подписчик может отбросить дубликаты.
+	HeaderDelivery = "X-Todo-Delivery"
+	// HeaderSignature содержит подпись тела запроса, см. Sign.
+	HeaderSignature = "X-Todo-Signature"
+)
+
+// Sign возвращает подпись тела запроса в формате "sha256=<hex>", где hex —
+// HMAC-SHA256 тела с ключом подписки.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify сообщает, что signature — подпись тела body ключом secret.
+// Сравнение выполняется за постоянное время.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,352 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/lib/logger/sl"
+	"todo/internal/models"
+)
+
+// Publisher ставит событие в очередь доставки подписчикам.
+type Publisher interface {
+	EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error)
+}
+
+// Publishers передаёт событие всем получателям по очереди и возвращает
+// суммарное число поставленных доставок. Ошибка одного получателя не
+// мешает остальным.
+type Publishers []Publisher
+
+func (p Publishers) EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error) {
+	var (
+		enqueued int
+		errs     []error
+	)
+	for _, publisher := range p {
+		n, err := publisher.EnqueueEvent(ctx, event)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		enqueued += n
+	}
+
+	return enqueued, errors.Join(errs...)
+}
+
+// Transactor выполняет fn в одной транзакции хранилища: вызовы хранилища с
+// контекстом fn видят изменения друг друга и фиксируются вместе.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// taskService публикует события жизненного цикла задач поверх хранилища.
+// Методы, которые не изменяют задачи, передаются встроенному TaskService
+// без изменений.
+type taskService struct {
+	handlers.TaskService
+	log       *slog.Logger
+	publisher Publisher
+	tx        Transactor
+}
+
+// WrapTaskService возвращает TaskService, который после каждого успешного
+// создания, изменения и удаления задачи ставит события в очередь
+// доставки.
+//
+// Если tx задан, изменение и его события записываются одной транзакцией:
+// ошибка публикации отменяет изменение, и подписчики не пропускают
+// сохранённые изменения. Без tx событие публикуется после сохранения
+// изменения: если поставить его в очередь не удалось, ошибка записывается
+// в журнал, а изменение остаётся в силе.
+func WrapTaskService(log *slog.Logger, service handlers.TaskService, publisher Publisher, tx Transactor) handlers.TaskService {
+	return &taskService{
+		TaskService: service,
+		log:         log.With(slog.String("component", "webhook.taskService")),
+		publisher:   publisher,
+		tx:          tx,
+	}
+}
+
+func (s *taskService) CreateTask(ctx context.Context, task models.Task) (*models.Task, error) {
+	return inTx(ctx, s, func(ctx context.Context) (*models.Task, error) {
+		return s.createTask(ctx, task)
+	})
+}
+
+func (s *taskService) UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	_, err := inTx(ctx, s, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.updateTask(ctx, task, children)
+	})
+	return err
+}
+
+func (s *taskService) PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	return inTx(ctx, s, func(ctx context.Context) (*models.Task, error) {
+		return s.patchTask(ctx, id, patch)
+	})
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, id uint, version int64) error {
+	_, err := inTx(ctx, s, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.deleteTask(ctx, id, version)
+	})
+	return err
+}
+
+func (s *taskService) DeleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error) {
+	return inTx(ctx, s, func(ctx context.Context) ([]models.Task, error) {
+		return s.deleteProject(ctx, id, policy)
+	})
+}
+
+func (s *taskService) AddBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	return inTx(ctx, s, func(ctx context.Context) (*models.Task, error) {
+		return s.addBlocker(ctx, id, blockerID, version)
+	})
+}
+
+func (s *taskService) RemoveBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	return inTx(ctx, s, func(ctx context.Context) (*models.Task, error) {
+		return s.removeBlocker(ctx, id, blockerID, version)
+	})
+}
+
+// publishErrorsKey — ключ контекста, в котором inTx собирает ошибки
+// публикации.
+type publishErrorsKey struct{}
+
+// inTx выполняет fn в транзакции s.tx, если она задана. Ошибки публикации
+// событий внутри транзакции отменяют её вместе с изменением.
+func inTx[T any](ctx context.Context, s *taskService, fn func(ctx context.Context) (T, error)) (T, error) {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+
+	var result T
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var errs []error
+		ctx = context.WithValue(ctx, publishErrorsKey{}, &errs)
+
+		var err error
+		if result, err = fn(ctx); err != nil {
+			return err
+		}
+
+		return errors.Join(errs...)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, nil
+}
+
+func (s *taskService) createTask(ctx context.Context, task models.Task) (*models.Task, error) {
+	created, err := s.TaskService.CreateTask(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, models.EventTaskCreated, *created)
+	if created.Status == models.StatusDone {
+		s.publish(ctx, models.EventTaskCompleted, *created)
+	}
+	s.publishNext(ctx, nil, created.NextID)
+
+	return created, nil
+}
+
+func (s *taskService) updateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	// PUT передаёт задачу целиком, поэтому, чтобы заметить переход в
+	// выполненные и созданное повторение, нужно знать прежнее состояние.
+	wasCompleted := false
+	var nextID *int64
+	if task.Status.Closed() {
+		if current, err := s.TaskService.GetByID(ctx, uint(task.ID)); err == nil {
+			wasCompleted = current.Status == models.StatusDone
+			nextID = current.NextID
+		}
+	}
+
+	completeChildren := task.Status == models.StatusDone && children == models.SubtasksComplete
+	var subtree []models.Task
+	var subtreeErr error
+	if completeChildren {
+		subtree, subtreeErr = s.TaskService.Subtree(ctx, uint(task.ID))
+	}
+
+	if err := s.TaskService.UpdateTask(ctx, task, children); err != nil {
+		return err
+	}
+
+	if completeChildren {
+		s.publishCompletedSubtasks(ctx, task.ID, subtree, subtreeErr)
+	}
+
+	s.publish(ctx, models.EventTaskUpdated, *task)
+	if task.Status == models.StatusDone && !wasCompleted {
+		s.publish(ctx, models.EventTaskCompleted, *task)
+	}
+	s.publishNext(ctx, nextID, task.NextID)
+
+	return nil
+}
+
+func (s *taskService) patchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	var nextID *int64
+	if patch.Status != nil && patch.Status.Closed() || patch.RRule != nil && *patch.RRule != "" {
+		if current, err := s.TaskService.GetByID(ctx, id); err == nil {
+			nextID = current.NextID
+		}
+	}
+
+	completeChildren := patch.Status != nil && *patch.Status == models.StatusDone && patch.Children == models.SubtasksComplete
+	var subtree []models.Task
+	var subtreeErr error
+	if completeChildren {
+		subtree, subtreeErr = s.TaskService.Subtree(ctx, id)
+	}
+
+	task, err := s.TaskService.PatchTask(ctx, id, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	if completeChildren {
+		s.publishCompletedSubtasks(ctx, task.ID, subtree, subtreeErr)
+	}
+
+	// Патч содержит только изменившиеся поля, так что Status == done
+	// означает переход в выполненные.
+	s.publish(ctx, models.EventTaskUpdated, *task)
+	if patch.Status != nil && *patch.Status == models.StatusDone {
+		s.publish(ctx, models.EventTaskCompleted, *task)
+	}
+	s.publishNext(ctx, nextID, task.NextID)
+
+	return task, nil
+}
+
+func (s *taskService) deleteTask(ctx context.Context, id uint, version int64) error {
+	// Вместе с задачей удаляются её подзадачи; их состояние нужно прочитать
+	// до удаления.
+	subtree, subtreeErr := s.TaskService.Subtree(ctx, id)
+
+	if err := s.TaskService.DeleteTask(ctx, id, version); err != nil {
+		return err
+	}
+	if subtreeErr != nil {
+		s.log.Warn("failed to read deleted tasks", slog.Uint64("id", uint64(id)), sl.Err(subtreeErr))
+	}
+
+	for _, task := range subtree {
+		s.publish(ctx, models.EventTaskDeleted, task)
+	}
+
+	return nil
+}
+
+func (s *taskService) deleteProject(ctx context.Context, id uint, policy models.ProjectDeletePolicy) ([]models.Task, error) {
+	tasks, err := s.TaskService.DeleteProject(ctx, id, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Задачи, перенесённые во входящие, изменены, удалённые вместе с
+	// проектом — удалены.
+	event := models.EventTaskUpdated
+	if policy == models.ProjectDeleteCascade {
+		event = models.EventTaskDeleted
+	}
+	for _, task := range tasks {
+		s.publish(ctx, event, task)
+	}
+
+	return tasks, nil
+}
+
+// publishCompletedSubtasks публикует выполнение подзадач задачи id,
+// которые были открыты в before — дереве, прочитанном до изменения, и
+// создание следующих повторений повторяющихся подзадач.
+func (s *taskService) publishCompletedSubtasks(ctx context.Context, id int64, before []models.Task, beforeErr error) {
+	if beforeErr != nil {
+		s.log.Warn("failed to read subtasks", slog.Int64("id", id), sl.Err(beforeErr))
+		return
+	}
+
+	open := make(map[int64]bool, len(before))
+	for _, task := range before {
+		open[task.ID] = task.ID != id && task.Status != models.StatusDone
+	}
+
+	after, err := s.TaskService.Subtree(ctx, uint(id))
+	if err != nil {
+		s.log.Warn("failed to read completed subtasks", slog.Int64("id", id), sl.Err(err))
+		return
+	}
+	for _, task := range after {
+		if open[task.ID] && task.Status == models.StatusDone {
+			s.publish(ctx, models.EventTaskUpdated, task)
+			s.publish(ctx, models.EventTaskCompleted, task)
+			s.publishNext(ctx, nil, task.NextID)
+		}
+	}
+}
+
+func (s *taskService) addBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	task, err := s.TaskService.AddBlocker(ctx, id, blockerID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, models.EventTaskUpdated, *task)
+
+	return task, nil
+}
+
+func (s *taskService) removeBlocker(ctx context.Context, id, blockerID uint, version int64) (*models.Task, error) {
+	task, err := s.TaskService.RemoveBlocker(ctx, id, blockerID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, models.EventTaskUpdated, *task)
+
+	return task, nil
+}
+
+// publishNext публикует создание следующего повторения, если ссылка
+// next_id появилась или сменилась.
+func (s *taskService) publishNext(ctx context.Context, before, after *int64) {
+	if after == nil || before != nil && *before == *after {
+		return
+	}
+
+	next, err := s.TaskService.GetByID(ctx, uint(*after))
+	if err != nil {
+		s.log.Error("failed to read next occurrence", slog.Int64("id", *after), sl.Err(err))
+		return
+	}
+
+	s.publish(ctx, models.EventTaskCreated, *next)
+}
+
+// publish ставит событие в очередь. Ошибка не возвращается: без
+// транзакции изменение задачи уже сохранено, а в транзакции inTx её
+// вернёт сам inTx.
+func (s *taskService) publish(ctx context.Context, eventType models.WebhookEventType, task models.Task) {
+	event := models.WebhookEvent{Type: eventType, OccurredAt: time.Now(), Task: task}
+
+	if _, err := s.publisher.EnqueueEvent(ctx, event); err != nil {
+		s.log.Error("failed to enqueue webhook event",
+			slog.String("event", string(eventType)), slog.Int64("task_id", task.ID), sl.Err(err))
+		if errs, ok := ctx.Value(publishErrorsKey{}).(*[]error); ok {
+			*errs = append(*errs, err)
+		}
+	}
+}
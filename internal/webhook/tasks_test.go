@@ -0,0 +1,197 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/models"
+	"todo/internal/storage/memory"
+	"todo/internal/webhook"
+	"todo/internal/workflow"
+)
+
+// publisher запоминает опубликованные события в виде "событие:id задачи".
+type publisher struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (p *publisher) EnqueueEvent(_ context.Context, event models.WebhookEvent) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, fmt.Sprintf("%s:%d", event.Type, event.Task.ID))
+	return 1, nil
+}
+
+// take возвращает события, опубликованные с прошлого вызова.
+func (p *publisher) take() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := p.events
+	p.events = nil
+	return events
+}
+
+func TestWrapTaskServicePublishes(t *testing.T) {
+	ctx := context.Background()
+	pub := &publisher{}
+	service := webhook.WrapTaskService(slog.New(slog.NewTextHandler(io.Discard, nil)),
+		workflow.WrapTaskService(memory.New(), models.DefaultWorkflow), pub, nil)
+
+	root, err := service.CreateTask(ctx, models.Task{Title: "root", DueDate: time.Now()})
+	require.NoError(t, err)
+	child, err := service.CreateTask(ctx, models.Task{Title: "child", DueDate: time.Now(), ParentID: &root.ID})
+	require.NoError(t, err)
+	require.Equal(t, []string{"task.created:1", "task.created:2"}, pub.take())
+
+	inProgress := models.StatusInProgress
+	_, err = service.PatchTask(ctx, uint(child.ID), models.TaskPatch{Status: &inProgress})
+	require.NoError(t, err)
+	require.Equal(t, []string{"task.updated:2"}, pub.take())
+
+	task, err := service.GetByID(ctx, uint(child.ID))
+	require.NoError(t, err)
+	task.Status = models.StatusDone
+	require.NoError(t, service.UpdateTask(ctx, task, models.SubtasksBlock))
+	require.Equal(t, []string{"task.updated:2", "task.completed:2"}, pub.take())
+
+	// Повторное сохранение выполненной задачи не публикует completed.
+	require.NoError(t, service.UpdateTask(ctx, task, models.SubtasksBlock))
+	require.Equal(t, []string{"task.updated:2"}, pub.take())
+
+	other, err := service.CreateTask(ctx, models.Task{Title: "other", DueDate: time.Now(), ParentID: &root.ID, RRule: "FREQ=DAILY"})
+	require.NoError(t, err)
+	pub.take()
+
+	done := models.StatusDone
+	_, err = service.PatchTask(ctx, uint(root.ID), models.TaskPatch{Status: &done, Children: models.SubtasksComplete})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		fmt.Sprintf("task.updated:%d", other.ID),
+		fmt.Sprintf("task.completed:%d", other.ID),
+		fmt.Sprintf("task.created:%d", other.ID+1),
+		"task.updated:1",
+		"task.completed:1",
+	}, pub.take())
+
+	require.NoError(t, service.DeleteTask(ctx, uint(root.ID), 0))
+	require.ElementsMatch(t, []string{
+		"task.deleted:1",
+		"task.deleted:2",
+		fmt.Sprintf("task.deleted:%d", other.ID),
+		fmt.Sprintf("task.deleted:%d", other.ID+1),
+	}, pub.take())
+}
+
+// transactor выполняет fn сразу и считает вызовы InTx.
+type transactor struct {
+	calls int
+}
+
+func (t *transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.calls++
+	return fn(ctx)
+}
+
+// failingPublisher не может поставить событие в очередь.
+type failingPublisher struct{}
+
+func (failingPublisher) EnqueueEvent(context.Context, models.WebhookEvent) (int, error) {
+	return 0, errors.New("queue is down")
+}
+
+func TestWrapTaskServiceTransaction(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Без транзакции изменение остаётся в силе, даже если событие не
+	// удалось поставить в очередь.
+	service := webhook.WrapTaskService(log, memory.New(), failingPublisher{}, nil)
+	_, err := service.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+	require.NoError(t, err)
+
+	// В транзакции ошибка публикации возвращается, чтобы хранилище
+	// отменило изменение.
+	tx := &transactor{}
+	service = webhook.WrapTaskService(log, memory.New(), failingPublisher{}, tx)
+	_, err = service.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+	require.ErrorContains(t, err, "queue is down")
+	require.Equal(t, 1, tx.calls)
+
+	pub := &publisher{}
+	service = webhook.WrapTaskService(log, memory.New(), pub, tx)
+	created, err := service.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(ctx, uint(created.ID), 0))
+	require.Equal(t, 3, tx.calls)
+	require.Equal(t, []string{"task.created:1", "task.deleted:1"}, pub.take())
+}
+
+func TestWrapTaskServiceDeleteProject(t *testing.T) {
+	ctx := context.Background()
+	pub := &publisher{}
+	store := memory.New()
+	service := webhook.WrapTaskService(slog.New(slog.NewTextHandler(io.Discard, nil)), store, pub, nil)
+
+	home, err := store.CreateProject(ctx, models.Project{Name: "home"})
+	require.NoError(t, err)
+	work, err := store.CreateProject(ctx, models.Project{Name: "work"})
+	require.NoError(t, err)
+
+	paint, err := service.CreateTask(ctx, models.Task{Title: "paint", DueDate: time.Now(), ProjectID: &home.ID})
+	require.NoError(t, err)
+	report, err := service.CreateTask(ctx, models.Task{Title: "report", DueDate: time.Now(), ProjectID: &work.ID})
+	require.NoError(t, err)
+	draft, err := service.CreateTask(ctx, models.Task{Title: "draft", DueDate: time.Now(), ParentID: &report.ID})
+	require.NoError(t, err)
+	pub.take()
+
+	_, err = service.DeleteProject(ctx, uint(home.ID), models.ProjectDeleteInbox)
+	require.NoError(t, err)
+	require.Equal(t, []string{fmt.Sprintf("task.updated:%d", paint.ID)}, pub.take())
+
+	_, err = service.DeleteProject(ctx, uint(work.ID), models.ProjectDeleteCascade)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		fmt.Sprintf("task.deleted:%d", report.ID),
+		fmt.Sprintf("task.deleted:%d", draft.ID),
+	}, pub.take())
+
+	_, err = service.DeleteProject(ctx, uint(work.ID), models.ProjectDeleteCascade)
+	require.Error(t, err)
+	require.Empty(t, pub.take())
+}
+
+func TestWrapTaskServiceRecurring(t *testing.T) {
+	ctx := context.Background()
+	pub := &publisher{}
+	service := webhook.WrapTaskService(slog.New(slog.NewTextHandler(io.Discard, nil)), memory.New(), pub, nil)
+
+	task, err := service.CreateTask(ctx, models.Task{
+		Title:   "standup",
+		DueDate: time.Date(2025, 4, 17, 9, 0, 0, 0, time.UTC),
+		RRule:   "FREQ=DAILY",
+	})
+	require.NoError(t, err)
+	pub.take()
+
+	done := models.StatusDone
+	completed, err := service.PatchTask(ctx, uint(task.ID), models.TaskPatch{Status: &done})
+	require.NoError(t, err)
+	require.NotNil(t, completed.NextID)
+	require.Equal(t, []string{
+		fmt.Sprintf("task.updated:%d", task.ID),
+		fmt.Sprintf("task.completed:%d", task.ID),
+		fmt.Sprintf("task.created:%d", *completed.NextID),
+	}, pub.take())
+}
@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"todo/internal/http-server/handlers"
+	"todo/internal/models"
+	"todo/internal/storage"
+)
+
+// maxAttempts ограничивает число попыток изменения без If-Match, которому
+// мешают параллельные изменения той же задачи.
+const maxAttempts = 3
+
+// taskService проверяет смену статуса задачи по процессу. Методы, которые
+// не меняют статус, передаются встроенному TaskService без изменений.
+type taskService struct {
+	handlers.TaskService
+	workflow models.Workflow
+}
+
+// WrapTaskService возвращает TaskService, который отклоняет недопустимые
+// в workflow переходы статусов с models.ErrInvalidTransition.
+func WrapTaskService(service handlers.TaskService, workflow models.Workflow) handlers.TaskService {
+	return &taskService{
+		TaskService: service,
+		workflow:    workflow,
+	}
+}
+
+// UpdateTask проверяет переход из текущего статуса задачи, а с
+// SubtasksComplete — и переход в done каждой открытой подзадачи. Запись
+// выполняется только для прочитанной версии, чтобы статус не изменился
+// между проверкой и записью; если клиент не передал версию, при
+// параллельном изменении проверка повторяется.
+func (s *taskService) UpdateTask(ctx context.Context, task *models.Task, children models.SubtaskPolicy) error {
+	version := task.Version
+
+	for attempt := 1; ; attempt++ {
+		current, err := s.current(ctx, task.ID, version)
+		if err != nil {
+			return err
+		}
+
+		if err := s.workflow.Check(current.Status, task.Status); err != nil {
+			return err
+		}
+		if task.Status == models.StatusDone && children == models.SubtasksComplete {
+			if err := s.checkSubtasks(ctx, task.ID); err != nil {
+				return err
+			}
+		}
+
+		task.Version = current.Version
+		err = s.TaskService.UpdateTask(ctx, task, children)
+		if version != 0 || attempt == maxAttempts || !errors.Is(err, storage.ErrVersionMismatch) {
+			return err
+		}
+	}
+}
+
+// PatchTask проверяет переход так же, как UpdateTask, если патч меняет
+// статус.
+func (s *taskService) PatchTask(ctx context.Context, id uint, patch models.TaskPatch) (*models.Task, error) {
+	if patch.Status == nil {
+		return s.TaskService.PatchTask(ctx, id, patch)
+	}
+
+	version := patch.Version
+
+	for attempt := 1; ; attempt++ {
+		current, err := s.current(ctx, int64(id), version)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.workflow.Check(current.Status, *patch.Status); err != nil {
+			return nil, err
+		}
+		if *patch.Status == models.StatusDone && patch.Children == models.SubtasksComplete {
+			if err := s.checkSubtasks(ctx, int64(id)); err != nil {
+				return nil, err
+			}
+		}
+
+		patch.Version = current.Version
+		task, err := s.TaskService.PatchTask(ctx, id, patch)
+		if version != 0 || attempt == maxAttempts || !errors.Is(err, storage.ErrVersionMismatch) {
+			return task, err
+		}
+	}
+}
+
+// checkSubtasks проверяет, что каждую открытую подзадачу задачи id можно
+// перевести в done.
+func (s *taskService) checkSubtasks(ctx context.Context, id int64) error {
+	tasks, err := s.TaskService.Subtree(ctx, uint(id))
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if task.ID == id || task.Status.Closed() {
+			continue
+		}
+		if err := s.workflow.Check(task.Status, models.StatusDone); err != nil {
+			return fmt.Errorf("subtask %d: %w", task.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// current читает задачу id и сверяет её версию с ненулевым version.
+func (s *taskService) current(ctx context.Context, id int64, version int64) (*models.Task, error) {
+	task, err := s.TaskService.GetByID(ctx, uint(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != task.Version {
+		return nil, storage.ErrVersionMismatch
+	}
+
+	return task, nil
+}
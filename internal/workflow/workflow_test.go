@@ -0,0 +1,109 @@
+package workflow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"todo/internal/models"
+	"todo/internal/storage"
+	"todo/internal/storage/memory"
+	"todo/internal/workflow"
+)
+
+func TestWrapTaskServiceChecksTransitions(t *testing.T) {
+	cases := []struct {
+		name      string
+		from      models.TaskStatus
+		to        models.TaskStatus
+		expectErr error
+	}{
+		{name: "Start", from: models.StatusTodo, to: models.StatusInProgress},
+		{name: "Same status", from: models.StatusBlocked, to: models.StatusBlocked},
+		{name: "Reopen", from: models.StatusDone, to: models.StatusTodo},
+		{name: "Blocked to done", from: models.StatusBlocked, to: models.StatusDone, expectErr: models.ErrInvalidTransition},
+		{name: "Cancelled to done", from: models.StatusCancelled, to: models.StatusDone, expectErr: models.ErrInvalidTransition},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			service := workflow.WrapTaskService(memory.New(), models.DefaultWorkflow)
+
+			created, err := service.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now(), Status: tc.from})
+			require.NoError(t, err)
+
+			task := *created
+			task.Status = tc.to
+			err = service.UpdateTask(ctx, &task, models.SubtasksBlock)
+			require.ErrorIs(t, err, tc.expectErr)
+
+			patched, err := service.PatchTask(ctx, uint(created.ID), models.TaskPatch{Status: &tc.to})
+			require.ErrorIs(t, err, tc.expectErr)
+			if tc.expectErr == nil {
+				require.Equal(t, tc.to, patched.Status)
+			}
+		})
+	}
+}
+
+func TestWrapTaskServiceVersion(t *testing.T) {
+	ctx := context.Background()
+	service := workflow.WrapTaskService(memory.New(), models.DefaultWorkflow)
+
+	created, err := service.CreateTask(ctx, models.Task{Title: "task", DueDate: time.Now()})
+	require.NoError(t, err)
+
+	inProgress := models.StatusInProgress
+	_, err = service.PatchTask(ctx, uint(created.ID), models.TaskPatch{Status: &inProgress, Version: created.Version + 1})
+	require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+	task, err := service.PatchTask(ctx, uint(created.ID), models.TaskPatch{Status: &inProgress})
+	require.NoError(t, err)
+	require.Equal(t, created.Version+1, task.Version)
+
+	stale := *created
+	stale.Status = models.StatusDone
+	require.ErrorIs(t, service.UpdateTask(ctx, &stale, models.SubtasksBlock), storage.ErrVersionMismatch)
+}
+
+func TestWrapTaskServiceCompleteSubtasks(t *testing.T) {
+	ctx := context.Background()
+	service := workflow.WrapTaskService(memory.New(), models.DefaultWorkflow)
+
+	root, err := service.CreateTask(ctx, models.Task{Title: "root", DueDate: time.Now()})
+	require.NoError(t, err)
+	_, err = service.CreateTask(ctx, models.Task{Title: "open", DueDate: time.Now(), ParentID: &root.ID})
+	require.NoError(t, err)
+	blocked, err := service.CreateTask(ctx, models.Task{
+		Title:    "blocked",
+		DueDate:  time.Now(),
+		ParentID: &root.ID,
+		Status:   models.StatusBlocked,
+	})
+	require.NoError(t, err)
+
+	done := models.StatusDone
+	_, err = service.PatchTask(ctx, uint(root.ID), models.TaskPatch{Status: &done, Children: models.SubtasksComplete})
+	require.ErrorIs(t, err, models.ErrInvalidTransition)
+	root.Status = models.StatusDone
+	require.ErrorIs(t, service.UpdateTask(ctx, root, models.SubtasksComplete), models.ErrInvalidTransition)
+
+	cancelled := models.StatusCancelled
+	_, err = service.PatchTask(ctx, uint(blocked.ID), models.TaskPatch{Status: &cancelled})
+	require.NoError(t, err)
+
+	task, err := service.PatchTask(ctx, uint(root.ID), models.TaskPatch{Status: &done, Children: models.SubtasksComplete})
+	require.NoError(t, err)
+	require.Equal(t, models.StatusDone, task.Status)
+
+	progress, err := service.Progress(ctx, uint(root.ID))
+	require.NoError(t, err)
+	require.Equal(t, 2, progress.Completed)
+}